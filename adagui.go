@@ -3,17 +3,10 @@
 package adagui
 
 import (
-    "log"
 	"math"
 	"golang.org/x/image/math/fixed"
 )
 
-func check(err error) {
-    if err != nil {
-        log.Fatal(err)
-    }
-}
-
 func flt2fix(x float64) fixed.Int26_6 {
 	return fixed.Int26_6(math.Round(x * 64))
 }
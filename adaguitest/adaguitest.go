@@ -0,0 +1,287 @@
+// Das Package adaguitest enthaelt alles, um GUI-Elemente von AdaGui ohne
+// angeschlossene Hardware automatisiert zu testen. Ein Test baut sich mit
+// New ein Fenster auf einem Screen mit MemBackend, speist mit Tap, Drag,
+// LongPress, etc. Touch-Ereignisse ein und vergleicht den Fensterinhalt mit
+// AssertGolden gegen eine Referenzdatei im Verzeichnis 'testdata'.
+//
+// Die Ereignisse durchlaufen dabei die gleiche Verarbeitung wie auf der
+// Hardware, d.h. Tap, DoubleTap und LongPress werden vom Screen erzeugt.
+// Tastatur-Ereignisse werden mit Key ueber eine key.FakeSource eingespeist.
+//
+// Die Listener der Bind-Objekte werden in den Tests synchron aufgerufen
+// (binding.DispatchSync). Die betroffenen Widgets werden dabei im UI-Thread
+// aktualisiert, ihr Zustand wird daher mit Do oder nach WaitIdle geprueft.
+//
+// Mit dem Flag '-update' werden die Referenzdateien neu geschrieben:
+//
+//	go test -run TestCheckbox -update
+package adaguitest
+
+import (
+	"context"
+	"flag"
+	"image"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/binding"
+	"github.com/stefan-muehlebach/adagui/key"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+var (
+	// Ist dieses Flag gesetzt, dann werden die Referenzbilder mit dem
+	// aktuellen Fensterinhalt ueberschrieben.
+	Update = flag.Bool("update", false, "update the golden files")
+
+	// Groesse des Bildschirms, auf welchem die Tests laufen.
+	Width  = adagui.DefaultMemWidth
+	Height = adagui.DefaultMemHeight
+
+	// Bei LongPress wird der Finger um diese Zeit laenger als die
+	// Schwelle fuer einen LongPress aufgelegt, damit das Ereignis sicher
+	// erzeugt wird.
+	SettleTime = 100 * time.Millisecond
+
+	// Maximale Wartezeit in WaitIdle.
+	IdleTimeout = 2 * time.Second
+
+	screen      *adagui.Screen
+	backend     *adagui.MemBackend
+	keys        *key.FakeSource
+	screenDone  chan bool
+	screenMutex sync.Mutex
+
+	// Zeitpunkt (in ns) des letzten Loslassens. Da alle Tests den gleichen
+	// Screen verwenden, koennte ein Tap sonst mit dem letzten Tap des
+	// vorangehenden Tests zu einem DoubleTap verbunden werden.
+	lastRelease atomic.Int64
+)
+
+// Liefert den Screen (und das zugehoerige Backend), auf welchem alle Tests
+// laufen. Da es in einer Applikation nur einen Screen geben darf, wird
+// dieser beim ersten Aufruf erzeugt und anschliessend wiederverwendet. Wurde
+// er mit Shutdown beendet, wird beim naechsten Aufruf ein neuer erzeugt.
+func Screen() (*adagui.Screen, *adagui.MemBackend) {
+	screenMutex.Lock()
+	defer screenMutex.Unlock()
+	if screen == nil {
+		binding.SetDefaultDispatchMode(binding.DispatchSync)
+		backend = adagui.NewMemBackend(Width, Height)
+		var err error
+		screen, err = adagui.NewScreenWithBackend(backend)
+		if err != nil {
+			panic(err)
+		}
+		keys = key.NewFakeSource()
+		screen.SetKeySource(keys)
+		screenDone = make(chan bool)
+		go func(s *adagui.Screen, done chan bool) {
+			s.Run(context.Background())
+			close(done)
+		}(screen, screenDone)
+	}
+	return screen, backend
+}
+
+// Beendet den Test-Screen (sofern er laeuft) und wartet, bis alle
+// Ressourcen freigegeben sind. Damit koennen Tests einen eigenen Screen
+// erzeugen.
+func Shutdown() {
+	screenMutex.Lock()
+	defer screenMutex.Unlock()
+	if screen == nil {
+		return
+	}
+	screen.Quit()
+	<-screenDone
+	screen, backend, keys = nil, nil, nil
+}
+
+// Harness verbindet einen Test mit einem Fenster, welches auf dem
+// Test-Screen angezeigt wird.
+type Harness struct {
+	T       testing.TB
+	Screen  *adagui.Screen
+	Backend *adagui.MemBackend
+	Window  *adagui.Window
+	Keys    *key.FakeSource
+}
+
+// Erzeugt ein neues Fenster mit root als Wurzel-Element, macht es zum
+// aktiven Fenster und wartet, bis es dargestellt ist. Das Fenster wird
+// am Ende des Tests automatisch geschlossen.
+func New(t testing.TB, root adagui.Node) *Harness {
+	t.Helper()
+	h := &Harness{T: t}
+	h.Screen, h.Backend = Screen()
+	h.Keys = keys
+	h.Window = h.Screen.NewWindow()
+	h.Window.SetRoot(root)
+	h.Screen.SetWindow(h.Window)
+	t.Cleanup(h.Close)
+	h.WaitIdle()
+	return h
+}
+
+// Schliesst das Fenster des Harness.
+func (h *Harness) Close() {
+	h.Window.Close()
+}
+
+// Wartet, bis alle Ereignisse verarbeitet, alle ausstehenden Layouts
+// durchgefuehrt und alle markierten Nodes neu gezeichnet wurden (siehe
+// Screen.WaitPainted). Geschieht dies nicht innerhalb von IdleTimeout,
+// wird der Test abgebrochen.
+func (h *Harness) WaitIdle() {
+	h.T.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), IdleTimeout)
+	defer cancel()
+	if err := h.Screen.WaitPainted(ctx); err != nil {
+		h.T.Fatalf("window did not repaint within %v", IdleTimeout)
+	}
+}
+
+// Mit Do wird fn im UI-Thread ausgefuehrt. Damit koennen Tests Widgets,
+// welche bereits angezeigt werden, gefahrlos veraendern oder abfragen. Wie
+// Screen.PostSync darf Do nicht im UI-Thread aufgerufen werden.
+func (h *Harness) Do(fn func()) {
+	h.Screen.PostSync(fn)
+}
+
+// Druecken, Ziehen und Loslassen an den Positionen pt. Diese Methoden
+// warten nicht auf die Verarbeitung der Ereignisse (siehe WaitIdle).
+func (h *Harness) Press(pt geom.Point) {
+	h.Backend.Press(pt)
+}
+
+func (h *Harness) Drag(pt geom.Point) {
+	h.Backend.Drag(pt)
+}
+
+func (h *Harness) Release(pt geom.Point) {
+	h.Backend.Release(pt)
+	lastRelease.Store(time.Now().UnixNano())
+}
+
+// Simuliert einen einfachen Tap an der Position pt. Liegt das letzte
+// Loslassen weniger als DoubleTapDuration zurueck, dann wird vorher
+// entsprechend gewartet, damit kein DoubleTap erkannt wird.
+func (h *Harness) Tap(pt geom.Point) {
+	h.T.Helper()
+	h.waitTapGap()
+	h.Press(pt)
+	h.Release(pt)
+	h.WaitIdle()
+}
+
+// Simuliert einen DoubleTap an der Position pt.
+func (h *Harness) DoubleTap(pt geom.Point) {
+	h.T.Helper()
+	h.waitTapGap()
+	h.Press(pt)
+	h.Release(pt)
+	h.Press(pt)
+	h.Release(pt)
+	h.WaitIdle()
+}
+
+func (h *Harness) waitTapGap() {
+	gap := h.Screen.GestureConfig().DoubleTapDuration
+	if d := time.Since(time.Unix(0, lastRelease.Load())); d <= gap {
+		time.Sleep(gap - d + time.Millisecond)
+	}
+}
+
+// Simuliert einen LongPress an der Position pt, der Finger wird danach
+// wieder angehoben.
+func (h *Harness) LongPress(pt geom.Point) {
+	h.T.Helper()
+	h.Press(pt)
+	time.Sleep(h.Screen.GestureConfig().LongPressThreshold + SettleTime)
+	h.Release(pt)
+	h.WaitIdle()
+}
+
+// Drueckt an der ersten Position in pts, zieht den Finger ueber alle
+// weiteren Positionen und laesst an der letzten Position wieder los.
+func (h *Harness) DragPath(pts ...geom.Point) {
+	h.T.Helper()
+	if len(pts) == 0 {
+		return
+	}
+	h.Press(pts[0])
+	for _, pt := range pts[1:] {
+		h.Drag(pt)
+	}
+	h.Release(pts[len(pts)-1])
+	h.WaitIdle()
+}
+
+// Zieht den Finger in steps gleich grossen Schritten von p0 nach p1.
+func (h *Harness) DragLine(p0, p1 geom.Point, steps int) {
+	h.T.Helper()
+	if steps < 1 {
+		steps = 1
+	}
+	pts := make([]geom.Point, steps+1)
+	for i := range pts {
+		pts[i] = p0.Interpolate(p1, float64(i)/float64(steps))
+	}
+	h.DragPath(pts...)
+}
+
+// Simuliert das Druecken und Loslassen der Taste code mit den Modifiern
+// mod und wartet, bis das Ereignis verarbeitet ist.
+func (h *Harness) Key(code key.Code, mod key.Modifier) {
+	h.T.Helper()
+	h.Keys.Type(code, mod)
+	h.WaitIdle()
+}
+
+// Zieht den Finger in steps gleich grossen Schritten innerhalb der Zeit d
+// von p0 nach p1. Die Zeitstempel der Ereignisse werden dabei kuenstlich
+// gesetzt, damit Swipe und Fling unabhaengig von der Auslastung des
+// Testrechners erkannt werden.
+func (h *Harness) Swipe(p0, p1 geom.Point, d time.Duration, steps int) {
+	h.T.Helper()
+	if steps < 1 {
+		steps = 1
+	}
+	t0 := time.Now()
+	h.Backend.SendEvent(adagui.PenEvent{Type: adagui.PenPress, Pos: p0,
+		Time: t0})
+	for i := 1; i <= steps; i++ {
+		f := float64(i) / float64(steps)
+		h.Backend.SendEvent(adagui.PenEvent{Type: adagui.PenDrag,
+			Pos: p0.Interpolate(p1, f), Time: t0.Add(time.Duration(f * float64(d)))})
+	}
+	h.Backend.SendEvent(adagui.PenEvent{Type: adagui.PenRelease, Pos: p1,
+		Time: t0.Add(d)})
+	h.WaitIdle()
+}
+
+// Liefert die Mitte des Nodes n in Bildschirmkoordinaten. Praktisch, um
+// gezielt auf ein Widget zu tippen.
+func (h *Harness) Center(n adagui.Node) geom.Point {
+	pt := n.Rect().Center()
+	if parent := n.Wrappee().Parent; parent != nil {
+		return parent.Local2Screen(pt)
+	}
+	return pt
+}
+
+// Liefert eine Kopie des aktuellen Fensterinhaltes.
+func (h *Harness) Image() *image.RGBA {
+	return h.Window.Image()
+}
+
+// Vergleicht den aktuellen Fensterinhalt mit dem Referenzbild
+// 'testdata/<name>.png' unter Verwendung von DefaultTolerance.
+func (h *Harness) AssertGolden(name string) {
+	h.T.Helper()
+	AssertGolden(h.T, name, h.Image(), DefaultTolerance)
+}
@@ -0,0 +1,110 @@
+package adaguitest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stefan-muehlebach/gg"
+)
+
+var (
+	// In diesem Verzeichnis werden die Referenzbilder gesucht.
+	GoldenDir = "testdata"
+	// Schlaegt ein Vergleich fehl, dann werden das aktuelle Bild und ein
+	// Differenzbild in diesem Verzeichnis abgelegt.
+	FailedDir = filepath.Join("testdata", "failed")
+
+	DefaultTolerance = Tolerance{MaxChannelDiff: 8, MaxDiffPixels: 0}
+
+	diffColor = color.RGBA{0xff, 0x00, 0xff, 0xff}
+)
+
+// Mit Tolerance wird festgelegt, wie stark zwei Bilder voneinander
+// abweichen duerfen, damit sie noch als gleich gelten. MaxChannelDiff ist
+// die maximale Abweichung pro Farbkanal, ab welcher ein Pixel als
+// verschieden gezaehlt wird und MaxDiffPixels die Anzahl verschiedener
+// Pixel, welche toleriert werden.
+type Tolerance struct {
+	MaxChannelDiff uint8
+	MaxDiffPixels  int
+}
+
+// Vergleicht die Bilder got und want. Retourniert wird die Anzahl
+// verschiedener Pixel sowie ein Differenzbild, in welchem die abweichenden
+// Pixel hervorgehoben und alle anderen abgedunkelt dargestellt sind. Haben
+// die Bilder nicht die gleiche Groesse, dann wird ein Fehler retourniert.
+func Compare(got, want image.Image, tol Tolerance) (int, *image.RGBA, error) {
+	if got.Bounds().Size() != want.Bounds().Size() {
+		return 0, nil, fmt.Errorf("image size differs: got %v, want %v",
+			got.Bounds().Size(), want.Bounds().Size())
+	}
+	numDiff := 0
+	rect := image.Rectangle{Max: got.Bounds().Size()}
+	diff := image.NewRGBA(rect)
+	gOff, wOff := got.Bounds().Min, want.Bounds().Min
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			c1 := color.RGBAModel.Convert(got.At(x+gOff.X, y+gOff.Y)).(color.RGBA)
+			c2 := color.RGBAModel.Convert(want.At(x+wOff.X, y+wOff.Y)).(color.RGBA)
+			if channelDiff(c1.R, c2.R) > tol.MaxChannelDiff ||
+				channelDiff(c1.G, c2.G) > tol.MaxChannelDiff ||
+				channelDiff(c1.B, c2.B) > tol.MaxChannelDiff ||
+				channelDiff(c1.A, c2.A) > tol.MaxChannelDiff {
+				numDiff++
+				diff.SetRGBA(x, y, diffColor)
+			} else {
+				diff.SetRGBA(x, y, color.RGBA{c2.R / 4, c2.G / 4, c2.B / 4, 0xff})
+			}
+		}
+	}
+	return numDiff, diff, nil
+}
+
+// Vergleicht das Bild img mit dem Referenzbild 'testdata/<name>.png'. Ist
+// das Flag '-update' gesetzt, dann wird das Referenzbild durch img ersetzt.
+// Bei einem Fehlschlag werden img und das Differenzbild im Verzeichnis
+// FailedDir abgelegt.
+func AssertGolden(t testing.TB, name string, img image.Image, tol Tolerance) {
+	t.Helper()
+	goldenFile := filepath.Join(GoldenDir, name+".png")
+	if *Update {
+		if err := os.MkdirAll(GoldenDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := gg.SavePNG(goldenFile, img); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := gg.LoadPNG(goldenFile)
+	if err != nil {
+		t.Fatalf("couldn't load golden file (use -update to create it): %v", err)
+	}
+	numDiff, diff, err := Compare(img, want, tol)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if numDiff <= tol.MaxDiffPixels {
+		return
+	}
+	if err := os.MkdirAll(FailedDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	gotFile := filepath.Join(FailedDir, name+".png")
+	diffFile := filepath.Join(FailedDir, name+"_diff.png")
+	gg.SavePNG(gotFile, img)
+	gg.SavePNG(diffFile, diff)
+	t.Errorf("%s: %d pixels differ (tolerance: %d), see %s and %s", name,
+		numDiff, tol.MaxDiffPixels, gotFile, diffFile)
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
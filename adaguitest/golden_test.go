@@ -0,0 +1,34 @@
+package adaguitest
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	img1 := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	img2 := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	img2.SetRGBA(1, 1, color.RGBA{4, 4, 4, 0})
+	img2.SetRGBA(2, 2, color.RGBA{200, 0, 0, 255})
+
+	numDiff, diff, err := Compare(img1, img2, Tolerance{MaxChannelDiff: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numDiff != 1 {
+		t.Errorf("got %d different pixels, want 1", numDiff)
+	}
+	if diff.RGBAAt(2, 2) != diffColor {
+		t.Errorf("different pixel not marked in diff image")
+	}
+	if diff.RGBAAt(1, 1) == diffColor {
+		t.Errorf("pixel within tolerance marked in diff image")
+	}
+
+	_, _, err = Compare(img1, image.NewRGBA(image.Rect(0, 0, 5, 5)),
+		DefaultTolerance)
+	if err == nil {
+		t.Errorf("images of different size compared without error")
+	}
+}
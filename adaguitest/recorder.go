@@ -0,0 +1,69 @@
+package adaguitest
+
+import (
+	"sync"
+
+	"github.com/stefan-muehlebach/adagui/touch"
+)
+
+// Ein Recorder zeichnet Touch-Ereignisse auf, welche an ein Widget
+// gesendet werden. Die Methode Record kann direkt als Handler registriert
+// werden:
+//
+//	rec := adaguitest.NewRecorder()
+//	btn.SetTouchFunc(rec.Record, touch.TypeTap, touch.TypeDoubleTap)
+type Recorder struct {
+	mutex  sync.Mutex
+	events []touch.Event
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Zeichnet das Ereignis evt auf.
+func (r *Recorder) Record(evt touch.Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, evt)
+}
+
+// Liefert eine Kopie aller bisher aufgezeichneten Ereignisse.
+func (r *Recorder) Events() []touch.Event {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	events := make([]touch.Event, len(r.events))
+	copy(events, r.events)
+	return events
+}
+
+// Liefert die Typen aller bisher aufgezeichneten Ereignisse.
+func (r *Recorder) Types() []touch.Type {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	types := make([]touch.Type, len(r.events))
+	for i, evt := range r.events {
+		types[i] = evt.Type
+	}
+	return types
+}
+
+// Zaehlt die aufgezeichneten Ereignisse vom Typ typ.
+func (r *Recorder) Count(typ touch.Type) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	n := 0
+	for _, evt := range r.events {
+		if evt.Type == typ {
+			n++
+		}
+	}
+	return n
+}
+
+// Loescht alle aufgezeichneten Ereignisse.
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = r.events[:0]
+}
@@ -23,12 +23,28 @@ var (
     AnimationLinear    = animationLinear
 )
 
+// Eine Animation wird vom Screen mit jedem Bildaufbau weitergeschaltet
+// (siehe Screen.StartAnimation). Tick erhaelt dabei einen Wert zwischen 0.0
+// und 1.0, welcher bereits durch Curve (Default: AnimationLinear) veraendert
+// wurde. Mit AutoReverse laeuft die Animation nach dem Erreichen des Endes
+// wieder zurueck an den Anfang. RepeatCount gibt an, wie oft die Animation
+// nach dem ersten Durchlauf wiederholt wird (AnimationRepeatForever fuer
+// eine endlose Animation). Ist Node gesetzt, wird dieser nach jedem Tick
+// zum Neuzeichnen markiert. OnComplete wird aufgerufen, sobald die
+// Animation regulaer beendet ist (nicht jedoch bei einem Aufruf von Stop).
 type Animation struct {
     AutoReverse bool
     Curve       AnimationCurve
     Duration    time.Duration
     RepeatCount int
     Tick        func(float64)
+    Node        Node
+    OnComplete  func()
+
+    start   time.Time
+    repeats int
+    reverse bool
+    running bool
 }
 
 func NewAnimation(d time.Duration, fn func(float64)) (*Animation) {
@@ -43,6 +59,67 @@ func (a *Animation) Stop() {
     CurrentScreen().StopAnimation(a)
 }
 
+// Mit Running kann abgefragt werden, ob die Animation aktuell laeuft.
+func (a *Animation) Running() (bool) {
+    s := CurrentScreen()
+    s.animMutex.Lock()
+    defer s.animMutex.Unlock()
+    return a.running
+}
+
+// Setzt die Animation auf den Anfang zurueck.
+func (a *Animation) reset(now time.Time) {
+    a.start   = now
+    a.repeats = 0
+    a.reverse = false
+}
+
+// Schaltet die Animation auf den Zeitpunkt now weiter und ruft Tick auf.
+// Liefert false, sobald die Animation beendet ist.
+func (a *Animation) step(now time.Time) (bool) {
+    curve := a.Curve
+    if curve == nil {
+        curve = AnimationLinear
+    }
+    d := a.Duration
+    if d <= 0 {
+        d = time.Nanosecond
+    }
+    done  := float64(now.Sub(a.start)) / float64(d)
+    atEnd := done >= 1.0
+    if atEnd {
+        done = 1.0
+    }
+    if a.reverse {
+        done = 1.0 - done
+    }
+    a.tick(curve(done))
+    if !atEnd {
+        return true
+    }
+
+    a.start = a.start.Add(d)
+    if a.AutoReverse && !a.reverse {
+        a.reverse = true
+        return true
+    }
+    a.reverse = false
+    if a.RepeatCount == AnimationRepeatForever || a.repeats < a.RepeatCount {
+        a.repeats++
+        return true
+    }
+    return false
+}
+
+func (a *Animation) tick(val float64) {
+    if a.Tick != nil {
+        a.Tick(val)
+    }
+    if a.Node != nil {
+        a.Node.Mark(MarkNeedsPaint)
+    }
+}
+
 func animationEaseIn(val float64) (float64) {
     return val*val
 }
@@ -0,0 +1,116 @@
+package adagui_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+)
+
+// Sammelt die Werte, mit welchen Tick aufgerufen wird.
+type tickRecorder struct {
+	mutex sync.Mutex
+	vals  []float64
+}
+
+func (r *tickRecorder) tick(v float64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.vals = append(r.vals, v)
+}
+
+func (r *tickRecorder) values() []float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]float64{}, r.vals...)
+}
+
+func waitDone(t *testing.T, done chan bool, d time.Duration) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(d):
+		t.Fatalf("animation did not complete within %v", d)
+	}
+}
+
+func TestAnimationComplete(t *testing.T) {
+	adaguitest.Screen()
+	rec := &tickRecorder{}
+	done := make(chan bool, 1)
+	a := adagui.NewAnimation(150*time.Millisecond, rec.tick)
+	a.OnComplete = func() { done <- true }
+	a.Start()
+	waitDone(t, done, time.Second)
+
+	vals := rec.values()
+	if len(vals) < 2 {
+		t.Fatalf("got only %d ticks", len(vals))
+	}
+	for i := 1; i < len(vals); i++ {
+		if vals[i] < vals[i-1] {
+			t.Errorf("tick values not increasing: %v", vals)
+			break
+		}
+	}
+	if last := vals[len(vals)-1]; last != 1.0 {
+		t.Errorf("last tick value: got %f, want 1.0", last)
+	}
+	if a.Running() {
+		t.Errorf("animation still running after completion")
+	}
+}
+
+func TestAnimationReverseRepeat(t *testing.T) {
+	adaguitest.Screen()
+	rec := &tickRecorder{}
+	done := make(chan bool, 1)
+	a := adagui.NewAnimation(60*time.Millisecond, rec.tick)
+	a.AutoReverse = true
+	a.RepeatCount = 1
+	a.OnComplete = func() { done <- true }
+	start := time.Now()
+	a.Start()
+	waitDone(t, done, 2*time.Second)
+
+	if d := time.Since(start); d < 240*time.Millisecond {
+		t.Errorf("animation completed after %v, expected at least 240ms", d)
+	}
+	ends, starts := 0, 0
+	for _, v := range rec.values() {
+		switch v {
+		case 1.0:
+			ends++
+		case 0.0:
+			starts++
+		}
+	}
+	if ends != 2 || starts != 2 {
+		t.Errorf("got %d ticks at the end and %d at the start, want 2 each",
+			ends, starts)
+	}
+	if last := rec.values()[len(rec.values())-1]; last != 0.0 {
+		t.Errorf("last tick value: got %f, want 0.0", last)
+	}
+}
+
+func TestAnimationStop(t *testing.T) {
+	adaguitest.Screen()
+	rec := &tickRecorder{}
+	a := adagui.NewAnimation(50*time.Millisecond, rec.tick)
+	a.RepeatCount = adagui.AnimationRepeatForever
+	a.OnComplete = func() { t.Errorf("OnComplete called for stopped animation") }
+	a.Start()
+	time.Sleep(200 * time.Millisecond)
+	if !a.Running() {
+		t.Fatalf("endless animation not running")
+	}
+	a.Stop()
+	n := len(rec.values())
+	time.Sleep(100 * time.Millisecond)
+	if m := len(rec.values()); m != n {
+		t.Errorf("got %d ticks after Stop", m-n)
+	}
+}
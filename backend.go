@@ -0,0 +1,118 @@
+package adagui
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/stefan-muehlebach/adatft"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+// Mit dem Typ PenEventType werden die rohen Ereignisse vom Touchscreen
+// unterschieden. Aus diesen drei Arten erzeugt der Screen (siehe
+// Screen.eventThread) alle weiteren Events wie Tap, DoubleTap oder LongPress.
+type PenEventType int
+
+const (
+	PenPress PenEventType = iota
+	PenDrag
+	PenRelease
+)
+
+func (t PenEventType) String() string {
+	switch t {
+	case PenPress:
+		return "PenPress"
+	case PenDrag:
+		return "PenDrag"
+	case PenRelease:
+		return "PenRelease"
+	default:
+		return "(Unknown PenEventType)"
+	}
+}
+
+// Ein PenEvent ist ein rohes Ereignis, wie es von einem Backend geliefert
+// wird. Die Position ist in Bildschirmkoordinaten angegeben.
+type PenEvent struct {
+	Type PenEventType
+	Pos  geom.Point
+	Time time.Time
+}
+
+// Rotation des Bildschirms im Uhrzeigersinn. Die Werte entsprechen denen
+// des Packages adatft, sind aber unabhaengig davon, damit Backends und Tests
+// ohne die Hardware-Bibliothek auskommen.
+type Rotation int
+
+const (
+	Rotate000 Rotation = iota
+	Rotate090
+	Rotate180
+	Rotate270
+)
+
+func (r Rotation) String() string {
+	switch r {
+	case Rotate000:
+		return "Rotate000"
+	case Rotate090:
+		return "Rotate090"
+	case Rotate180:
+		return "Rotate180"
+	case Rotate270:
+		return "Rotate270"
+	default:
+		return "(Unknown Rotation)"
+	}
+}
+
+// Das Interface Backend abstrahiert die Hardware, auf welcher AdaGui
+// laeuft: es nimmt fertig gezeichnete Bilder entgegen, liefert die rohen
+// Ereignisse vom Touchscreen und gibt Auskunft ueber Groesse und Rotation
+// des Bildschirms. Neben dem Backend fuer das TFT-Display von AdaFruit
+// (siehe TftBackend) gibt es mit MemBackend eine Implementation, welche
+// komplett im Speicher arbeitet und keine Hardware benoetigt.
+type Backend interface {
+	// Liefert Breite und Hoehe des Bildschirms in Pixel.
+	Size() (width, height int)
+
+	// Liefert die Rotation, mit welcher der Bildschirm betrieben wird.
+	Rotation() Rotation
+
+	// Stellt das Bild img auf dem Bildschirm dar.
+	Draw(img image.Image) error
+
+	// Stellt von img nur die Bereiche rects dar. Wird von Screen nach einem
+	// teilweisen Neuaufbau eines Fensters verwendet.
+	DrawRects(img image.Image, rects []image.Rectangle) error
+
+	// Ueber diesen Kanal werden die Ereignisse vom Touchscreen geliefert.
+	// Nach dem Schliessen des Backends werden keine Ereignisse mehr
+	// geliefert.
+	EventQ() <-chan PenEvent
+
+	// Setzt die Helligkeit der Hintergrundbeleuchtung auf level, wobei 0.0
+	// die Beleuchtung ausschaltet und 1.0 die volle Helligkeit bedeutet.
+	// Wird vom Screen zum Dimmen bei Inaktivitaet verwendet.
+	SetBacklight(level float64) error
+
+	// Gibt alle Ressourcen des Backends wieder frei.
+	Close()
+}
+
+// Erzeugt das Backend mit dem Namen name (siehe ScreenOptions.Backend).
+func newDefaultBackend(name string, rotation adatft.RotationType) (Backend,
+	error) {
+	switch name {
+	case "mem":
+		b := NewMemBackend(DefaultMemWidth, DefaultMemHeight)
+		b.SetRotation(tftRotation(rotation))
+		return b, nil
+	case "tft", "":
+		return NewTftBackend(rotation), nil
+	default:
+		return nil, fmt.Errorf("%w '%s'", ErrUnknownBackend, name)
+	}
+}
@@ -0,0 +1,181 @@
+package adagui
+
+import (
+	"image"
+	"image/draw"
+	"sync"
+	"time"
+
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+const (
+	// Groesse des Bildschirms, welche MemBackend ohne weitere Angaben
+	// verwendet. Sie entspricht dem TFT-Display von AdaFruit im Querformat.
+	DefaultMemWidth  = 320
+	DefaultMemHeight = 240
+)
+
+// MemBackend ist ein Backend, welches komplett im Speicher arbeitet. Die
+// dargestellten Bilder werden in einen Framebuffer kopiert und koennen
+// ueber Image abgefragt werden. Touch-Ereignisse koennen mit Press, Drag
+// und Release (oder allgemein mit SendEvent) eingespeist werden. Damit
+// lassen sich Applikationen ohne angeschlossene Hardware, bspw. auf einem
+// Laptop oder in automatisierten Tests, betreiben.
+type MemBackend struct {
+	width, height int
+	rotation      Rotation
+	fb            *image.RGBA
+	numFrames     int
+	lastRects     []image.Rectangle
+	backlight     float64
+	eventQ        chan PenEvent
+	closeQ        chan bool
+	closeOnce     sync.Once
+	mutex         sync.Mutex
+}
+
+// Erzeugt ein neues MemBackend mit einem Framebuffer der Groesse width x
+// height.
+func NewMemBackend(width, height int) *MemBackend {
+	b := &MemBackend{}
+	b.width = width
+	b.height = height
+	b.rotation = Rotate000
+	b.fb = image.NewRGBA(image.Rect(0, 0, width, height))
+	b.backlight = 1.0
+	b.eventQ = make(chan PenEvent)
+	b.closeQ = make(chan bool)
+	return b
+}
+
+func (b *MemBackend) Size() (int, int) {
+	return b.width, b.height
+}
+
+func (b *MemBackend) Rotation() Rotation {
+	return b.rotation
+}
+
+// Mit SetRotation kann die Rotation festgelegt werden, welche das Backend
+// meldet. Auf den Framebuffer hat dies keinen Einfluss.
+func (b *MemBackend) SetRotation(rotation Rotation) {
+	b.rotation = rotation
+}
+
+// Kopiert das Bild img in den Framebuffer.
+func (b *MemBackend) Draw(img image.Image) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	draw.Draw(b.fb, b.fb.Bounds(), img, img.Bounds().Min, draw.Src)
+	b.numFrames++
+	b.lastRects = []image.Rectangle{b.fb.Bounds()}
+	return nil
+}
+
+// Kopiert nur die Bereiche rects von img in den Framebuffer.
+func (b *MemBackend) DrawRects(img image.Image, rects []image.Rectangle) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.lastRects = b.lastRects[:0]
+	for _, r := range rects {
+		r = r.Intersect(b.fb.Bounds())
+		if r.Empty() {
+			continue
+		}
+		draw.Draw(b.fb, r, img, r.Min.Add(img.Bounds().Min), draw.Src)
+		b.lastRects = append(b.lastRects, r)
+	}
+	b.numFrames++
+	return nil
+}
+
+func (b *MemBackend) EventQ() <-chan PenEvent {
+	return b.eventQ
+}
+
+// Ohne Hintergrundbeleuchtung wird level nur festgehalten (siehe
+// Backlight).
+func (b *MemBackend) SetBacklight(level float64) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.backlight = level
+	return nil
+}
+
+// Liefert die zuletzt mit SetBacklight gesetzte Helligkeit.
+func (b *MemBackend) Backlight() float64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.backlight
+}
+
+func (b *MemBackend) Close() {
+	b.closeOnce.Do(func() {
+		close(b.closeQ)
+	})
+}
+
+// Liefert true, falls das Backend bereits geschlossen wurde.
+func (b *MemBackend) Closed() bool {
+	select {
+	case <-b.closeQ:
+		return true
+	default:
+		return false
+	}
+}
+
+// Liefert eine Kopie des aktuellen Framebuffers.
+func (b *MemBackend) Image() *image.RGBA {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	img := image.NewRGBA(b.fb.Bounds())
+	copy(img.Pix, b.fb.Pix)
+	return img
+}
+
+// Liefert die Anzahl Bilder, welche bisher dargestellt wurden.
+func (b *MemBackend) NumFrames() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.numFrames
+}
+
+// Liefert die Bereiche, welche beim letzten Bildaufbau in den Framebuffer
+// kopiert wurden.
+func (b *MemBackend) LastRects() []image.Rectangle {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	rects := make([]image.Rectangle, len(b.lastRects))
+	copy(rects, b.lastRects)
+	return rects
+}
+
+// Speist das Ereignis evt ein, als ob es vom Touchscreen kaeme. Die Methode
+// blockiert, bis das Ereignis vom Screen abgeholt wurde oder das Backend
+// geschlossen wird.
+func (b *MemBackend) SendEvent(evt PenEvent) {
+	if evt.Time.IsZero() {
+		evt.Time = time.Now()
+	}
+	select {
+	case b.eventQ <- evt:
+	case <-b.closeQ:
+	}
+}
+
+// Simuliert das Druecken auf den Bildschirm an der Position pt.
+func (b *MemBackend) Press(pt geom.Point) {
+	b.SendEvent(PenEvent{Type: PenPress, Pos: pt})
+}
+
+// Simuliert das Ziehen des Fingers an die Position pt.
+func (b *MemBackend) Drag(pt geom.Point) {
+	b.SendEvent(PenEvent{Type: PenDrag, Pos: pt})
+}
+
+// Simuliert das Loslassen des Bildschirms an der Position pt.
+func (b *MemBackend) Release(pt geom.Point) {
+	b.SendEvent(PenEvent{Type: PenRelease, Pos: pt})
+}
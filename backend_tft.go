@@ -0,0 +1,152 @@
+package adagui
+
+import (
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/stefan-muehlebach/adatft"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+// TftBackend ist das Backend fuer das TFT-Display von AdaFruit mit
+// resistivem Touchscreen. Es verwendet fuer die Ansteuerung der Hardware
+// das Package adatft.
+type TftBackend struct {
+	disp      *adatft.Display
+	touch     *adatft.Touch
+	backlight string
+	rotation  Rotation
+	eventQ    chan PenEvent
+	closeQ    chan bool
+	closeOnce sync.Once
+}
+
+// Oeffnet Display und Touchscreen mit der angegebenen Rotation.
+func NewTftBackend(rotation adatft.RotationType) *TftBackend {
+	b := &TftBackend{}
+	b.disp = adatft.OpenDisplay(rotation)
+	b.touch = adatft.OpenTouch(rotation)
+	b.rotation = tftRotation(rotation)
+	b.backlight = findBacklight()
+	b.eventQ = make(chan PenEvent)
+	b.closeQ = make(chan bool)
+
+	go b.eventThread()
+
+	return b
+}
+
+func (b *TftBackend) Size() (int, int) {
+	return adatft.Width, adatft.Height
+}
+
+func (b *TftBackend) Rotation() Rotation {
+	return b.rotation
+}
+
+// Uebersetzt die Rotation von adatft in den Typ Rotation.
+func tftRotation(rotation adatft.RotationType) Rotation {
+	switch rotation {
+	case adatft.Rotate090:
+		return Rotate090
+	case adatft.Rotate180:
+		return Rotate180
+	case adatft.Rotate270:
+		return Rotate270
+	default:
+		return Rotate000
+	}
+}
+
+func (b *TftBackend) Draw(img image.Image) error {
+	b.disp.Draw(img)
+	return nil
+}
+
+// Das Package adatft uebertraegt immer ganze Bilder an das Display, daher
+// wird hier jeweils das komplette Bild img dargestellt.
+func (b *TftBackend) DrawRects(img image.Image, rects []image.Rectangle) error {
+	return b.Draw(img)
+}
+
+func (b *TftBackend) EventQ() <-chan PenEvent {
+	return b.eventQ
+}
+
+// Die Hintergrundbeleuchtung wird ueber das sysfs des Kernels gesteuert
+// ('/sys/class/backlight'). Ist dort kein Geraet vorhanden, wird
+// ErrNoBacklight retourniert.
+func (b *TftBackend) SetBacklight(level float64) error {
+	if b.backlight == "" {
+		return ErrNoBacklight
+	}
+	data, err := os.ReadFile(filepath.Join(b.backlight, "max_brightness"))
+	if err != nil {
+		return err
+	}
+	maxLevel, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return err
+	}
+	level = min(max(level, 0.0), 1.0)
+	value := strconv.Itoa(int(math.Round(level * float64(maxLevel))))
+	return os.WriteFile(filepath.Join(b.backlight, "brightness"),
+		[]byte(value), 0644)
+}
+
+// Liefert das Verzeichnis der Hintergrundbeleuchtung im sysfs oder einen
+// leeren String, falls keine vorhanden ist.
+func findBacklight() string {
+	dirs, _ := filepath.Glob("/sys/class/backlight/*")
+	if len(dirs) == 0 {
+		return ""
+	}
+	return dirs[0]
+}
+
+func (b *TftBackend) Close() {
+	b.closeOnce.Do(func() {
+		close(b.closeQ)
+		b.touch.Close()
+		b.disp.Close()
+	})
+}
+
+// Uebersetzt die Ereignisse von adatft in PenEvents.
+func (b *TftBackend) eventThread() {
+	defer close(b.eventQ)
+	for {
+		select {
+		case <-b.closeQ:
+			return
+		case tchEvt, ok := <-b.touch.EventQ:
+			if !ok {
+				return
+			}
+			penEvt := PenEvent{
+				Pos:  geom.NewPoint(tchEvt.X, tchEvt.Y),
+				Time: tchEvt.Time,
+			}
+			switch tchEvt.Type {
+			case adatft.PenPress:
+				penEvt.Type = PenPress
+			case adatft.PenDrag:
+				penEvt.Type = PenDrag
+			case adatft.PenRelease:
+				penEvt.Type = PenRelease
+			default:
+				continue
+			}
+			select {
+			case b.eventQ <- penEvt:
+			case <-b.closeQ:
+				return
+			}
+		}
+	}
+}
@@ -6,6 +6,7 @@ package binding
 import (
 	"log"
 	"sync"
+	"sync/atomic"
 )
 
 // Die init-Funktion ist vorallem hier, damit der Import des Log-Packages
@@ -27,6 +28,86 @@ type DataItem interface {
 	RemoveCallback(f CallbackFunc)
 }
 
+// Bind-Objekte, bei welchen die Art des Aufrufs der Listener (siehe
+// DispatchMode) einzeln festgelegt werden kann, implementieren zusaetzlich
+// dieses Interface. Alle Bind-Objekte dieses Packages tun dies, fuer andere
+// Implementationen von DataItem gilt der Default (siehe
+// SetDefaultDispatchMode).
+type Dispatchable interface {
+	DispatchMode() DispatchMode
+	SetDispatchMode(mode DispatchMode)
+}
+
+// Liefert die Art, wie die Listener von data aufgerufen werden. Implementiert
+// data das Interface Dispatchable nicht, wird der Default retourniert.
+func DispatchModeOf(data DataItem) DispatchMode {
+	if d, ok := data.(Dispatchable); ok {
+		return d.DispatchMode()
+	}
+	return DefaultDispatchMode()
+}
+
+// Mit DispatchMode wird bestimmt, wie die Listener und Callback-Funktionen
+// eines Bind-Objektes bei einer Änderung des Wertes aufgerufen werden.
+type DispatchMode int32
+
+const (
+	// Jeder Listener und jede Callback-Funktion wird in einer eigenen
+	// Go-Routine aufgerufen. Die Reihenfolge der Aufrufe ist damit nicht
+	// bestimmt. Dies ist der Default.
+	DispatchAsync DispatchMode = iota
+	// Die Listener und Callback-Funktionen werden nacheinander in der
+	// Go-Routine aufgerufen, welche den Wert verändert hat. Set kehrt erst
+	// zurück, wenn alle Aufrufe abgeschlossen sind.
+	DispatchSync
+	// Die Listener und Callback-Funktionen werden der Reihe nach an den
+	// Dispatcher übergeben, welcher mit SetDispatcher hinterlegt wurde (in
+	// AdaGui ist dies der UI-Thread des Screens). Ist kein Dispatcher
+	// hinterlegt, werden sie synchron aufgerufen.
+	DispatchQueued
+)
+
+func (m DispatchMode) String() string {
+	switch m {
+	case DispatchAsync:
+		return "DispatchAsync"
+	case DispatchSync:
+		return "DispatchSync"
+	case DispatchQueued:
+		return "DispatchQueued"
+	default:
+		return "(Unknown DispatchMode)"
+	}
+}
+
+var (
+	defaultMode atomic.Int32
+	dispatcher  atomic.Pointer[func(fn func())]
+)
+
+// Legt fest, wie die Listener aller Bind-Objekte aufgerufen werden, für
+// welche mit SetDispatchMode keine eigene Art festgelegt wurde.
+func SetDefaultDispatchMode(mode DispatchMode) {
+	defaultMode.Store(int32(mode))
+}
+
+// Liefert die mit SetDefaultDispatchMode festgelegte Art des Aufrufs.
+func DefaultDispatchMode() DispatchMode {
+	return DispatchMode(defaultMode.Load())
+}
+
+// Mit SetDispatcher wird die Funktion post hinterlegt, welche im Modus
+// DispatchQueued die Aufrufe der Listener entgegennimmt. post muss die
+// übergebenen Funktionen in der gleichen Reihenfolge ausführen. Mit nil
+// wird der Dispatcher wieder entfernt.
+func SetDispatcher(post func(fn func())) {
+	if post == nil {
+		dispatcher.Store(nil)
+		return
+	}
+	dispatcher.Store(&post)
+}
+
 // Alle Typen, welche über die Aenderungen von Bind-Objekten informiert werden
 // wollen, müssen das DataListener-Interface implementieren. Im Wesentlichen
 // eine einzige Methode (DataChanged), welche als Argument das veränderte
@@ -70,6 +151,9 @@ type base struct {
 	// Der Zugriff auf weitere Strukturen dieses Typs wird über das Mutex
 	// lock gesteuert.
 	lock sync.RWMutex
+	// Die mit SetDispatchMode festgelegte Art des Aufrufs der Listener,
+	// um eins erhöht. Mit 0 gilt der Default (siehe SetDefaultDispatchMode).
+	mode atomic.Int32
 }
 
 // Mit Init werden wichtige Initialisierungen vorgenommen. Init ist bei jeder
@@ -103,16 +187,43 @@ func (b *base) RemoveCallback(f CallbackFunc) {
 	b.callbacks.Delete(&f)
 }
 
+// Liefert die Art, wie die Listener dieses Bind-Objektes aufgerufen werden.
+func (b *base) DispatchMode() DispatchMode {
+	if m := b.mode.Load(); m > 0 {
+		return DispatchMode(m - 1)
+	}
+	return DefaultDispatchMode()
+}
+
+// Legt fest, wie die Listener dieses Bind-Objektes aufgerufen werden. Die
+// Einstellung hat Vorrang vor dem Default (siehe SetDefaultDispatchMode).
+func (b *base) SetDispatchMode(mode DispatchMode) {
+	b.mode.Store(int32(mode) + 1)
+}
+
 // Die (private) Methode trigger wird immer dann aufgerufen, wenn sich der
 // Wert des Bind-Objektes ändert. Diese Methode ruft die registrierten
-// Listener-, resp. Callback-Methoden auf.
+// Listener-, resp. Callback-Methoden auf. Damit die Listener den Wert mit
+// Get abfragen können, darf trigger nicht unter dem Lock aufgerufen werden.
 func (b *base) trigger() {
+	mode := b.DispatchMode()
+	post := func(fn func()) { fn() }
+	switch mode {
+	case DispatchAsync:
+		post = func(fn func()) { go fn() }
+	case DispatchQueued:
+		if p := dispatcher.Load(); p != nil {
+			post = *p
+		}
+	}
 	b.listeners.Range(func(key, _ any) bool {
-		go key.(DataListener).DataChanged(b.super)
+		l := key.(DataListener)
+		post(func() { l.DataChanged(b.super) })
 		return true
 	})
 	b.callbacks.Range(func(f, _ any) bool {
-		go (*f.(*CallbackFunc))(b.super)
+		fn := *f.(*CallbackFunc)
+		post(func() { fn(b.super) })
 		return true
 	})
 }
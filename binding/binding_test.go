@@ -0,0 +1,124 @@
+package binding
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestDispatchSync(t *testing.T) {
+	v := NewInt()
+	v.SetDispatchMode(DispatchSync)
+	var got []int
+	v.AddCallback(func(data DataItem) {
+		// Der Wert muss innerhalb des Listeners abgefragt werden koennen.
+		got = append(got, data.(Int).Get())
+	})
+	for i := 1; i <= 3; i++ {
+		v.Set(i)
+	}
+	if len(got) != 4 || got[1] != 1 || got[2] != 2 || got[3] != 3 {
+		t.Errorf("got %v, want [0 1 2 3]", got)
+	}
+}
+
+func TestDispatchQueued(t *testing.T) {
+	var queue []func()
+	SetDispatcher(func(fn func()) { queue = append(queue, fn) })
+	defer SetDispatcher(nil)
+
+	v := NewString()
+	v.SetDispatchMode(DispatchQueued)
+	var got []string
+	v.AddListener(NewDataListener(func(data DataItem) {
+		got = append(got, data.(String).Get())
+	}))
+	v.Set("a")
+	if len(got) != 1 || len(queue) != 1 {
+		t.Fatalf("listener called before the queue was processed")
+	}
+	for _, fn := range queue {
+		fn()
+	}
+	if got[1] != "a" {
+		t.Errorf("got %q, want %q", got[1], "a")
+	}
+
+	SetDispatcher(nil)
+	v.Set("b")
+	if got[len(got)-1] != "b" {
+		t.Errorf("without a dispatcher, listeners must be called synchronously")
+	}
+}
+
+func TestDispatchDefault(t *testing.T) {
+	if DefaultDispatchMode() != DispatchAsync {
+		t.Fatalf("default dispatch mode is %v", DefaultDispatchMode())
+	}
+	v := NewBool()
+	if v.DispatchMode() != DispatchAsync {
+		t.Errorf("dispatch mode is %v, want DispatchAsync", v.DispatchMode())
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	v.AddCallback(func(data DataItem) {
+		if data.(Bool).Get() {
+			wg.Done()
+		}
+	})
+	v.Set(true)
+	wg.Wait()
+
+	SetDefaultDispatchMode(DispatchSync)
+	defer SetDefaultDispatchMode(DispatchAsync)
+	if v.DispatchMode() != DispatchSync {
+		t.Errorf("dispatch mode is %v, want DispatchSync", v.DispatchMode())
+	}
+}
+
+func TestStructReload(t *testing.T) {
+	data := struct {
+		Name  string
+		Count int
+	}{"a", 1}
+	s := BindStruct(&data)
+	s.SetDispatchMode(DispatchSync)
+	item, err := s.GetItem("Count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.(Dispatchable).SetDispatchMode(DispatchSync)
+	count := 0
+	item.AddCallback(func(d DataItem) {
+		// Aus dem Listener heraus darf der Struct abgefragt werden.
+		s.GetValue("Count")
+		count++
+	})
+	data.Count = 2
+	if err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("listener called %d times, want 2", count)
+	}
+}
+
+// Ein DataItem ohne eigene Einstellung verwendet den Default.
+type plainItem struct{}
+
+func (plainItem) AddListener(l DataListener)    {}
+func (plainItem) RemoveListener(l DataListener) {}
+func (plainItem) AddCallback(f CallbackFunc)    {}
+func (plainItem) RemoveCallback(f CallbackFunc) {}
+
+func TestDispatchModeOf(t *testing.T) {
+	SetDefaultDispatchMode(DispatchQueued)
+	defer SetDefaultDispatchMode(DispatchAsync)
+	if mode := DispatchModeOf(plainItem{}); mode != DispatchQueued {
+		t.Errorf("dispatch mode is %v, want DispatchQueued", mode)
+	}
+	v := NewInt()
+	v.SetDispatchMode(DispatchSync)
+	if mode := DispatchModeOf(v); mode != DispatchSync {
+		t.Errorf("dispatch mode is %v, want DispatchSync", mode)
+	}
+}
@@ -10,6 +10,7 @@ import (
 // Bool supports binding a bool value.
 type Bool interface {
     DataItem
+    Dispatchable
     Get() (bool)
     Set(bool)
 }
@@ -58,11 +59,12 @@ func (b *boundBool) Get() (bool) {
 
 func (b *boundBool) Set(val bool) {
     b.lock.Lock()
-    defer b.lock.Unlock()
     if *b.val == val {
+        b.lock.Unlock()
         return
     }
     *b.val = val
+    b.lock.Unlock()
     b.trigger()
 }
 
@@ -73,12 +75,13 @@ type boundExternalBool struct {
 
 func (b *boundExternalBool) Set(val bool) {
     b.lock.Lock()
-    defer b.lock.Unlock()
     if b.old == val {
+        b.lock.Unlock()
         return
     }
     *b.val = val
     b.old = val
+    b.lock.Unlock()
     b.trigger()
 }
 
@@ -89,6 +92,7 @@ func (b *boundExternalBool) Reload() {
 // Bytes supports binding a []byte value.
 type Bytes interface {
     DataItem
+    Dispatchable
     Get() ([]byte)
     Set([]byte)
 }
@@ -137,11 +141,12 @@ func (b *boundBytes) Get() ([]byte) {
 
 func (b *boundBytes) Set(val []byte) {
     b.lock.Lock()
-    defer b.lock.Unlock()
     if bytes.Equal(*b.val, val) {
+        b.lock.Unlock()
         return
     }
     *b.val = val
+    b.lock.Unlock()
     b.trigger()
 }
 
@@ -152,12 +157,13 @@ type boundExternalBytes struct {
 
 func (b *boundExternalBytes) Set(val []byte) {
     b.lock.Lock()
-    defer b.lock.Unlock()
     if bytes.Equal(b.old, val) {
+        b.lock.Unlock()
         return
     }
     *b.val = val
     b.old = val
+    b.lock.Unlock()
     b.trigger()
 }
 
@@ -168,6 +174,7 @@ func (b *boundExternalBytes) Reload() {
 // Float supports binding a float64 value.
 type Float interface {
     DataItem
+    Dispatchable
     Get() (float64)
     Set(float64)
 }
@@ -216,11 +223,12 @@ func (b *boundFloat) Get() (float64) {
 
 func (b *boundFloat) Set(val float64) {
     b.lock.Lock()
-    defer b.lock.Unlock()
     if *b.val == val {
+        b.lock.Unlock()
         return
     }
     *b.val = val
+    b.lock.Unlock()
     b.trigger()
 }
 
@@ -231,12 +239,13 @@ type boundExternalFloat struct {
 
 func (b *boundExternalFloat) Set(val float64) {
     b.lock.Lock()
-    defer b.lock.Unlock()
     if b.old == val {
+        b.lock.Unlock()
         return
     }
     *b.val = val
     b.old = val
+    b.lock.Unlock()
     b.trigger()
 }
 
@@ -247,6 +256,7 @@ func (b *boundExternalFloat) Reload() {
 // Int supports binding a int value.
 type Int interface {
     DataItem
+    Dispatchable
     Get() (int)
     Set(int)
 }
@@ -295,11 +305,12 @@ func (b *boundInt) Get() (int) {
 
 func (b *boundInt) Set(val int) {
     b.lock.Lock()
-    defer b.lock.Unlock()
     if *b.val == val {
+        b.lock.Unlock()
         return
     }
     *b.val = val
+    b.lock.Unlock()
     b.trigger()
 }
 
@@ -310,12 +321,13 @@ type boundExternalInt struct {
 
 func (b *boundExternalInt) Set(val int) {
     b.lock.Lock()
-    defer b.lock.Unlock()
     if b.old == val {
+        b.lock.Unlock()
         return
     }
     *b.val = val
     b.old = val
+    b.lock.Unlock()
     b.trigger()
 }
 
@@ -326,6 +338,7 @@ func (b *boundExternalInt) Reload() {
 // Rune supports binding a rune value.
 type Rune interface {
     DataItem
+    Dispatchable
     Get() (rune)
     Set(rune)
 }
@@ -374,11 +387,12 @@ func (b *boundRune) Get() (rune) {
 
 func (b *boundRune) Set(val rune) {
     b.lock.Lock()
-    defer b.lock.Unlock()
     if *b.val == val {
+        b.lock.Unlock()
         return
     }
     *b.val = val
+    b.lock.Unlock()
     b.trigger()
 }
 
@@ -389,12 +403,13 @@ type boundExternalRune struct {
 
 func (b *boundExternalRune) Set(val rune) {
     b.lock.Lock()
-    defer b.lock.Unlock()
     if b.old == val {
+        b.lock.Unlock()
         return
     }
     *b.val = val
     b.old = val
+    b.lock.Unlock()
     b.trigger()
 }
 
@@ -405,6 +420,7 @@ func (b *boundExternalRune) Reload() {
 // String supports binding a string value.
 type String interface {
     DataItem
+    Dispatchable
     Get() (string)
     Set(string)
 }
@@ -453,11 +469,12 @@ func (b *boundString) Get() (string) {
 
 func (b *boundString) Set(val string) {
     b.lock.Lock()
-    defer b.lock.Unlock()
     if *b.val == val {
+        b.lock.Unlock()
         return
     }
     *b.val = val
+    b.lock.Unlock()
     b.trigger()
 }
 
@@ -468,12 +485,13 @@ type boundExternalString struct {
 
 func (b *boundExternalString) Set(val string) {
     b.lock.Lock()
-    defer b.lock.Unlock()
     if b.old == val {
+        b.lock.Unlock()
         return
     }
     *b.val = val
     b.old = val
+    b.lock.Unlock()
     b.trigger()
 }
 
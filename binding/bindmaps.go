@@ -15,6 +15,7 @@ var (
 // Since: 2.0
 type DataMap interface {
 	DataItem
+	Dispatchable
 	GetItem(string) (DataItem, error)
 	Keys() []string
 }
@@ -57,7 +58,7 @@ func BindUntypedMap(d *map[string]interface{}) ExternalUntypedMap {
 	m := &mapBase{items: make(map[string]reflectUntyped), val: d, updateExternal: true}
 
 	for k := range *d {
-		m.setItem(k, bindUntypedMapValue(d, k, m.updateExternal))
+		m.items[k] = bindUntypedMapValue(d, k, m.updateExternal)
 	}
 
 	return m
@@ -110,10 +111,13 @@ func BindStruct(i interface{}) Struct {
 	return s
 }
 
+// Mit set wird nur der Wert gesetzt, die Listener muessen anschliessend
+// (ausserhalb des Locks) mit trigger benachrichtigt werden.
 type reflectUntyped interface {
 	DataItem
 	get() (interface{}, error)
 	set(interface{}) error
+	trigger()
 }
 
 type mapBase struct {
@@ -151,9 +155,8 @@ func (b *mapBase) Keys() []string {
 
 func (b *mapBase) Delete(key string) {
 	b.lock.Lock()
-	defer b.lock.Unlock()
-
 	delete(b.items, key)
+	b.lock.Unlock()
 
 	b.trigger()
 }
@@ -182,47 +185,60 @@ func (b *mapBase) GetValue(key string) (interface{}, error) {
 
 func (b *mapBase) Reload() error {
 	b.lock.Lock()
-	defer b.lock.Unlock()
+	changed, err := b.doReload()
+	b.lock.Unlock()
 
-	return b.doReload()
+	notify(changed)
+	return err
 }
 
 func (b *mapBase) Set(v map[string]interface{}) error {
 	b.lock.Lock()
-	defer b.lock.Unlock()
-
 	if b.val == nil { // was not initialized with a blank value, recover
 		b.val = &v
+		b.lock.Unlock()
 		b.trigger()
 		return nil
 	}
 
 	*b.val = v
-	return b.doReload()
+	changed, err := b.doReload()
+	b.lock.Unlock()
+
+	notify(changed)
+	return err
 }
 
 func (b *mapBase) SetValue(key string, d interface{}) error {
 	b.lock.Lock()
-	defer b.lock.Unlock()
-
 	if i, ok := b.items[key]; ok {
-		return i.set(d)
+		err := i.set(d)
+		b.lock.Unlock()
+		if err == nil {
+			i.trigger()
+		}
+		return err
 	}
 
 	(*b.val)[key] = d
-	item := bindUntypedMapValue(b.val, key, b.updateExternal)
-	b.setItem(key, item)
+	b.items[key] = bindUntypedMapValue(b.val, key, b.updateExternal)
+	b.lock.Unlock()
+
+	b.trigger()
 	return nil
 }
 
-func (b *mapBase) doReload() (retErr error) {
-	changed := false
+// Gleicht die Items mit dem Inhalt der Map ab. Retourniert werden alle
+// Bind-Objekte, deren Listener nach dem Freigeben des Locks benachrichtigt
+// werden müssen.
+func (b *mapBase) doReload() (changed []triggerer, retErr error) {
+	mapChanged := false
 	// add new
 	for key := range *b.val {
 		_, found := b.items[key]
 		if !found {
-			b.setItem(key, bindUntypedMapValue(b.val, key, b.updateExternal))
-			changed = true
+			b.items[key] = bindUntypedMapValue(b.val, key, b.updateExternal)
+			mapChanged = true
 		}
 	}
 
@@ -231,33 +247,42 @@ func (b *mapBase) doReload() (retErr error) {
 		_, found := (*b.val)[key]
 		if !found {
 			delete(b.items, key)
-			changed = true
+			mapChanged = true
 		}
 	}
-	if changed {
-		b.trigger()
+	if mapChanged {
+		changed = append(changed, b)
 	}
 
 	for k, item := range b.items {
 		var err error
+		set := true
 
 		if b.updateExternal {
-			err = item.(*boundExternalMapValue).setIfChanged((*b.val)[k])
+			set, err = item.(*boundExternalMapValue).setIfChanged((*b.val)[k])
 		} else {
 			err = item.(*boundMapValue).set((*b.val)[k])
 		}
 
 		if err != nil {
 			retErr = err
+		} else if set {
+			changed = append(changed, item)
 		}
 	}
 	return
 }
 
-func (b *mapBase) setItem(key string, d reflectUntyped) {
-	b.items[key] = d
+// Alle Bind-Objekte, deren Listener nach einer Änderung benachrichtigt
+// werden können.
+type triggerer interface {
+	trigger()
+}
 
-	b.trigger()
+func notify(list []triggerer) {
+	for _, t := range list {
+		t.trigger()
+	}
 }
 
 type boundStruct struct {
@@ -267,9 +292,9 @@ type boundStruct struct {
 }
 
 func (b *boundStruct) Reload() (retErr error) {
-	b.lock.Lock()
-	defer b.lock.Unlock()
+	var updates []func() error
 
+	b.lock.Lock()
 	v := reflect.ValueOf(b.orig).Elem()
 	t := v.Type()
 	for j := 0; j < v.NumField(); j++ {
@@ -289,21 +314,31 @@ func (b *boundStruct) Reload() (retErr error) {
 			continue
 		}
 
-		var err error
+		// Die Items werden erst nach dem Freigeben des Locks gesetzt, damit
+		// ihre Listener den Struct abfragen koennen.
+		item := b.items[key]
 		switch kind {
 		case reflect.Bool:
-			err = b.items[key].(*reflectBool).Set(f.Bool())
+			val := f.Bool()
+			updates = append(updates, func() error { return item.(*reflectBool).Set(val) })
 		case reflect.Float32, reflect.Float64:
-			err = b.items[key].(*reflectFloat).Set(f.Float())
+			val := f.Float()
+			updates = append(updates, func() error { return item.(*reflectFloat).Set(val) })
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			err = b.items[key].(*reflectInt).Set(int(f.Int()))
+			val := int(f.Int())
+			updates = append(updates, func() error { return item.(*reflectInt).Set(val) })
 		case reflect.String:
-			err = b.items[key].(*reflectString).Set(f.String())
+			val := f.String()
+			updates = append(updates, func() error { return item.(*reflectString).Set(val) })
 		}
-		if err != nil {
+		(*b.val)[key] = f.Interface()
+	}
+	b.lock.Unlock()
+
+	for _, update := range updates {
+		if err := update(); err != nil {
 			retErr = err
 		}
-		(*b.val)[key] = f.Interface()
 	}
 	return
 }
@@ -336,8 +371,6 @@ func (b *boundMapValue) get() (interface{}, error) {
 
 func (b *boundMapValue) set(val interface{}) error {
 	(*b.val)[b.key] = val
-
-	b.trigger()
 	return nil
 }
 
@@ -347,13 +380,13 @@ type boundExternalMapValue struct {
 	old interface{}
 }
 
-func (b *boundExternalMapValue) setIfChanged(val interface{}) error {
+func (b *boundExternalMapValue) setIfChanged(val interface{}) (bool, error) {
 	if val == b.old {
-		return nil
+		return false, nil
 	}
 	b.old = val
 
-	return b.set(val)
+	return true, b.set(val)
 }
 
 type boundReflect struct {
@@ -373,8 +406,6 @@ func (b *boundReflect) set(val interface{}) (err error) {
 		}
 	}()
 	b.val.Set(reflect.ValueOf(val))
-
-	b.trigger()
 	return nil
 }
 
@@ -8,6 +8,7 @@ import (
 // Untyped supports binding a interface{} value.
 type Untyped interface {
 	DataItem
+	Dispatchable
 	Get() interface{}
 	Set(interface{})
 }
@@ -60,11 +61,12 @@ func (b *boundUntyped) Get() interface{} {
 
 func (b *boundUntyped) Set(val interface{}) {
 	b.lock.Lock()
-	defer b.lock.Unlock()
 	if b.val.Interface() == val {
+		b.lock.Unlock()
 		return
 	}
 	b.val.Set(reflect.ValueOf(val))
+	b.lock.Unlock()
 	b.trigger()
 }
 
@@ -75,12 +77,13 @@ type boundExternalUntyped struct {
 
 func (b *boundExternalUntyped) Set(val interface{}) {
 	b.lock.Lock()
-	defer b.lock.Unlock()
 	if b.old == val {
+		b.lock.Unlock()
 		return
 	}
 	b.val.Set(reflect.ValueOf(val))
 	b.old = val
+	b.lock.Unlock()
 	b.trigger()
 }
 
@@ -70,8 +70,6 @@ func (s *stringFromBool) Set(str string) {
 }
 
 func (s *stringFromBool) DataChanged(data DataItem) {
-    s.lock.RLock()
-    defer s.lock.RUnlock()
     s.trigger()
 }
 
@@ -138,8 +136,6 @@ func (s *stringFromFloat) Set(str string) {
 }
 
 func (s *stringFromFloat) DataChanged(data DataItem) {
-    s.lock.RLock()
-    defer s.lock.RUnlock()
     s.trigger()
 }
 
@@ -206,8 +202,6 @@ func (s *stringFromInt) Set(str string) {
 }
 
 func (s *stringFromInt) DataChanged(data DataItem) {
-    s.lock.RLock()
-    defer s.lock.RUnlock()
     s.trigger()
 }
 
@@ -286,8 +280,6 @@ func (s *stringToBool) Set(val bool) {
 }
 
 func (s *stringToBool) DataChanged(data DataItem) {
-    s.lock.RLock()
-    defer s.lock.RUnlock()
     s.trigger()
 }
 
@@ -366,8 +358,6 @@ func (s *stringToFloat) Set(val float64) {
 }
 
 func (s *stringToFloat) DataChanged(data DataItem) {
-    s.lock.RLock()
-    defer s.lock.RUnlock()
     s.trigger()
 }
 
@@ -446,7 +436,5 @@ func (s *stringToInt) Set(val int) {
 }
 
 func (s *stringToInt) DataChanged(data DataItem) {
-    s.lock.RLock()
-    defer s.lock.RUnlock()
     s.trigger()
 }
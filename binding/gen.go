@@ -15,6 +15,7 @@ const itemBindTemplate = `
 // {{ .Name }} supports binding a {{ .Type }} value.
 type {{ .Name }} interface {
     DataItem
+    Dispatchable
     Get() ({{ .Type }})
     Set({{ .Type }})
 }
@@ -63,17 +64,19 @@ func (b *bound{{ .Name }}) Get() ({{ .Type }}) {
 
 func (b *bound{{ .Name }}) Set(val {{ .Type }}) {
     b.lock.Lock()
-    defer b.lock.Unlock()
     {{- if eq .Comparator "" }}
     if *b.val == val {
+        b.lock.Unlock()
         return
     }
     {{- else }}
     if {{ .Comparator }}(*b.val, val) {
+        b.lock.Unlock()
         return
     }
     {{- end }}
     *b.val = val
+    b.lock.Unlock()
     b.trigger()
 }
 
@@ -84,18 +87,20 @@ type boundExternal{{ .Name }} struct {
 
 func (b *boundExternal{{ .Name }}) Set(val {{ .Type }}) {
     b.lock.Lock()
-    defer b.lock.Unlock()
     {{- if eq .Comparator "" }}
     if b.old == val {
+        b.lock.Unlock()
         return
     }
     {{- else }}
     if {{ .Comparator }}(b.old, val) {
+        b.lock.Unlock()
         return
     }
     {{- end }}
     *b.val = val
     b.old = val
+    b.lock.Unlock()
     b.trigger()
 }
 
@@ -174,8 +179,6 @@ func (s *stringFrom{{ .Name }}) Set(str string) {
 }
 
 func (s *stringFrom{{ .Name }}) DataChanged(data DataItem) {
-    s.lock.RLock()
-    defer s.lock.RUnlock()
     s.trigger()
 }
 `
@@ -262,8 +265,6 @@ func (s *stringTo{{ .Name }}) Set(val {{ .Type }}) {
 }
 
 func (s *stringTo{{ .Name }}) DataChanged(data DataItem) {
-    s.lock.RLock()
-    defer s.lock.RUnlock()
     s.trigger()
 }
 `
@@ -0,0 +1,112 @@
+package adagui
+
+import (
+	"github.com/stefan-muehlebach/adagui/touch"
+)
+
+// Mit CapturePointer erhaelt der Node n alle weiteren Touch-Events der
+// aktuellen Geste, unabhaengig davon, wo sich der Finger befindet und ob n
+// in der Zwischenzeit verschoben oder aus dem SceneGraph entfernt wurde.
+// Die Events werden n direkt (ohne Capture- und Bubble-Phase) zugestellt.
+// Damit kann ein Node die Geste auch an einen anderen Node weitergeben.
+// Der bisherige Empfaenger erhaelt dabei ein Leave-Event. Der Pointer
+// bleibt bis zum Aufruf von ReleasePointer, bis zum naechsten Press-Event
+// oder bis zum Abbruch der Geste (siehe touch.Event.Canceled) gefangen.
+// Die Methode ist fuer den Aufruf aus einem Event-Handler gedacht. Der
+// Pointer wird ohne Synchronisation gesetzt; CapturePointer, ReleasePointer
+// und PointerCapture duerfen daher nur im UI-Thread aufgerufen werden.
+func (w *Window) CapturePointer(n Node) {
+	w.capture = n
+}
+
+// Gibt den mit CapturePointer gefangenen Pointer wieder frei. Die weiteren
+// Events gehen wieder an den Node, auf welchen urspruenglich gedrueckt
+// wurde. Darf nur im UI-Thread aufgerufen werden.
+func (w *Window) ReleasePointer() {
+	w.capture = nil
+}
+
+// Liefert den Node, welcher den Pointer gefangen hat oder nil. Darf nur
+// im UI-Thread aufgerufen werden.
+func (w *Window) PointerCapture() Node {
+	return w.capture
+}
+
+// In pointerState fuehrt das Fenster Buch ueber die aktuelle Geste: target
+// ist der Node, auf welchen gedrueckt wurde, hover der Node, fuer welchen
+// Enter- und Leave-Events erzeugt werden und inside, ob sich der Finger
+// gerade innerhalb von hover befindet.
+type pointerState struct {
+	target, hover Node
+	inside        bool
+}
+
+// Verarbeitet ein Touch-Event (mit Positionen in Bildschirmkoordinaten).
+// Muss im UI-Thread aufgerufen werden.
+func (w *Window) handleTouch(ptr *pointerState, evt touch.Event) {
+	if evt.Type == touch.TypePress {
+		ptr.target = w.root.SelectTarget(evt.Pos)
+		Debugf(Events, "new target    : %T", ptr.target)
+		w.capture = nil
+		ptr.hover, ptr.inside = ptr.target, true
+	}
+	recv := w.receiver(ptr)
+	if recv == nil {
+		return
+	}
+	w.updateHover(ptr, evt)
+
+	if evt.Type == touch.TypeDrag {
+		inside := recv.Contains(recv.Screen2Local(evt.Pos))
+		if inside != ptr.inside {
+			ptr.inside = inside
+			newEvent := evt
+			if inside {
+				newEvent.Type = touch.TypeEnter
+			} else {
+				newEvent.Type = touch.TypeLeave
+			}
+			w.deliver(recv, newEvent, touch.PhaseTarget)
+		}
+	}
+
+	// Hat ein Node den Pointer gefangen, dann erhaelt nur noch er die
+	// Events, und zwar ohne Weitergabe durch den SceneGraph. Beansprucht
+	// ein Node die Geste, so faengt er damit den Pointer.
+	if w.capture != nil {
+		w.deliver(w.capture, evt, touch.PhaseTarget)
+	} else if n := w.dispatch(ptr.target, evt); n != nil {
+		Debugf(Events, "gesture claimed by: %T", n)
+		w.capture = n
+	}
+	// Bei einer abgebrochenen Geste wird auch der Pointer freigegeben.
+	if evt.Canceled {
+		w.capture = nil
+	}
+	w.updateHover(ptr, evt)
+}
+
+// Liefert den aktuellen Empfaenger der Events.
+func (w *Window) receiver(ptr *pointerState) Node {
+	if w.capture != nil {
+		return w.capture
+	}
+	return ptr.target
+}
+
+// Hat der Empfaenger der Events gewechselt (bspw. durch CapturePointer),
+// dann erhaelt der bisherige ein Leave-Event, falls sich der Finger in ihm
+// befand.
+func (w *Window) updateHover(ptr *pointerState, evt touch.Event) {
+	recv := w.receiver(ptr)
+	if recv == ptr.hover {
+		return
+	}
+	if ptr.hover != nil && ptr.inside {
+		newEvent := evt
+		newEvent.Type = touch.TypeLeave
+		w.deliver(ptr.hover, newEvent, touch.PhaseTarget)
+	}
+	ptr.hover = recv
+	ptr.inside = recv != nil && recv.Contains(recv.Screen2Local(evt.Pos))
+}
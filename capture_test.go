@@ -0,0 +1,107 @@
+package adagui_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+	"github.com/stefan-muehlebach/adagui/touch"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+// Baut eine Group mit zwei Buttons nebeneinander auf.
+func twoButtons() (*adagui.Group, *adagui.Button, *adagui.Button) {
+	a := adagui.NewButton(60, 40)
+	b := adagui.NewButton(60, 40)
+	grp := adagui.NewGroup()
+	grp.Add(a, b)
+	a.SetPos(geom.Point{X: 40, Y: 100})
+	b.SetPos(geom.Point{X: 200, Y: 100})
+	return grp, a, b
+}
+
+func TestCapturePointerHandoff(t *testing.T) {
+	grp, a, b := twoButtons()
+	h := adaguitest.New(t, grp)
+	aRec, bRec := adaguitest.NewRecorder(), adaguitest.NewRecorder()
+	a.SetTouchFunc(aRec.Record, touch.TypeDrag, touch.TypeLeave,
+		touch.TypeRelease)
+	b.SetTouchFunc(bRec.Record, touch.TypeEnter, touch.TypeLeave,
+		touch.TypeDrag, touch.TypeRelease)
+	a.SetOnPress(func(evt touch.Event) {
+		h.Window.CapturePointer(b)
+	})
+
+	pa, pb := h.Center(a), h.Center(b)
+	h.DragPath(pa, pa.AddXY(0, 5), pb, pb.AddXY(0, 50))
+
+	if a.Pushed() {
+		t.Errorf("button a is still pushed")
+	}
+	if got := fmt.Sprint(aRec.Types()); got != "[Leave]" {
+		t.Errorf("button a got %s, want [Leave]", got)
+	}
+	want := "[Drag Enter Drag Leave Drag Release]"
+	if got := fmt.Sprint(bRec.Types()); got != want {
+		t.Errorf("button b got %s, want %s", got, want)
+	}
+	if h.Window.PointerCapture() != b {
+		t.Errorf("pointer not captured by b after the release")
+	}
+}
+
+func TestCaptureAfterRemove(t *testing.T) {
+	grp, a, _ := twoButtons()
+	h := adaguitest.New(t, grp)
+	rec := adaguitest.NewRecorder()
+	a.SetTouchFunc(rec.Record, touch.TypeDrag, touch.TypeRelease)
+	a.SetOnPress(func(evt touch.Event) {
+		h.Window.CapturePointer(a)
+		a.Remove()
+	})
+
+	pa := h.Center(a)
+	h.DragPath(pa, pa.AddXY(20, 0), pa.AddXY(40, 0))
+	if got := fmt.Sprint(rec.Types()); got != "[Drag Drag Release]" {
+		t.Errorf("removed button got %s", got)
+	}
+}
+
+func TestReleasePointer(t *testing.T) {
+	grp, a, b := twoButtons()
+	h := adaguitest.New(t, grp)
+	rec := adaguitest.NewRecorder()
+	a.SetTouchFunc(rec.Record, touch.TypeDrag, touch.TypeRelease)
+	a.SetOnPress(func(evt touch.Event) {
+		h.Window.CapturePointer(b)
+	})
+	b.SetOnDrag(func(evt touch.Event) {
+		h.Window.ReleasePointer()
+	})
+
+	pa := h.Center(a)
+	h.DragPath(pa, pa.AddXY(5, 0), pa.AddXY(10, 0))
+	if got := fmt.Sprint(rec.Types()); got != "[Drag Release]" {
+		t.Errorf("button a got %s after ReleasePointer", got)
+	}
+}
+
+func TestSliderCapturesPointer(t *testing.T) {
+	sld := adagui.NewSlider(150, adagui.Horizontal)
+	sp := adagui.NewScrollPanel(200, 150)
+	sp.Add(sld)
+	sld.SetPos(geom.Point{X: 25, Y: 50})
+	sp.SetCaptureFunc(func(evt touch.Event) {
+		evt.ClaimGesture()
+	}, touch.TypeDrag)
+	h := adaguitest.New(t, centered(sp))
+
+	r := sld.Rect()
+	p0 := sp.Local2Screen(geom.Point{X: r.Min.X, Y: r.Center().Y})
+	p1 := sp.Local2Screen(geom.Point{X: r.Max.X, Y: r.Center().Y})
+	h.DragLine(p0, p1, 8)
+	if v := sld.Value(); v < 0.9 {
+		t.Errorf("slider value is %v, the drag was stolen by the panel", v)
+	}
+}
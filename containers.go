@@ -11,13 +11,13 @@ package adagui
 
 import (
 	"container/list"
+	"fmt"
 	"github.com/stefan-muehlebach/adagui/binding"
 	"github.com/stefan-muehlebach/adagui/touch"
 	"github.com/stefan-muehlebach/gg"
 	"github.com/stefan-muehlebach/gg/geom"
 	"golang.org/x/image/draw"
 	"image"
-	"log"
 )
 
 // Alle GUI-Typen, welche weitere Nodes verwalten können (Fenster, Panels,
@@ -31,6 +31,12 @@ type ContainerEmbed struct {
 	touch.TouchEmbed
 	ChildList *list.List
 	Layout    LayoutManager
+
+	// Zwischengespeicherte minimale Groesse (siehe MinSize). Sie wird mit
+	// InvalidateMeasure verworfen.
+	measured       bool
+	measuredSize   geom.Point
+	measuredLayout LayoutManager
 }
 
 func (c *ContainerEmbed) Init() {
@@ -39,17 +45,41 @@ func (c *ContainerEmbed) Init() {
 	c.Layout = &NullLayout{}
 }
 
-func (c *ContainerEmbed) Add(n ...Node) {
+// Liefert das Embed selber. Damit kann ueber einen beliebigen Node auf die
+// Liste der Kinder zugegriffen werden, sofern er ein Container ist.
+func (c *ContainerEmbed) containerEmbed() *ContainerEmbed {
+	return c
+}
+
+// Liefert das ContainerEmbed von n oder nil, falls n kein Container ist.
+func asContainer(n Node) *ContainerEmbed {
+	if c, ok := n.(interface{ containerEmbed() *ContainerEmbed }); ok {
+		return c.containerEmbed()
+	}
+	return nil
+}
+
+// Fuegt die Nodes n dem Container hinzu. Nodes, welche bereits einem
+// Container hinzugefuegt wurden, werden uebersprungen und es wird
+// ErrAlreadyAttached retourniert. Alle uebrigen Nodes werden trotzdem
+// hinzugefuegt. Das Layout wird nicht sofort, sondern erst vor dem
+// naechsten Bildaufbau (resp. mit UpdateLayout) durchgefuehrt.
+func (c *ContainerEmbed) Add(n ...Node) error {
+	var err error
 	for _, node := range n {
 		embed := node.Wrappee()
 		if embed.Parent != nil {
-			log.Fatal("Container: Add called for an attached child")
+			err = fmt.Errorf("%w: %T", ErrAlreadyAttached, node)
+			continue
 		}
 		embed.Win = c.Win
 		embed.Parent = c
+		ownProps(node)
 		c.ChildList.PushBack(embed)
-		c.layout()
+		embed.Mark(MarkNeedsPaint)
 	}
+	c.InvalidateLayout()
+	return err
 }
 
 func (c *ContainerEmbed) Del(n Node) {
@@ -59,37 +89,64 @@ func (c *ContainerEmbed) Del(n Node) {
 			continue
 		}
 		embed := node.Wrappee()
+		embed.damage()
 		embed.Win = nil
 		embed.Parent = nil
 		c.ChildList.Remove(elem)
 		break
 	}
-	c.layout()
+	c.InvalidateLayout()
 }
 
 func (c *ContainerEmbed) DelAll() {
 	for elem := c.ChildList.Front(); elem != nil; elem = elem.Next() {
 		embed := elem.Value.(*Embed)
+		embed.damage()
 		embed.Parent = nil
 		embed.Win = nil
 	}
 	c.ChildList.Init()
-	c.layout()
+	c.InvalidateLayout()
 }
 
 func (c *ContainerEmbed) SetSize(s geom.Point) {
+	if s.Eq(c.size) {
+		return
+	}
 	c.Embed.SetSize(s)
-	c.layout()
+	c.Mark(MarkNeedsLayout)
 }
 
+// Ohne fixe minimale Groesse wird diese vom LayoutManager aus den Kindern
+// ermittelt und bis zur naechsten Aenderung (siehe InvalidateMeasure)
+// zwischengespeichert.
 func (c *ContainerEmbed) MinSize() geom.Point {
-	ms := geom.Point{}
-	if c.minSize.Eq(geom.Point{0, 0}) {
-		ms = c.Layout.MinSize(c.ChildList)
-	} else {
-		ms = c.Embed.MinSize()
+	if !c.minSize.Eq(geom.Point{X: 0, Y: 0}) {
+		return c.Embed.MinSize()
 	}
-	return ms
+	if !c.measured || c.measuredLayout != c.Layout {
+		c.measuredSize = c.Layout.MinSize(c.ChildList)
+		c.measuredLayout = c.Layout
+		c.measured = true
+	}
+	return c.measuredSize
+}
+
+// Markiert den Container fuer ein neues Layout. Muss aufgerufen werden,
+// wenn die Parameter des LayoutManagers geaendert wurden; beim Hinzufuegen
+// und Entfernen von Kindern geschieht dies automatisch.
+func (c *ContainerEmbed) InvalidateLayout() {
+	c.InvalidateMeasure()
+	c.Mark(MarkNeedsLayout)
+}
+
+// Fuehrt ein ausstehendes Layout des Containers und aller Container
+// darunter sofort durch. Fuer Container in einem Fenster geschieht dies vor
+// jedem Bildaufbau automatisch; die Methode wird nur benoetigt, wenn Pos
+// und Size ohne Fenster oder zwischen zwei Bildaufbauten aktuell sein
+// muessen. Darf nur im UI-Thread aufgerufen werden.
+func (c *ContainerEmbed) UpdateLayout() {
+	c.Embed.updateLayout()
 }
 
 func (c *ContainerEmbed) Paint(gc *gg.Context) {
@@ -107,12 +164,23 @@ func (c *ContainerEmbed) Paint(gc *gg.Context) {
 	}
 }
 
+// Markierungen der Kinder werden nach oben weitergereicht. Als beschaedigt
+// gilt dabei nur das Kind, nicht jedoch der ganze Container. Muss ein Kind
+// neu vermessen oder angeordnet werden, wird der Container (und damit auch
+// alle uebergeordneten Container) fuer das Layout markiert.
 func (c *ContainerEmbed) OnChildMarked(child Node, newMarks Marks) {
-	c.Mark(newMarks)
+	if newMarks&layoutMarks != 0 {
+		newMarks = newMarks&^MarkNeedsMeasure | MarkNeedsLayout
+	}
+	c.propagateMarks(newMarks)
 }
 
 func (c *ContainerEmbed) SelectTarget(pt geom.Point) Node {
 	Debugf(Coordinates, "[%T], pt: %v", c.Wrapper, pt)
+	if !c.enabled {
+		Debugf(Coordinates, "container is disabled")
+		return nil
+	}
 	if !c.Wrapper.Contains(pt) {
 	    Debugf(Coordinates, "is not inside this container")
 		return nil
@@ -135,6 +203,9 @@ func (c *ContainerEmbed) SelectTarget(pt geom.Point) Node {
 	return c.Wrapper
 }
 
+// Ordnet die Kinder sofort mit dem LayoutManager an. Normalerweise wird
+// das Layout jedoch mit InvalidateLayout angefordert und vor dem naechsten
+// Bildaufbau durchgefuehrt.
 func (c *ContainerEmbed) layout() {
 	if c.Layout == nil {
 		return
@@ -194,36 +265,55 @@ func NewPanel(w, h float64) *Panel {
 	return p
 }
 
+// Der Hintergrund wird mit der Farbe, resp. dem Farbverlauf oder dem Bild
+// aus den Properties gefuellt. Das Bild im Feld Image wird zusaetzlich
+// darueber gezeichnet.
 func (p *Panel) Paint(gc *gg.Context) {
 	Debugf(Painting, "[%T], LocalBounds: %v", p.Wrapper, p.LocalBounds())
 
+	p.paintShadow(gc, p.DropShadow(), p.LocalBounds(), 0.0)
 	gc.DrawRectangle(p.LocalBounds().AsCoord())
 	gc.SetFillColor(p.Color())
 	gc.SetStrokeColor(p.BorderColor())
 	gc.SetStrokeWidth(p.BorderWidth())
-	gc.FillStroke()
+	fillStrokeStyled(gc, p.LocalBounds(), p.FillGradient(),
+		p.BackgroundImage())
 
 	if p.Image != nil {
+		var opts *draw.Options
+		if mask := gc.Mask(); mask != nil {
+			opts = &draw.Options{DstMask: mask}
+		}
 		dst := gc.Image().(*image.RGBA)
-		draw.NearestNeighbor.Transform(dst, p.Matrix().AsAff3(),
-			p.Image, p.LocalBounds().Int(), draw.Over, nil)
+		draw.NearestNeighbor.Transform(dst, gc.Matrix().AsAff3(),
+			p.Image, p.LocalBounds().Int(), draw.Over, opts)
 	}
 
+	// Push und Pop (statt ResetClip) sorgen dafuer, dass ein bereits
+	// bestehender Clipping-Bereich erhalten bleibt.
 	if p.IsClipping {
+		gc.Push()
 		gc.DrawRectangle(p.LocalBounds().AsCoord())
 		gc.Clip()
 		p.ContainerEmbed.Paint(gc)
-		gc.ResetClip()
+		gc.Pop()
 	} else {
 		p.ContainerEmbed.Paint(gc)
 	}
 }
 
-// Komplexeres Panel mit Scrollmoeglichkeit.
+// Komplexeres Panel mit Scrollmoeglichkeit. Ist die virtuelle Groesse
+// groesser als das Panel, kann der Inhalt mit dem Finger verschoben werden,
+// auch wenn die Bewegung auf einem Kind (bspw. einem Button) beginnt: sobald
+// sich der Finger um mehr als NearThreshold (siehe touch.GestureConfig)
+// bewegt hat, beansprucht das Panel die Geste in der Capture-Phase. Kinder,
+// welche den Pointer fangen (bspw. Slider), sind davon nicht betroffen.
 type ScrollPanel struct {
 	ContainerEmbed
 	Image                               image.Image
-	virtSize, sizeDiff, viewPort, refPt geom.Point
+	virtSize, sizeDiff, refPt geom.Point
+	dragStart                 geom.Point
+	isDragging                bool
 }
 
 func NewScrollPanel(w, h float64) *ScrollPanel {
@@ -234,11 +324,45 @@ func NewScrollPanel(w, h float64) *ScrollPanel {
 
 	p.SetMinSize(geom.Point{w, h})
 	p.SetVirtualSize(p.Size())
-	p.viewPort = geom.Point{0, 0}
 	p.refPt = geom.Point{0, 0}
 	return p
 }
 
+func (p *ScrollPanel) OnInputEvent(evt touch.Event) {
+	if evt.Phase != touch.PhaseBubble {
+		p.scrollByDrag(evt)
+	}
+	p.CallTouchFunc(evt)
+}
+
+// Verschiebt den Inhalt, sobald die Bewegung des Fingers die Schwelle fuer
+// einen Drag ueberschritten hat.
+func (p *ScrollPanel) scrollByDrag(evt touch.Event) {
+	switch evt.Type {
+	case touch.TypePress:
+		p.dragStart = p.refPt
+		p.isDragging = false
+	case touch.TypeDrag:
+		if !p.isDragging {
+			threshold := touch.NearThreshold
+			if cfg := nodeGestureConfig(p); cfg != nil {
+				threshold = cfg.NearThreshold
+			}
+			if evt.Pos.Distance(evt.InitPos) <= threshold ||
+				(p.sizeDiff.X <= 0 && p.sizeDiff.Y <= 0) {
+				return
+			}
+			p.isDragging = true
+			evt.ClaimGesture()
+		}
+		refPt := p.dragStart.Sub(evt.Pos.Sub(evt.InitPos))
+		p.refPt = refPt.Max(geom.Point{}).Min(p.sizeDiff)
+		p.Mark(MarkNeedsPaint)
+	case touch.TypeRelease:
+		p.isDragging = false
+	}
+}
+
 func (p *ScrollPanel) Paint(gc *gg.Context) {
 	Debugf(Painting, "[%T], LocalBounds: %v, RefPt: %v", p.Wrapper,
 	    p.LocalBounds(), p.refPt)
@@ -249,8 +373,9 @@ func (p *ScrollPanel) Paint(gc *gg.Context) {
 	gc.SetStrokeColor(p.BorderColor())
 	gc.SetStrokeWidth(p.BorderWidth())
 	gc.FillStroke()
+	// Der Clipping-Bereich wird durch das Pop in Embed.Paint wieder
+	// aufgehoben.
 	p.ContainerEmbed.Paint(gc)
-	gc.ResetClip()
 }
 
 func (p *ScrollPanel) LocalBounds() geom.Rectangle {
@@ -263,8 +388,7 @@ func (p *ScrollPanel) Size() (geom.Point) {
 
 func (p *ScrollPanel) SetSize(size geom.Point) {
     Debugf(Layout, "[%T], %+v", p.Wrapper, size)
-	p.Embed.SetSize(size)
-	p.layout()
+	p.ContainerEmbed.SetSize(size)
 }
 
 func (p *ScrollPanel) MinSize() geom.Point {
@@ -290,14 +414,18 @@ func (p *ScrollPanel) VisibleRange() geom.Point {
 
 func (p *ScrollPanel) SetXView(vx float64) {
 	p.refPt.X = p.sizeDiff.X * vx
+	p.Mark(MarkNeedsPaint)
 }
 
 func (p *ScrollPanel) SetYView(vy float64) {
 	p.refPt.Y = p.sizeDiff.Y * vy
+	p.Mark(MarkNeedsPaint)
 }
 
+// Liefert die linke obere Ecke des sichtbaren Bereichs in virtuellen
+// Koordinaten.
 func (p *ScrollPanel) ViewPort() geom.Point {
-	return p.viewPort
+	return p.refPt
 }
 
 // Bestimmt die neue virtuelle Groesse des ScrolledPanels. Man kann bei
@@ -358,7 +486,7 @@ func NewTabMenu(content Container) *TabMenu {
 	m.data.Set(-1)
     m.content = content
 	m.contentList = make([]Node, 0)
-	m.data.AddCallback(func(d binding.DataItem) {
+	m.data.AddCallback(onUI(func(d binding.DataItem) {
 		idx := d.(binding.Int).Get()
 		if (idx < 0) || (idx >= len(m.contentList)) ||
 			(m.contentList[idx] == nil) {
@@ -366,17 +494,19 @@ func NewTabMenu(content Container) *TabMenu {
 		}
 		m.content.DelAll()
 		m.content.Add(m.contentList[idx])
-		m.content.layout()
-	})
+	}))
 	return m
 }
 
+func (m *TabMenu) OnThemeChanged() {
+	m.SetMinSize(geom.Point{X: m.Width(), Y: m.Height()})
+}
+
 func (m *TabMenu) AddTab(label string, content Node) (int) {
 	tabIndex := len(m.contentList)
 	m.contentList = append(m.contentList, content)
 	b := NewTabButtonWithData(label, tabIndex, m.data)
 	m.Add(b)
-	m.layout()
 	return tabIndex
 }
 
@@ -386,9 +516,18 @@ func (m *TabMenu) SetTab(idx int) {
 
 func (m *TabMenu) Paint(gc *gg.Context) {
 	Debugf(Painting, "[%T], LocalBounds: %v", m.Wrapper, m.LocalBounds())
+	m.paintShadow(gc, m.DropShadow(), m.LocalBounds(), 0.0)
 	gc.DrawRectangle(m.LocalBounds().AsCoord())
 	gc.SetFillColor(m.Color())
-    gc.Fill()
+	if img := m.BackgroundImage(); img != nil && img.Image != nil {
+		drawNineSlice(gc, img, m.LocalBounds())
+		gc.ClearPath()
+	} else {
+		if grad := m.FillGradient(); grad != nil {
+			gc.SetFillStyle(gradientPattern(gc, grad, m.LocalBounds()))
+		}
+		gc.Fill()
+	}
 //	gc.FillPreserve()
 //	gc.Clip()
 	m.ContainerEmbed.Paint(gc)
@@ -0,0 +1,162 @@
+package adagui
+
+import (
+	"image"
+	"math"
+
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+// Verwaltung der beschaedigten Bereiche eines Fensters. Wird ein Node mit
+// MarkNeedsPaint markiert, dann meldet er sich beim Fenster als beschaedigt.
+// Beim naechsten Bildaufbau ermittelt das Fenster aus allen beschaedigten
+// Nodes die Rechtecke (in Bildschirmkoordinaten), welche neu gezeichnet
+// werden muessen: pro Node ist dies der Bereich, den er beim letzten
+// Zeichnen belegt hat und der Bereich, den er aktuell belegt. Ueberlappende
+// Rechtecke werden vereinigt. Gezeichnet werden nur noch die Nodes, welche
+// eines dieser Rechtecke schneiden und dem Backend werden nur diese
+// Rechtecke uebermittelt.
+
+const (
+	// Um diesen Wert (in Pixel) werden die beschaedigten Bereiche
+	// vergroessert, damit auch Rahmen, Antialiasing und Linienenden, die
+	// ueber den Node hinausragen, neu gezeichnet werden.
+	damageMargin = 4.0
+
+	// Werden mehr Rechtecke als diese Anzahl ermittelt, dann werden sie
+	// zu einem einzigen Rechteck vereinigt.
+	maxDamageRects = 8
+)
+
+// Meldet den Node m beim Fenster als beschaedigt.
+func (w *Window) damageNode(m *Embed) {
+	w.damageMutex.Lock()
+	defer w.damageMutex.Unlock()
+	if w.damagedNodes == nil {
+		w.damagedNodes = make(map[*Embed]bool)
+	}
+	w.damagedNodes[m] = true
+	w.s.frames.wake()
+}
+
+// Markiert das ganze Fenster als beschaedigt, so dass es beim naechsten
+// Bildaufbau komplett neu gezeichnet wird.
+func (w *Window) damageAll() {
+	w.damageMutex.Lock()
+	defer w.damageMutex.Unlock()
+	w.damageFull = true
+	w.s.frames.wake()
+}
+
+// Liefert true, falls beim naechsten Bildaufbau etwas gezeichnet werden
+// muss. Wird im UI-Thread aufgerufen.
+func (w *Window) needsPaint() bool {
+	if w.root == nil {
+		return false
+	}
+	marks := w.root.Wrappee().Marks
+	if marks.NeedsPaint() || marks.NeedsLayout() {
+		return true
+	}
+	w.damageMutex.Lock()
+	defer w.damageMutex.Unlock()
+	return w.damageFull || len(w.damagedNodes) > 0
+}
+
+// Ermittelt aus den beschaedigten Nodes die Rechtecke, welche neu gezeichnet
+// werden muessen und setzt die Verwaltung zurueck. Muss unter dem Lock des
+// Fensters aufgerufen werden.
+func (w *Window) collectDamage() []image.Rectangle {
+	bounds := w.gc.Image().Bounds()
+
+	w.damageMutex.Lock()
+	nodes := w.damagedNodes
+	full := w.damageFull
+	w.damagedNodes = nil
+	w.damageFull = false
+	w.damageMutex.Unlock()
+
+	if full {
+		return []image.Rectangle{bounds}
+	}
+	rects := make([]image.Rectangle, 0, 2*len(nodes))
+	for m := range nodes {
+		rects = append(rects, damageRect(m.paintRect, bounds))
+		if m.Visible() && m.window() == w {
+			rects = append(rects, damageRect(m.screenRect(), bounds))
+		}
+	}
+	return mergeRects(rects)
+}
+
+// Prueft, ob das Rechteck r (in Bildschirmkoordinaten) einen der Bereiche
+// schneidet, welche aktuell neu gezeichnet werden.
+func (w *Window) isDamaged(r geom.Rectangle) bool {
+	for _, rect := range w.paintRects {
+		if geom.NewRectangleIMG(rect).Overlaps(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rechnet das Rechteck r in ein Rechteck mit ganzzahligen Koordinaten um,
+// vergroessert es um damageMargin und beschneidet es auf bounds.
+func damageRect(r geom.Rectangle, bounds image.Rectangle) image.Rectangle {
+	if r.Empty() {
+		return image.Rectangle{}
+	}
+	return image.Rect(
+		int(math.Floor(r.Min.X-damageMargin)),
+		int(math.Floor(r.Min.Y-damageMargin)),
+		int(math.Ceil(r.Max.X+damageMargin)),
+		int(math.Ceil(r.Max.Y+damageMargin))).Intersect(bounds)
+}
+
+// Vereinigt sich ueberlappende Rechtecke so lange, bis keine zwei Rechtecke
+// mehr ueberlappen. Leere Rechtecke werden entfernt. Bleiben mehr als
+// maxDamageRects uebrig, dann wird ein einziges, umschliessendes Rechteck
+// retourniert.
+func mergeRects(rects []image.Rectangle) []image.Rectangle {
+	res := make([]image.Rectangle, 0, len(rects))
+	for _, r := range rects {
+		if !r.Empty() {
+			res = append(res, r)
+		}
+	}
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(res); i++ {
+			for j := i + 1; j < len(res); j++ {
+				if !res[i].Overlaps(res[j]) {
+					continue
+				}
+				res[i] = res[i].Union(res[j])
+				res = append(res[:j], res[j+1:]...)
+				merged = true
+				j--
+			}
+		}
+	}
+	if len(res) > maxDamageRects {
+		union := image.Rectangle{}
+		for _, r := range res {
+			union = union.Union(r)
+		}
+		res = []image.Rectangle{union}
+	}
+	return res
+}
+
+// Liefert das achsenparallele Rechteck, welches das mit mat transformierte
+// Rechteck mit Ursprung (0,0) und Groesse size umschliesst.
+func boundingRect(mat *geom.Matrix, size geom.Point) geom.Rectangle {
+	p := mat.Transform(geom.Point{})
+	r := geom.Rectangle{Min: p, Max: p}
+	for _, pt := range []geom.Point{{X: size.X}, {Y: size.Y}, size} {
+		p = mat.Transform(pt)
+		r.Min = r.Min.Min(p)
+		r.Max = r.Max.Max(p)
+	}
+	return r
+}
@@ -0,0 +1,67 @@
+package adagui
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+func TestMergeRects(t *testing.T) {
+	rects := mergeRects([]image.Rectangle{
+		image.Rect(0, 0, 10, 10),
+		image.Rect(5, 5, 20, 20),
+		image.Rect(100, 100, 110, 110),
+		image.Rect(15, 15, 30, 30),
+		{},
+	})
+	want := []image.Rectangle{
+		image.Rect(0, 0, 30, 30),
+		image.Rect(100, 100, 110, 110),
+	}
+	if len(rects) != len(want) {
+		t.Fatalf("got %v, want %v", rects, want)
+	}
+	for i := range want {
+		if rects[i] != want[i] {
+			t.Errorf("rect %d: got %v, want %v", i, rects[i], want[i])
+		}
+	}
+}
+
+// Zu viele einzelne Rechtecke werden zu einem einzigen vereinigt.
+func TestMergeRectsLimit(t *testing.T) {
+	rects := make([]image.Rectangle, 0)
+	for i := 0; i <= maxDamageRects; i++ {
+		rects = append(rects, image.Rect(20*i, 0, 20*i+10, 10))
+	}
+	rects = mergeRects(rects)
+	want := image.Rect(0, 0, 20*maxDamageRects+10, 10)
+	if len(rects) != 1 || rects[0] != want {
+		t.Errorf("got %v, want [%v]", rects, want)
+	}
+}
+
+// Ein erneutes Layout mit unveraenderten Groessen und Positionen darf die
+// Kinder nicht zum Neuzeichnen markieren.
+func TestRelayoutKeepsChildrenClean(t *testing.T) {
+	grp := NewGroup()
+	grp.Layout = NewHBoxLayout(10)
+	b1 := NewButton(40, 20)
+	b2 := NewButton(60, 30)
+	grp.Add(b1, b2)
+	grp.SetSize(geom.Point{X: 200, Y: 50})
+	grp.UpdateLayout()
+	b1.Marks, b2.Marks = 0, 0
+
+	grp.InvalidateLayout()
+	grp.UpdateLayout()
+	if b1.Marks&MarkNeedsPaint != 0 || b2.Marks&MarkNeedsPaint != 0 {
+		t.Errorf("children marked for painting after an unchanged layout")
+	}
+	grp.SetSize(geom.Point{X: 200, Y: 60})
+	grp.UpdateLayout()
+	if b1.Marks&MarkNeedsPaint == 0 {
+		t.Errorf("child not marked after its size changed")
+	}
+}
@@ -0,0 +1,151 @@
+package adagui
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/stefan-muehlebach/adagui/binding"
+)
+
+// Der SceneGraph gehoert einer einzigen Go-Routine, dem UI-Thread des
+// Screens. Darin werden die Touch- und Tastatur-Ereignisse verarbeitet, die
+// Animationen weitergeschaltet und die Fenster gezeichnet. Aenderungen an
+// Nodes (SetPos, Add, SetValue, etc.) aus anderen Go-Routinen (Timer,
+// Netzwerk, Callbacks von Bind-Objekten) muessen mit Post oder PostSync an
+// den UI-Thread uebergeben werden:
+//
+//	go func() {
+//		val := readSensor()
+//		screen.Post(func() { slider.SetValue(val) })
+//	}()
+//
+// Die Methoden von Screen und Window koennen aus beliebigen Go-Routinen
+// aufgerufen werden, sie uebergeben ihre Arbeit selber an den UI-Thread
+// (siehe invoke). Interne Funktionen, welche bereits im UI-Thread laufen,
+// verwenden dagegen die entsprechenden Funktionen ohne Uebergabe (bspw.
+// repaint statt Repaint).
+type dispatcher struct {
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	queue  []func()
+	closed bool
+	busy   atomic.Bool
+}
+
+func (d *dispatcher) init() {
+	d.cond = sync.NewCond(&d.mutex)
+}
+
+// Haengt fn an die Warteschlange an. Retourniert false, falls der
+// UI-Thread bereits beendet wurde.
+func (d *dispatcher) post(fn func()) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.closed {
+		return false
+	}
+	d.queue = append(d.queue, fn)
+	d.cond.Signal()
+	return true
+}
+
+// Beendet den UI-Thread, sobald alle bereits uebergebenen Funktionen
+// ausgefuehrt sind.
+func (d *dispatcher) close() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.closed = true
+	d.cond.Signal()
+}
+
+// Fuehrt mit Post uebergebene Funktionen in der Reihenfolge aus, in welcher
+// sie uebergeben wurden.
+func (d *dispatcher) run() {
+	for {
+		d.mutex.Lock()
+		for len(d.queue) == 0 && !d.closed {
+			d.cond.Wait()
+		}
+		if len(d.queue) == 0 {
+			d.mutex.Unlock()
+			return
+		}
+		queue := d.queue
+		d.queue = nil
+		d.mutex.Unlock()
+		for _, fn := range queue {
+			d.exec(fn)
+		}
+	}
+}
+
+// Alle Funktionen werden vom UI-Thread ueber exec ausgefuehrt. Solange
+// eine davon laeuft, ist busy gesetzt.
+func (d *dispatcher) exec(fn func()) {
+	d.busy.Store(true)
+	defer d.busy.Store(false)
+	fn()
+}
+
+// Mit Post wird die Funktion fn an den UI-Thread uebergeben und dort nach
+// allen bereits wartenden Funktionen ausgefuehrt. Die Methode kehrt sofort
+// zurueck. Nach dem Beenden des Screens wird fn nicht mehr ausgefuehrt.
+func (s *Screen) Post(fn func()) {
+	s.ui.post(fn)
+}
+
+// Wie Post, wartet jedoch, bis fn ausgefuehrt wurde. PostSync darf nicht
+// im UI-Thread selber aufgerufen werden (bspw. aus einem Event-Handler oder
+// einer mit Post uebergebenen Funktion), da dieser sonst auf sich selber
+// warten wuerde; dort kann direkt gearbeitet werden. Ist der UI-Thread
+// bereits beendet, wird fn in der aufrufenden Go-Routine ausgefuehrt.
+func (s *Screen) PostSync(fn func()) {
+	done := make(chan bool)
+	if !s.ui.post(func() {
+		defer close(done)
+		fn()
+	}) {
+		fn()
+		return
+	}
+	<-done
+}
+
+// Mit invoke uebergeben die Methoden von Screen und Window ihre Arbeit an
+// den UI-Thread. Ist dieser untaetig, wird wie bei PostSync gewartet, bis
+// fn ausgefuehrt wurde. Fuehrt der UI-Thread dagegen gerade eine Funktion
+// aus, wird fn nur angehaengt und invoke kehrt sofort zurueck. Damit
+// koennen diese Methoden auch aus Event-Handlern und Callbacks aufgerufen
+// werden, ohne dass der UI-Thread auf sich selber wartet; fn wird in
+// diesem Fall direkt nach dem Handler ausgefuehrt.
+func (s *Screen) invoke(fn func()) {
+	if s.ui.busy.Load() {
+		s.Post(fn)
+		return
+	}
+	s.PostSync(fn)
+}
+
+// Mit onUI wird die Callback-Funktion fn fuer ein Bind-Objekt so verpackt,
+// dass sie bei Aenderungen des Wertes im UI-Thread ausgefuehrt wird. Der
+// erste Aufruf erfolgt durch AddCallback beim Erzeugen des Widgets und damit
+// in der Go-Routine, welcher das Widget zu diesem Zeitpunkt noch gehoert;
+// er wird direkt ausgefuehrt. Da die Listener in eigenen Go-Routinen
+// laufen koennen, wird dies atomar festgehalten. Werden die Listener des
+// Bind-Objektes synchron aufgerufen (binding.DispatchSync), wird fn wie die
+// Methoden von Screen mit invoke uebergeben.
+func onUI(fn binding.CallbackFunc) binding.CallbackFunc {
+	var called atomic.Bool
+	return func(data binding.DataItem) {
+		s := CurrentScreen()
+		if !called.Swap(true) || s == nil {
+			fn(data)
+			return
+		}
+		if binding.DispatchModeOf(data) == binding.DispatchSync {
+			s.invoke(func() { fn(data) })
+			return
+		}
+		s.Post(func() { fn(data) })
+	}
+}
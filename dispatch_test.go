@@ -0,0 +1,117 @@
+package adagui_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+	"github.com/stefan-muehlebach/adagui/binding"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+func TestPostOrder(t *testing.T) {
+	s, _ := adaguitest.Screen()
+	var list []int
+	for i := 0; i < 10; i++ {
+		s.Post(func() { list = append(list, i) })
+	}
+	s.PostSync(func() {})
+	if len(list) != 10 {
+		t.Fatalf("got %d executed functions, want 10", len(list))
+	}
+	for i, v := range list {
+		if v != i {
+			t.Errorf("function %d executed at position %d", v, i)
+		}
+	}
+}
+
+// Die Methoden von Screen und Window koennen auch im UI-Thread (bspw. aus
+// einem Event-Handler) aufgerufen werden, ohne dass dieser auf sich selber
+// wartet. Ihre Arbeit wird direkt danach ausgefuehrt.
+func TestScreenMethodsOnUIThread(t *testing.T) {
+	h := adaguitest.New(t, adagui.NewGroup())
+	dlg := h.Screen.NewWindow()
+	t.Cleanup(dlg.Close)
+	btn := adagui.NewButton(60, 40)
+
+	done := make(chan bool)
+	h.Screen.Post(func() {
+		dlg.SetRoot(btn)
+		h.Screen.PushWindow(dlg, adagui.WindowModal)
+		dlg.SetFocus(btn)
+		h.Screen.Repaint()
+		close(done)
+	})
+	select {
+	case <-done:
+	case <-time.After(adaguitest.IdleTimeout):
+		t.Fatalf("UI thread blocked while calling screen methods")
+	}
+	h.WaitIdle()
+	var root, focus adagui.Node
+	h.Do(func() {
+		root = dlg.Root()
+		focus = dlg.Focus()
+	})
+	if root != btn || focus != btn {
+		t.Errorf("changes made on the UI thread were not applied")
+	}
+	if h.Screen.PopWindow() != dlg {
+		t.Errorf("dialog was not pushed onto the window stack")
+	}
+}
+
+func TestBackgroundUpdate(t *testing.T) {
+	sld := adagui.NewSlider(200, adagui.Horizontal)
+	data := binding.NewInt()
+	data.SetDispatchMode(binding.DispatchAsync)
+	rb1 := adagui.NewRadioButtonWithData("Eins", 1, data)
+	rb2 := adagui.NewRadioButtonWithData("Zwei", 2, data)
+	grp := adagui.NewGroup()
+	grp.Add(sld, rb1, rb2)
+	sld.SetPos(geom.Point{X: 20, Y: 40})
+	rb1.SetPos(geom.Point{X: 20, Y: 120})
+	rb2.SetPos(geom.Point{X: 20, Y: 160})
+	h := adaguitest.New(t, grp)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				h.Screen.Post(func() {
+					sld.SetValue(float64(j) / 20.0)
+				})
+				data.Set(j%2 + 1)
+			}
+		}()
+	}
+	wg.Wait()
+	h.Screen.Post(func() { sld.SetValue(0.5) })
+	data.Set(2)
+	h.WaitIdle()
+	got := h.Image()
+
+	if v := sld.Value(); v != 0.5 {
+		t.Errorf("slider value is %v, want 0.5", v)
+	}
+
+	// Die gleichen Werte, ohne konkurrierende Go-Routinen gesetzt, muessen
+	// zum gleichen Bild fuehren.
+	data.Set(1)
+	h.WaitIdle()
+	data.Set(2)
+	h.WaitIdle()
+	numDiff, _, err := adaguitest.Compare(got, h.Image(),
+		adaguitest.DefaultTolerance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numDiff > 0 {
+		t.Errorf("%d pixels differ after concurrent updates", numDiff)
+	}
+}
@@ -2,7 +2,6 @@ package adagui
 
 import (
     "container/list"
-    "log"
     "github.com/stefan-muehlebach/adagui/touch"
     "github.com/stefan-muehlebach/adagui/props"
     "github.com/stefan-muehlebach/gg"
@@ -20,9 +19,12 @@ type Embed struct {
     pos, size, minSize geom.Point
     transl, rotate, scale, transf *geom.Matrix
     Marks Marks
+    paintRect geom.Rectangle
     visible bool
     enabled bool
     selectable bool
+    focusable bool
+    layingOut bool
     props.PropertyEmbed
 }
 
@@ -40,93 +42,156 @@ func (m *Embed) Wrappee() (*Embed) {
     return m
 }
 
+// Liefert true, falls der Node zuvorderst, d.h. als letztes Kind seines
+// Containers gezeichnet wird. Fuer Nodes ohne Container wird false
+// retourniert.
 func (m *Embed) IsAtFront() bool {
     var e *list.Element
 
     if m.Parent == nil {
-        log.Fatal("node: This child is not attached")
+        return false
     }
     p := m.Parent
     e = p.ChildList.Back()
     return e.Value.(*Embed) == m
 }
 
-func (m *Embed) ToBack() {
-    var e *list.Element
-
-    if m.Parent == nil {
-        log.Fatal("node: This child is not attached")
+// Mit ToBack, resp. ToFront wird der Node innerhalb seines Containers
+// zuhinterst, resp. zuvorderst platziert. Ist der Node keinem Container
+// hinzugefuegt, wird ErrNotAttached retourniert.
+func (m *Embed) ToBack() (error) {
+    e, err := m.element()
+    if err != nil {
+        return err
     }
-    p := m.Parent
-    for e = p.ChildList.Front(); e != nil; e = e.Next() {
-        if e.Value.(*Embed) == m {
-            break
-        }
-    }
-    if e == nil {
-        return
-    }
-    p.ChildList.MoveToFront(e)
+    m.Parent.ChildList.MoveToFront(e)
+    return nil
 }
 
-func (m *Embed) ToFront() {
-    var e *list.Element
+func (m *Embed) ToFront() (error) {
+    e, err := m.element()
+    if err != nil {
+        return err
+    }
+    m.Parent.ChildList.MoveToBack(e)
+    return nil
+}
 
-    if m.Parent == nil {
-        log.Fatal("node: This child is not attached")
+// Entfernt den Node aus seinem Container. Ist der Node keinem Container
+// hinzugefuegt, wird ErrNotAttached retourniert.
+func (m *Embed) Remove() (error) {
+    e, err := m.element()
+    if err != nil {
+        return err
     }
     p := m.Parent
-    for e = p.ChildList.Front(); e != nil; e = e.Next() {
-        if e.Value.(*Embed) == m {
-            break
-        }
-    }
-    if e == nil {
-        return
-    }
-    p.ChildList.MoveToBack(e)
+    m.damage()
+    m.Win = nil
+    m.Parent = nil
+    p.ChildList.Remove(e)
+    p.InvalidateLayout()
+    return nil
 }
 
-func (m *Embed) Remove() {
-    var e *list.Element
-
+// Sucht den Eintrag des Nodes in der Liste der Kinder seines Containers.
+func (m *Embed) element() (*list.Element, error) {
     if m.Parent == nil {
-        log.Fatal("node: This child is not attached")
+        return nil, ErrNotAttached
     }
-    p := m.Parent
-    for e = p.ChildList.Front(); e != nil; e = e.Next() {
+    for e := m.Parent.ChildList.Front(); e != nil; e = e.Next() {
         if e.Value.(*Embed) == m {
-            break
+            return e, nil
         }
     }
-    if e == nil {
-        return
-    }
-    m.Win = nil
-    p.ChildList.Remove(e)
+    return nil, ErrNotAttached
 }
 
+// Position und Groesse eines Nodes werden vom Layout seines Containers
+// bestimmt. Dieses wird vor jedem Bildaufbau im UI-Thread berechnet (siehe
+// ContainerEmbed.UpdateLayout); dazwischen koennen die Werte veraltet sein.
 func (m *Embed) Pos() (geom.Point) {
     return m.pos
 }
 func (m *Embed) SetPos(p geom.Point) {
+    changed := !p.Eq(m.pos)
+    if !changed && *m.transl == *geom.Translate(p) {
+        return
+    }
     m.pos = p
     m.Translate(p)
+    // Beim NullLayout haengt die minimale Groesse des Containers von den
+    // Positionen der Kinder ab.
+    if changed && m.Parent != nil {
+        if _, ok := m.Parent.Layout.(*NullLayout); ok {
+            m.Parent.InvalidateMeasure()
+        }
+    }
 }
 func (m *Embed) Size() (geom.Point) {
     return m.size.Max(m.Wrapper.MinSize())
 }
+// Das Layout setzt Position und Groesse aller Kinder bei jedem Durchgang;
+// neu gezeichnet wird ein Node nur, wenn sich diese auch aendern.
 func (m *Embed) SetSize(size geom.Point) {
     Debugf(Layout, "[%T], %+v", m.Wrapper, size)
+    if size.Eq(m.size) {
+        return
+    }
     m.size = size
-    //m.Mark(MarkNeedsPaint)
+    m.Mark(MarkNeedsPaint)
 }
 func (m *Embed) MinSize() (geom.Point) {
     return m.minSize
 }
 func (m *Embed) SetMinSize(s geom.Point) {
+    changed := !s.Eq(m.minSize)
     m.minSize = s
-    //m.Mark(MarkNeedsPaint)
+    if changed {
+        m.InvalidateMeasure()
+    }
+    m.Mark(MarkNeedsPaint)
+}
+
+// Mit InvalidateMeasure meldet ein Node, dass sich seine minimale Groesse
+// (siehe MinSize) geaendert hat. Widgets, welche MinSize selber berechnen,
+// muessen diese Methode nach jeder Aenderung aufrufen; SetMinSize macht dies
+// bereits. Die zwischengespeicherten minimalen Groessen der Container
+// werden verworfen und die Container werden fuer das naechste Layout
+// markiert, welches vor dem naechsten Bildaufbau durchgefuehrt wird.
+func (m *Embed) InvalidateMeasure() {
+    if c := asContainer(m.Wrapper); c != nil {
+        c.measured = false
+    }
+    for p := m.Parent; p != nil; p = p.Parent {
+        p.measured = false
+        // Container mit fixer minimaler Groesse haengen nicht von ihren
+        // Kindern ab.
+        if !p.minSize.Eq(geom.Point{}) {
+            break
+        }
+    }
+    m.Mark(MarkNeedsMeasure)
+}
+
+// Fuehrt fuer alle markierten Container im Teilbaum von m das Layout durch
+// (von oben nach unten) und entfernt die Markierungen MarkNeedsMeasure und
+// MarkNeedsLayout. Die Markierungen bleiben bis zum Schluss gesetzt, damit
+// Aenderungen an den Kindern waehrend des Layouts nicht erneut nach oben
+// gemeldet werden.
+func (m *Embed) updateLayout() {
+    if m.Marks & layoutMarks == 0 {
+        return
+    }
+    m.layingOut = true
+    if c := asContainer(m.Wrapper); c != nil {
+        Debugf(Layout, "[%T]", m.Wrapper)
+        c.layout()
+        for e := c.ChildList.Front(); e != nil; e = e.Next() {
+            e.Value.(*Embed).updateLayout()
+        }
+    }
+    m.Marks &^= layoutMarks
+    m.layingOut = false
 }
 
 func (m *Embed) LocalBounds() (geom.Rectangle) {
@@ -147,17 +212,86 @@ func (m *Embed) Visible() (bool) {
     return m.visible
 }
 func (m *Embed) SetVisible(v bool) {
+    if m.visible == v {
+        return
+    }
     m.visible = v
+    m.InvalidateMeasure()
+    m.Mark(MarkNeedsPaint)
 }
 
+// Ein Node ist nur dann aktiv, wenn er selber und alle seine Container
+// aktiv sind. Inaktive Nodes (inkl. ihrer Kinder) erhalten keine Touch-
+// oder Tastatur-Ereignisse und werden mit den Disabled*-Properties
+// gezeichnet.
 func (m *Embed) Enabled() (bool) {
-    return m.enabled
+    if !m.enabled {
+        return false
+    }
+    for p := m.Parent; p != nil; p = p.Parent {
+        if !p.enabled {
+            return false
+        }
+    }
+    return true
 }
+
+// Mit SetEnabled wird der Node (inkl. aller Kinder) aktiviert, resp.
+// deaktiviert. Beim Deaktivieren werden gedrueckte Widgets losgelassen.
 func (m *Embed) SetEnabled(e bool) {
+    if m.enabled == e {
+        return
+    }
     m.enabled = e
+    if !e {
+        releaseTree(m.Wrapper)
+    }
+    m.Mark(MarkNeedsPaint)
+}
+
+// Setzt den Status aller gedrueckten Widgets unterhalb von n zurueck.
+func releaseTree(n Node) {
+    if p, ok := n.(interface{ release() }); ok {
+        p.release()
+    }
+    if c := asContainer(n); c != nil {
+        for e := c.ChildList.Front(); e != nil; e = e.Next() {
+            releaseTree(e.Value.(*Embed).Wrapper)
+        }
+    }
+}
+
+// Nur Nodes, welche fokussierbar sind, koennen mit der Tastatur angewaehlt
+// werden (siehe Window.SetFocus). Per Default sind dies alle Widgets, welche
+// sich druecken lassen (Buttons, Checkboxen, Slider, etc.).
+func (m *Embed) Focusable() (bool) {
+    return m.focusable
+}
+func (m *Embed) SetFocusable(f bool) {
+    m.focusable = f
+}
+
+// Liefert true, falls der Node in seinem Fenster den Tastatur-Fokus hat.
+func (m *Embed) Focused() (bool) {
+    w := m.window()
+    return w != nil && w.focus != nil && w.focus.Wrappee() == m
 }
 
+// Setzt die Markierungen marks und meldet sie an den Parent-Node weiter.
+// Mit MarkNeedsPaint meldet sich der Node zudem beim Fenster als
+// beschaedigt, damit sein Bereich beim naechsten Bildaufbau neu gezeichnet
+// wird.
 func (m *Embed) Mark(marks Marks) {
+    if marks.NeedsPaint() {
+        m.damage()
+    }
+    m.propagateMarks(marks)
+}
+
+// Wie Mark, jedoch ohne den Node als beschaedigt zu melden. Wird von
+// Containern verwendet, um Markierungen ihrer Kinder nach oben
+// weiterzureichen.
+func (m *Embed) propagateMarks(marks Marks) {
     oldMarks := m.Marks
     m.Marks |= marks
     changedMarks := m.Marks ^ oldMarks
@@ -167,13 +301,86 @@ func (m *Embed) Mark(marks Marks) {
     }
 }
 
+// Zeichnet den Node. Wird in das Fenster gezeichnet, zu welchem der Node
+// gehoert, dann wird der belegte Bereich fuer die Ermittlung der
+// beschaedigten Bereiche festgehalten und Nodes, welche keinen der neu zu
+// zeichnenden Bereiche schneiden, werden uebersprungen.
 func (m *Embed) Paint(gc *gg.Context) {
     Debugf(Painting, "[%T]", m.Wrapper)
     m.Marks.UnmarkNeedsPaint()
     gc.Push()
+    defer gc.Pop()
     gc.Multiply(m.Matrix())
+    if w := m.window(); w != nil && w.gc == gc {
+        rect := boundingRect(gc.Matrix(), m.Wrapper.Size())
+        // Schatten und Fokus-Rahmen liegen ausserhalb des Nodes, daher wird
+        // auch der zuletzt gezeichnete Bereich geprueft.
+        dmg := rect.Union(m.paintRect).Inset(-damageMargin, -damageMargin)
+        if !w.isDamaged(dmg) {
+            return
+        }
+        m.paintRect = rect
+        // Reicht der gezeichnete Bereich neu ueber den beschaedigten Bereich
+        // hinaus (bspw. durch einen neuen Schatten), wird der Node im
+        // naechsten Bildaufbau vollstaendig neu gezeichnet.
+        defer func() {
+            if !m.paintRect.In(dmg) {
+                m.damage()
+            }
+        }()
+    }
     m.Wrapper.Paint(gc)
-    gc.Pop()
+    if m.Focused() {
+        m.paintFocusRing(gc)
+    }
+}
+
+// Zeichnet um den Node herum einen Rahmen, welcher anzeigt, dass der Node
+// den Tastatur-Fokus hat. Farbe und Breite stammen aus den Properties
+// FocusColor und FocusWidth. Da der Rahmen ausserhalb des Nodes liegt, wird
+// der gezeichnete Bereich entsprechend vergroessert.
+func (m *Embed) paintFocusRing(gc *gg.Context) {
+    fw := m.FocusWidth()
+    if fw <= 0 {
+        return
+    }
+    r := geom.Rectangle{Max: m.Wrapper.Size()}.Inset(-fw, -fw)
+    gc.DrawRoundedRectangle(r.Min.X, r.Min.Y, r.Dx(), r.Dy(),
+        m.CornerRadius()+fw)
+    gc.SetStrokeColor(m.FocusColor())
+    gc.SetStrokeWidth(fw)
+    gc.Stroke()
+    if w := m.window(); w != nil && w.gc == gc {
+        m.paintRect = m.paintRect.Inset(-1.5*fw, -1.5*fw)
+    }
+}
+
+// Meldet den Node beim Fenster als beschaedigt.
+func (m *Embed) damage() {
+    if w := m.window(); w != nil {
+        w.damageNode(m)
+    }
+}
+
+// Liefert das Fenster, in welchem der Node dargestellt wird oder nil, falls
+// der Node an kein Fenster angehaengt ist.
+func (m *Embed) window() (*Window) {
+    for m.Parent != nil {
+        m = &m.Parent.Embed
+    }
+    return m.Win
+}
+
+// Liefert den Bereich in Bildschirmkoordinaten, welchen der Node aktuell
+// belegt. Dabei werden die Transformationen aller Parent-Nodes
+// beruecksichtigt.
+func (m *Embed) screenRect() (geom.Rectangle) {
+    mat := m.Matrix()
+    for p := m.Parent; p != nil; p = p.Parent {
+        off := p.Wrapper.LocalBounds().Min
+        mat = p.Matrix().Multiply(geom.Translate(off.Neg()).Multiply(mat))
+    }
+    return boundingRect(mat, m.Wrapper.Size())
 }
 
 // Contains ermittelt, ob sich der Punkt pt innerhalb des Widgets befindet.
@@ -278,7 +485,7 @@ func (m *LeafEmbed) OnChildMarked(child Node, newMarks Marks) {}
 
 func (m *LeafEmbed) SelectTarget(pt geom.Point) (Node) {
     Debugf(Coordinates, "[%T], pt: %v", m.Wrapper, pt)
-    if !m.Visible() || !m.selectable {
+    if !m.Visible() || !m.enabled || !m.selectable {
         return nil
     }
     if !m.Wrapper.Contains(pt) {
@@ -327,6 +534,10 @@ const (
     MarkNeedsRecalc  = Marks(1 << 3)
 )
 
+// Diese Markierungen werden durch das Layout (siehe Embed.updateLayout)
+// wieder entfernt.
+const layoutMarks = MarkNeedsMeasure | MarkNeedsLayout
+
 func (m Marks)  NeedsMeasure() (bool) { return m & MarkNeedsMeasure != 0 }
 func (m Marks)  NeedsLayout() (bool)  { return m & MarkNeedsLayout  != 0 }
 func (m Marks)  NeedsPaint() (bool)   { return m & MarkNeedsPaint   != 0 }
@@ -0,0 +1,32 @@
+package adagui
+
+import (
+	"errors"
+)
+
+// Diese Fehler werden von den Funktionen und Methoden von AdaGui
+// retourniert, ggf. mit weiteren Angaben versehen. Mit errors.Is kann
+// geprueft werden, um welchen Fehler es sich handelt.
+var (
+	// Der Node wurde keinem Container hinzugefuegt (siehe ToBack, ToFront
+	// und Remove).
+	ErrNotAttached = errors.New("adagui: node is not attached")
+	// Der Node wurde bereits einem Container hinzugefuegt (siehe
+	// ContainerEmbed.Add).
+	ErrAlreadyAttached = errors.New("adagui: node is already attached")
+	// In der Applikation gibt es bereits einen Screen (siehe NewScreen).
+	ErrScreenExists = errors.New("adagui: there is already a screen in this application")
+	// Das in ScreenOptions gewaehlte Backend ist unbekannt.
+	ErrUnknownBackend = errors.New("adagui: unknown backend")
+	// Es wird kein Fenster angezeigt (siehe Screen.SaveScreenshot).
+	ErrNoWindow = errors.New("adagui: no active window")
+	// Es laeuft bereits eine Filmaufnahme (siehe Screen.StartMovie).
+	ErrMovieRunning = errors.New("adagui: movie recording already running")
+	// Es laeuft keine Filmaufnahme (siehe Screen.StopMovie).
+	ErrNoMovie = errors.New("adagui: no movie recording running")
+	// Die maximale Dauer oder Groesse einer Filmaufnahme wurde erreicht.
+	ErrMovieLimit = errors.New("adagui: movie recording limit reached")
+	// Die Helligkeit der Hintergrundbeleuchtung kann nicht veraendert
+	// werden (siehe Backend.SetBacklight).
+	ErrNoBacklight = errors.New("adagui: backlight not available")
+)
@@ -0,0 +1,80 @@
+package adagui_test
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+)
+
+func TestNotAttached(t *testing.T) {
+	btn := adagui.NewButton(60, 40)
+	if err := btn.Remove(); !errors.Is(err, adagui.ErrNotAttached) {
+		t.Errorf("Remove: got %v, want ErrNotAttached", err)
+	}
+	if err := btn.ToBack(); !errors.Is(err, adagui.ErrNotAttached) {
+		t.Errorf("ToBack: got %v, want ErrNotAttached", err)
+	}
+	if err := btn.ToFront(); !errors.Is(err, adagui.ErrNotAttached) {
+		t.Errorf("ToFront: got %v, want ErrNotAttached", err)
+	}
+	if btn.IsAtFront() {
+		t.Errorf("detached node reported at front")
+	}
+}
+
+func TestAddAttached(t *testing.T) {
+	a, b := adagui.NewButton(60, 40), adagui.NewButton(60, 40)
+	grp1, grp2 := adagui.NewGroup(), adagui.NewGroup()
+	if err := grp1.Add(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := grp2.Add(a, b); !errors.Is(err, adagui.ErrAlreadyAttached) {
+		t.Errorf("got %v, want ErrAlreadyAttached", err)
+	}
+	if a.Wrappee().Parent != &grp1.ContainerEmbed {
+		t.Errorf("attached node moved to another container")
+	}
+	if b.Wrappee().Parent != &grp2.ContainerEmbed {
+		t.Errorf("remaining node not added")
+	}
+	if err := b.ToBack(); err != nil {
+		t.Errorf("ToBack: %v", err)
+	}
+	if err := b.Remove(); err != nil {
+		t.Errorf("Remove: %v", err)
+	}
+}
+
+func TestScreenExists(t *testing.T) {
+	adaguitest.Screen()
+	_, err := adagui.NewScreenWithBackend(adagui.NewMemBackend(10, 10))
+	if !errors.Is(err, adagui.ErrScreenExists) {
+		t.Errorf("got %v, want ErrScreenExists", err)
+	}
+}
+
+func TestIconFallback(t *testing.T) {
+	btn := adagui.NewIconButton("testdata/NoSuchIcon.png")
+	if btn.MinSize().X <= 0 || btn.MinSize().Y <= 0 {
+		t.Errorf("icon button without size: %v", btn.MinSize())
+	}
+	if err := btn.SetIcon("testdata/NoSuchIcon.png"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestSaveScreenshot(t *testing.T) {
+	h := adaguitest.New(t, adagui.NewButton(60, 40))
+	dir := t.TempDir()
+	if err := h.Window.SaveScreenshot(filepath.Join(dir, "shot.png")); err != nil {
+		t.Errorf("SaveScreenshot: %v", err)
+	}
+	err := h.Window.SaveScreenshot(filepath.Join(dir, "missing", "shot.png"))
+	if err == nil {
+		t.Errorf("SaveScreenshot into a missing directory without error")
+	}
+}
@@ -0,0 +1,229 @@
+package adagui
+
+import (
+	"math"
+	"time"
+
+	"github.com/stefan-muehlebach/adagui/key"
+	"github.com/stefan-muehlebach/adagui/touch"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+// Liefert den Node, welcher in diesem Fenster den Tastatur-Fokus hat oder
+// nil, falls kein Node fokussiert ist. Ein inaktiver Node (siehe
+// Embed.Enabled) hat keinen Fokus. Muss wie die Methoden der Nodes im
+// UI-Thread aufgerufen werden.
+func (w *Window) Focus() Node {
+	return w.focused()
+}
+
+func (w *Window) focused() Node {
+	if w.focus == nil || w.focus.Wrappee().window() != w ||
+		!w.focus.Wrappee().Enabled() {
+		return nil
+	}
+	return w.focus
+}
+
+// Gibt dem Node n den Tastatur-Fokus. Der bisher fokussierte Node verliert
+// ihn und beide werden neu gezeichnet. Mit nil wird der Fokus entfernt.
+func (w *Window) SetFocus(n Node) {
+	w.s.invoke(func() { w.setFocus(n) })
+}
+
+func (w *Window) setFocus(n Node) {
+	old := w.focused()
+	if old == n {
+		return
+	}
+	w.focus = n
+	if old != nil {
+		old.Mark(MarkNeedsPaint)
+	}
+	if n != nil {
+		n.Mark(MarkNeedsPaint)
+	}
+}
+
+// Verschiebt den Fokus zum naechsten, resp. vorherigen fokussierbaren Node
+// (in der Reihenfolge, in welcher die Nodes dem SceneGraph hinzugefuegt
+// wurden). Nach dem letzten Node folgt wieder der erste.
+func (w *Window) FocusNext() {
+	w.s.invoke(func() { w.stepFocus(+1) })
+}
+
+func (w *Window) FocusPrev() {
+	w.s.invoke(func() { w.stepFocus(-1) })
+}
+
+// Mit SetKeyFunc kann eine Funktion hinterlegt werden, welche alle
+// Tastatur-Ereignisse erhaelt, die weder vom fokussierten Node noch fuer
+// die Navigation zwischen den Nodes verwendet wurden (bspw. Esc fuer einen
+// Zurueck-Knopf).
+func (w *Window) SetKeyFunc(fn key.KeyFunction) {
+	w.s.invoke(func() { w.keyFunc = fn })
+}
+
+func (w *Window) stepFocus(dir int) {
+	nodes := w.focusables()
+	if len(nodes) == 0 {
+		return
+	}
+	idx := -1
+	focus := w.focused()
+	for i, n := range nodes {
+		if n == focus {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		if dir > 0 {
+			idx = len(nodes) - 1
+		} else {
+			idx = 0
+		}
+	}
+	idx = (idx + dir + len(nodes)) % len(nodes)
+	w.setFocus(nodes[idx])
+}
+
+// Verschiebt den Fokus zum naechstgelegenen fokussierbaren Node in der
+// Richtung dir. Massgebend sind die Mittelpunkte der Nodes in
+// Bildschirmkoordinaten, wobei Abweichungen quer zur Richtung doppelt
+// gewichtet werden.
+func (w *Window) moveFocus(dir geom.Point) {
+	nodes := w.focusables()
+	if len(nodes) == 0 {
+		return
+	}
+	focus := w.focused()
+	if focus == nil {
+		w.setFocus(nodes[0])
+		return
+	}
+	c0 := focus.Wrappee().screenRect().Center()
+	var best Node
+	bestScore := math.Inf(1)
+	for _, n := range nodes {
+		if n == focus {
+			continue
+		}
+		d := n.Wrappee().screenRect().Center().Sub(c0)
+		along := d.X*dir.X + d.Y*dir.Y
+		if along <= 0 {
+			continue
+		}
+		across := math.Abs(d.X*dir.Y - d.Y*dir.X)
+		if score := along + 2*across; score < bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best != nil {
+		w.setFocus(best)
+	}
+}
+
+// Liefert alle sichtbaren und aktiven Nodes des Fensters, welche den Fokus
+// erhalten koennen.
+func (w *Window) focusables() []Node {
+	var nodes []Node
+	var walk func(n Node)
+	walk = func(n Node) {
+		m := n.Wrappee()
+		if !m.Visible() || !m.Enabled() {
+			return
+		}
+		if m.Focusable() {
+			nodes = append(nodes, n)
+		}
+		if c, ok := n.(interface{ containerEmbed() *ContainerEmbed }); ok {
+			for e := c.containerEmbed().ChildList.Front(); e != nil; e = e.Next() {
+				walk(e.Value.(*Embed).Wrapper)
+			}
+		}
+	}
+	if w.root != nil {
+		walk(w.root)
+	}
+	return nodes
+}
+
+// Verarbeitet ein Tastatur-Ereignis. Zuerst erhaelt der fokussierte Node
+// das Ereignis, sofern er KeyHandler implementiert. Anschliessend wird mit
+// Tab, Shift-Tab und den Pfeiltasten der Fokus verschoben und mit Enter
+// oder der Leertaste der fokussierte Node betaetigt. Alle uebrigen
+// Ereignisse gehen an die Funktion, welche mit SetKeyFunc hinterlegt wurde.
+// Wird im UI-Thread aufgerufen.
+func (w *Window) handleKey(evt key.Event) {
+	if !w.dispatchKey(evt) && w.keyFunc != nil {
+		w.keyFunc(evt)
+	}
+}
+
+func (w *Window) dispatchKey(evt key.Event) bool {
+	focus := w.focused()
+	if h, ok := focus.(KeyHandler); ok && h.OnKeyEvent(evt) {
+		return true
+	}
+	var dir geom.Point
+	switch evt.Code {
+	case key.CodeTab:
+		if evt.Down() {
+			if evt.Mod&key.ModShift != 0 {
+				w.stepFocus(-1)
+			} else {
+				w.stepFocus(+1)
+			}
+		}
+		return true
+	case key.CodeEnter, key.CodeKPEnter, key.CodeSpace:
+		if focus == nil {
+			return false
+		}
+		w.activate(focus, evt)
+		return true
+	case key.CodeUp:
+		dir = geom.Point{X: 0, Y: -1}
+	case key.CodeDown:
+		dir = geom.Point{X: 0, Y: 1}
+	case key.CodeLeft:
+		dir = geom.Point{X: -1, Y: 0}
+	case key.CodeRight:
+		dir = geom.Point{X: 1, Y: 0}
+	default:
+		return false
+	}
+	if len(w.focusables()) == 0 {
+		return false
+	}
+	if evt.Down() {
+		w.moveFocus(dir)
+	}
+	return true
+}
+
+// Betaetigt den Node n so, als waere er in seiner Mitte angetippt worden:
+// beim Druecken der Taste erhaelt er ein Press-, beim Loslassen ein
+// Release- und ein Tap-Event.
+func (w *Window) activate(n Node, evt key.Event) {
+	pos := n.Screen2Local(n.Wrappee().screenRect().Center())
+	tchEvt := touch.Event{
+		Pos:      pos,
+		InitPos:  pos,
+		Time:     time.Now(),
+		InitTime: w.keyPressTime,
+	}
+	switch evt.Action {
+	case key.Press:
+		w.keyPressTime = tchEvt.Time
+		tchEvt.Type = touch.TypePress
+		tchEvt.InitTime = tchEvt.Time
+		n.OnInputEvent(tchEvt)
+	case key.Release:
+		tchEvt.Type = touch.TypeRelease
+		n.OnInputEvent(tchEvt)
+		tchEvt.Type = touch.TypeTap
+		n.OnInputEvent(tchEvt)
+	}
+}
@@ -0,0 +1,136 @@
+package adagui_test
+
+import (
+	"testing"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+	"github.com/stefan-muehlebach/adagui/key"
+)
+
+// Baut ein Gitter mit 2x2 Buttons auf.
+func buttonGrid() (*adagui.Group, []*adagui.TextButton) {
+	grp := adagui.NewGroup()
+	grp.Layout = adagui.NewColumnGridLayout(2)
+	btns := make([]*adagui.TextButton, 4)
+	for i := range btns {
+		btns[i] = adagui.NewTextButton("Key")
+		grp.Add(btns[i])
+	}
+	return grp, btns
+}
+
+func TestFocusTraversal(t *testing.T) {
+	grp, btns := buttonGrid()
+	h := adaguitest.New(t, centered(grp))
+
+	if h.Window.Focus() != nil {
+		t.Fatalf("new window has a focused node")
+	}
+	steps := []struct {
+		code key.Code
+		mod  key.Modifier
+		want int
+	}{
+		{key.CodeTab, 0, 0},
+		{key.CodeTab, 0, 1},
+		{key.CodeTab, key.ModShift, 0},
+		{key.CodeTab, key.ModShift, 3},
+		{key.CodeUp, 0, 1},
+		{key.CodeLeft, 0, 0},
+		{key.CodeDown, 0, 2},
+		{key.CodeDown, 0, 2},
+		{key.CodeRight, 0, 3},
+	}
+	for i, step := range steps {
+		h.Key(step.code, step.mod)
+		if got := h.Window.Focus(); got != btns[step.want] {
+			t.Fatalf("step %d (%v): focus is not on button %d", i,
+				step.code, step.want)
+		}
+		if !btns[step.want].Focused() {
+			t.Errorf("step %d: Focused() is false", i)
+		}
+	}
+}
+
+func TestFocusSkipsHidden(t *testing.T) {
+	grp, btns := buttonGrid()
+	btns[1].SetVisible(false)
+	h := adaguitest.New(t, centered(grp))
+
+	h.Key(key.CodeTab, 0)
+	h.Key(key.CodeTab, 0)
+	if h.Window.Focus() != btns[2] {
+		t.Errorf("focus did not skip the hidden button")
+	}
+}
+
+func TestFocusRing(t *testing.T) {
+	btn := adagui.NewTextButton("Focus")
+	h := adaguitest.New(t, centered(btn))
+
+	before := h.Image()
+	h.Key(key.CodeTab, 0)
+	pt := btn.Wrappee().Parent.Local2Screen(btn.Rect().Min).AddXY(-2, 10)
+	x, y := int(pt.X), int(pt.Y)
+	if before.RGBAAt(x, y) == h.Image().RGBAAt(x, y) {
+		t.Errorf("no focus ring painted at %v", pt)
+	}
+	h.Window.SetFocus(nil)
+	h.WaitIdle()
+	if before.RGBAAt(x, y) != h.Image().RGBAAt(x, y) {
+		t.Errorf("focus ring not removed at %v", pt)
+	}
+}
+
+func TestKeyActivate(t *testing.T) {
+	chk := adagui.NewCheckbox("Senf")
+	h := adaguitest.New(t, centered(chk))
+
+	h.Key(key.CodeTab, 0)
+	h.Key(key.CodeEnter, 0)
+	if !chk.Checked() {
+		t.Errorf("checkbox not checked after Enter")
+	}
+	h.Key(key.CodeSpace, 0)
+	if chk.Checked() {
+		t.Errorf("checkbox still checked after Space")
+	}
+}
+
+func TestSliderKeys(t *testing.T) {
+	sld := adagui.NewSlider(200, adagui.Horizontal)
+	h := adaguitest.New(t, centered(sld))
+
+	h.Key(key.CodeTab, 0)
+	h.Key(key.CodeRight, 0)
+	h.Key(key.CodeRight, 0)
+	if v := sld.Value(); v < 0.19 || v > 0.21 {
+		t.Errorf("slider value after two steps is %v, want 0.2", v)
+	}
+	h.Key(key.CodeLeft, 0)
+	if v := sld.Value(); v < 0.09 || v > 0.11 {
+		t.Errorf("slider value after step back is %v, want 0.1", v)
+	}
+}
+
+func TestKeyFunc(t *testing.T) {
+	h := adaguitest.New(t, centered(adagui.NewLabel("Esc")))
+
+	done := make(chan key.Code, 1)
+	h.Window.SetKeyFunc(func(evt key.Event) {
+		if evt.Action == key.Press {
+			done <- evt.Code
+		}
+	})
+	h.Key(key.CodeEsc, 0)
+	select {
+	case code := <-done:
+		if code != key.CodeEsc {
+			t.Errorf("got key %v, want %v", code, key.CodeEsc)
+		}
+	default:
+		t.Errorf("key function not called")
+	}
+}
@@ -0,0 +1,361 @@
+package adagui
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/stefan-muehlebach/gg"
+	"github.com/stefan-muehlebach/gg/colors"
+	"github.com/stefan-muehlebach/gg/fonts"
+	"golang.org/x/image/font"
+)
+
+var (
+	// Voreinstellung fuer die Bildrate, mit welcher ein Screen gezeichnet
+	// wird, solange sich etwas veraendert (siehe SetTargetFPS).
+	DefaultTargetFPS = float64(time.Second) / float64(refreshRate)
+
+	// Voreinstellung fuer die minimale Bildrate, auf welche der Bildaufbau
+	// gedrosselt wird, wenn sich nichts mehr veraendert (siehe SetIdleFPS).
+	DefaultIdleFPS = 4.0
+
+	// So lange muss der Screen unveraendert bleiben, bis die Bildrate
+	// gedrosselt wird.
+	IdleDelay = 500 * time.Millisecond
+
+	// Zeitraum, ueber welchen die Werte in FrameStats gemittelt werden.
+	StatsPeriod = time.Second
+)
+
+// FrameStats enthaelt die Kennzahlen zum Bildaufbau eines Screens (siehe
+// Screen.FrameStats). Die Zeiten sind Mittelwerte ueber alle Bildaufbauten
+// der letzten Messperiode (StatsPeriod), bei welchen etwas gezeichnet wurde.
+type FrameStats struct {
+	// Anzahl Bildaufbauten pro Sekunde in der letzten Messperiode. Ist die
+	// Bildrate gedrosselt, liegt dieser Wert unter TargetFPS.
+	FPS float64
+	// Die mit SetTargetFPS eingestellte Bildrate.
+	TargetFPS float64
+	// Ist true, solange die Bildrate gedrosselt ist.
+	Idle bool
+	// Gesamtzahl der Bildaufbauten, resp. derjenigen, bei welchen etwas
+	// gezeichnet wurde.
+	Frames, Painted int
+	// Gesamtzahl der Bildaufbauten, welche ausgelassen werden mussten,
+	// weil der vorangehende Bildaufbau zu lange gedauert hat.
+	Dropped int
+	// Zeit fuer das Weiterschalten der Animationen und das Layout.
+	LayoutTime time.Duration
+	// Zeit fuer das Zeichnen der Fenster.
+	PaintTime time.Duration
+	// Zeit fuer die Uebertragung der Bilddaten an das Backend.
+	TransferTime time.Duration
+}
+
+func (fs FrameStats) String() string {
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	return fmt.Sprintf("%4.1f fps L%4.1f P%4.1f T%4.1f ms D%d", fs.FPS,
+		ms(fs.LayoutTime), ms(fs.PaintTime), ms(fs.TransferTime), fs.Dropped)
+}
+
+// Mit frameClock wird die Bildrate eines Screens gesteuert und gemessen.
+// Die Zeiten eines Bildaufbaus werden im UI-Thread erfasst (siehe record),
+// die Steuerung des Tickers erfolgt im paintThread.
+type frameClock struct {
+	mutex     sync.Mutex
+	targetFPS float64
+	idleFPS   float64
+	stopped   bool
+	interval  time.Duration
+	lastTick  time.Time
+	lastBusy  time.Time
+	throttled atomic.Bool
+	wakeQ     chan bool
+	doneQ     chan bool
+
+	stats       FrameStats
+	periodStart time.Time
+	frames      int
+	painted     int
+	sum         FrameStats
+
+	// Diese Felder gehoeren dem UI-Thread.
+	cur         frameSample
+	showFPS     atomic.Bool
+	overlay     *gg.Context
+	overlayFace font.Face
+	overlayText string
+}
+
+// Die Zeiten eines einzelnen Bildaufbaus.
+type frameSample struct {
+	busy                  bool
+	layout, paint, transf time.Duration
+}
+
+func (c *frameClock) init() {
+	c.targetFPS = DefaultTargetFPS
+	c.idleFPS = DefaultIdleFPS
+	c.interval = c.frameInterval()
+	c.stats.TargetFPS = c.targetFPS
+	c.wakeQ = make(chan bool, 1)
+	c.doneQ = make(chan bool)
+}
+
+// Liefert den Abstand zwischen zwei Bildaufbauten bei der Ziel-Bildrate.
+// Muss unter dem Lock aufgerufen werden.
+func (c *frameClock) frameInterval() time.Duration {
+	return time.Duration(float64(time.Second) / c.targetFPS)
+}
+
+// Liefert den Abstand zwischen zwei Bildaufbauten bei voll gedrosselter
+// Bildrate. Muss unter dem Lock aufgerufen werden.
+func (c *frameClock) idleInterval() time.Duration {
+	if c.idleFPS <= 0.0 || c.idleFPS >= c.targetFPS {
+		return c.frameInterval()
+	}
+	return time.Duration(float64(time.Second) / c.idleFPS)
+}
+
+// Setzt den Ticker auf den Abstand d. Muss unter dem Lock aufgerufen
+// werden.
+func (c *frameClock) reset(t *time.Ticker, d time.Duration) {
+	c.interval = d
+	c.lastTick = time.Time{}
+	if !c.stopped {
+		t.Reset(d)
+	}
+}
+
+// Wird nach jedem Bildaufbau im paintThread aufgerufen. Nachgefuehrt
+// werden die Statistik und (falls sich nichts mehr veraendert) die
+// gedrosselte Bildrate.
+func (c *frameClock) update(t *time.Ticker, now time.Time, fs frameSample) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	close(c.doneQ)
+	c.doneQ = make(chan bool)
+
+	frameIntv := c.frameInterval()
+	if !c.lastTick.IsZero() && c.interval == frameIntv {
+		n := int((now.Sub(c.lastTick)+frameIntv/2)/frameIntv) - 1
+		if n > 0 {
+			c.stats.Dropped += n
+		}
+	}
+	c.lastTick = now
+
+	c.stats.Frames++
+	c.frames++
+	if fs.paint > 0 || fs.transf > 0 {
+		c.stats.Painted++
+		c.painted++
+		c.sum.LayoutTime += fs.layout
+		c.sum.PaintTime += fs.paint
+		c.sum.TransferTime += fs.transf
+	}
+	if c.periodStart.IsZero() {
+		c.periodStart = now
+	}
+	if d := now.Sub(c.periodStart); d >= StatsPeriod {
+		c.stats.FPS = float64(c.frames) / d.Seconds()
+		if c.painted > 0 {
+			n := time.Duration(c.painted)
+			c.stats.LayoutTime = c.sum.LayoutTime / n
+			c.stats.PaintTime = c.sum.PaintTime / n
+			c.stats.TransferTime = c.sum.TransferTime / n
+		} else {
+			c.stats.LayoutTime = 0
+			c.stats.PaintTime = 0
+			c.stats.TransferTime = 0
+		}
+		c.periodStart = now
+		c.frames, c.painted = 0, 0
+		c.sum = FrameStats{}
+	}
+
+	// Die Bildrate wird schrittweise bis auf idleFPS reduziert.
+	if fs.busy || c.lastBusy.IsZero() {
+		c.lastBusy = now
+		if fs.busy && c.throttled.Swap(false) {
+			c.stats.Idle = false
+			c.reset(t, c.frameInterval())
+		}
+		return
+	}
+	if now.Sub(c.lastBusy) < IdleDelay {
+		return
+	}
+	idleIntv := c.idleInterval()
+	if c.interval >= idleIntv {
+		return
+	}
+	c.throttled.Store(true)
+	c.stats.Idle = true
+	c.reset(t, min(2*c.interval, idleIntv))
+}
+
+// Stellt die volle Bildrate wieder her, falls sie gedrosselt ist.
+func (c *frameClock) unthrottle(t *time.Ticker) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.lastBusy = time.Now()
+	if !c.throttled.Swap(false) {
+		return
+	}
+	c.stats.Idle = false
+	c.reset(t, c.frameInterval())
+}
+
+// Meldet dem paintThread, dass sich etwas veraendert hat. Ist die Bildrate
+// gedrosselt, wird sie sofort wieder auf die Ziel-Bildrate gesetzt.
+func (c *frameClock) wake() {
+	if !c.throttled.Load() {
+		return
+	}
+	select {
+	case c.wakeQ <- true:
+	default:
+	}
+}
+
+// Liefert einen Kanal, welcher nach dem naechsten abgeschlossenen
+// Bildaufbau geschlossen wird.
+func (c *frameClock) nextFrame() <-chan bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.doneQ
+}
+
+// Haelt die Zeiten fuer das Zeichnen und die Uebertragung des aktuellen
+// Bildaufbaus fest. Wird im UI-Thread aufgerufen.
+func (c *frameClock) record(paint, transf time.Duration) {
+	c.cur.paint += paint
+	c.cur.transf += transf
+}
+
+// Mit SetTargetFPS wird die Bildrate festgelegt, mit welcher der Screen
+// gezeichnet wird, solange sich etwas veraendert (Default: DefaultTargetFPS).
+func (s *Screen) SetTargetFPS(fps float64) {
+	if fps <= 0.0 {
+		return
+	}
+	c := &s.frames
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.targetFPS = fps
+	c.stats.TargetFPS = fps
+	c.throttled.Store(false)
+	c.stats.Idle = false
+	c.reset(s.paintTicker, c.frameInterval())
+}
+
+// Liefert die mit SetTargetFPS eingestellte Bildrate.
+func (s *Screen) TargetFPS() float64 {
+	s.frames.mutex.Lock()
+	defer s.frames.mutex.Unlock()
+	return s.frames.targetFPS
+}
+
+// Veraendert sich waehrend IdleDelay nichts auf dem Screen (keine
+// markierten Nodes, keine Animationen), dann wird die Bildrate schrittweise
+// bis auf fps reduziert (Default: DefaultIdleFPS). Sobald wieder etwas neu
+// gezeichnet werden muss, wird mit der vollen Bildrate gearbeitet. Mit
+// fps <= 0 wird die Drosselung ausgeschaltet.
+func (s *Screen) SetIdleFPS(fps float64) {
+	c := &s.frames
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.idleFPS = fps
+	if c.throttled.Swap(false) {
+		c.stats.Idle = false
+		c.reset(s.paintTicker, c.frameInterval())
+	}
+}
+
+// Liefert die mit SetIdleFPS eingestellte minimale Bildrate.
+func (s *Screen) IdleFPS() float64 {
+	s.frames.mutex.Lock()
+	defer s.frames.mutex.Unlock()
+	return s.frames.idleFPS
+}
+
+// Liefert die aktuellen Kennzahlen zum Bildaufbau.
+func (s *Screen) FrameStats() FrameStats {
+	s.frames.mutex.Lock()
+	defer s.frames.mutex.Unlock()
+	return s.frames.stats
+}
+
+// Mit SetShowFPS wird die Anzeige der Kennzahlen zum Bildaufbau (siehe
+// FrameStats) in der linken oberen Ecke des Bildschirms ein-, resp.
+// ausgeschaltet. Die Anzeige kann auch beim Erzeugen des Screens aktiviert
+// werden (siehe ScreenOptions.ShowFPS).
+func (s *Screen) SetShowFPS(show bool) {
+	s.invoke(func() {
+		if s.frames.showFPS.Swap(show) == show {
+			return
+		}
+		s.frames.overlayText = ""
+		s.mutex.Lock()
+		for _, w := range s.stack {
+			w.damageAll()
+		}
+		s.mutex.Unlock()
+	})
+}
+
+// Liefert true, falls die Kennzahlen zum Bildaufbau angezeigt werden.
+func (s *Screen) ShowFPS() bool {
+	return s.frames.showFPS.Load()
+}
+
+// Erstellt bei Bedarf das Bild mit den aktuellen Kennzahlen neu und liefert
+// den Bereich, welchen es auf dem Bildschirm belegt. Wurde das Bild neu
+// erstellt, ist changed true. Wird im UI-Thread aufgerufen.
+func (s *Screen) updateOverlay() (r image.Rectangle, changed bool) {
+	c := &s.frames
+	text := s.FrameStats().String()
+	if c.overlayFace == nil {
+		c.overlayFace, _ = fonts.NewFace(fonts.GoMono, 11.0)
+	}
+	if text != c.overlayText || c.overlay == nil {
+		c.overlayText = text
+		changed = true
+		if c.overlay == nil {
+			c.overlay = gg.NewContext(1, 1)
+		}
+		c.overlay.SetFontFace(c.overlayFace)
+		w, h := c.overlay.MeasureString(text)
+		width, height := int(math.Ceil(w))+6, int(math.Ceil(h))+6
+		if c.overlay.Width() != width || c.overlay.Height() != height {
+			r = c.overlay.Image().Bounds()
+			c.overlay = gg.NewContext(width, height)
+		}
+		gc := c.overlay
+		gc.SetFillColor(colors.Black.Alpha(0.7))
+		gc.Clear()
+		gc.SetFontFace(c.overlayFace)
+		gc.SetTextColor(colors.YellowGreen)
+		gc.DrawStringAnchored(text, 3.0, float64(gc.Height())/2.0, 0.0, 0.5)
+	}
+	return r.Union(c.overlay.Image().Bounds()), changed
+}
+
+// Zeichnet die Kennzahlen in den Bereich r des Bildes dst.
+func (s *Screen) drawOverlay(dst draw.Image, r image.Rectangle) {
+	img := s.frames.overlay.Image()
+	r = r.Intersect(img.Bounds())
+	if r.Empty() {
+		return
+	}
+	draw.Draw(dst, r, img, r.Min, draw.Over)
+}
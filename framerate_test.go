@@ -0,0 +1,95 @@
+package adagui_test
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+)
+
+// Wartet bis zu timeout darauf, dass cond erfuellt ist.
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return true
+}
+
+func TestIdleThrottle(t *testing.T) {
+	btn := adagui.NewButton(60, 40)
+	h := adaguitest.New(t, centered(btn))
+
+	if !waitFor(5*time.Second, func() bool { return h.Screen.FrameStats().Idle }) {
+		t.Fatalf("frame rate not throttled: %+v", h.Screen.FrameStats())
+	}
+	h.Do(func() { btn.Mark(adagui.MarkNeedsPaint) })
+	if !waitFor(time.Second, func() bool { return !h.Screen.FrameStats().Idle }) {
+		t.Fatalf("frame rate still throttled after marking a node")
+	}
+	h.WaitIdle()
+	if fs := h.Screen.FrameStats(); fs.Painted == 0 || fs.Frames < fs.Painted {
+		t.Errorf("inconsistent frame counters: %+v", fs)
+	}
+}
+
+func TestTargetFPS(t *testing.T) {
+	h := adaguitest.New(t, centered(adagui.NewButton(60, 40)))
+	h.Screen.SetTargetFPS(10.0)
+	h.Screen.SetIdleFPS(0.0)
+	t.Cleanup(func() {
+		h.Screen.SetTargetFPS(adagui.DefaultTargetFPS)
+		h.Screen.SetIdleFPS(adagui.DefaultIdleFPS)
+	})
+	if fps := h.Screen.TargetFPS(); fps != 10.0 {
+		t.Errorf("TargetFPS is %v, want 10", fps)
+	}
+
+	time.Sleep(2*adagui.StatsPeriod + 200*time.Millisecond)
+	fs := h.Screen.FrameStats()
+	if fs.FPS < 7.0 || fs.FPS > 12.0 {
+		t.Errorf("measured %.1f fps, want about 10", fs.FPS)
+	}
+	if fs.Idle {
+		t.Errorf("frame rate throttled although idle throttling is off")
+	}
+}
+
+func TestShowFPS(t *testing.T) {
+	h := adaguitest.New(t, centered(adagui.NewButton(60, 40)))
+	before := h.Backend.Image()
+
+	h.Screen.SetShowFPS(true)
+	h.WaitIdle()
+	img := h.Backend.Image()
+	region := image.Rect(0, 0, 40, 10)
+	if !differs(before, img, region) {
+		t.Errorf("no overlay drawn in the upper left corner")
+	}
+	if !h.Screen.ShowFPS() {
+		t.Errorf("ShowFPS returned false")
+	}
+
+	h.Screen.SetShowFPS(false)
+	h.WaitIdle()
+	if differs(before, h.Backend.Image(), region) {
+		t.Errorf("overlay not removed")
+	}
+}
+
+// Liefert true, falls sich die Bilder a und b im Bereich r unterscheiden.
+func differs(a, b *image.RGBA, r image.Rectangle) bool {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if a.RGBAAt(x, y) != b.RGBAAt(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
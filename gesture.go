@@ -0,0 +1,98 @@
+package adagui
+
+import (
+	"math"
+	"time"
+
+	"github.com/stefan-muehlebach/adagui/touch"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+// Laedt die Grenzwerte fuer die Gesten aus der Datei gestureFile (siehe
+// ScreenOptions.Gestures). Ohne Datei werden die Standardwerte retourniert.
+func loadGestureConfig(gestureFile string) (touch.GestureConfig, error) {
+	if gestureFile == "" {
+		return touch.DefaultGestureConfig(), nil
+	}
+	return touch.NewGestureConfigFromFile(gestureFile)
+}
+
+// Legt die Grenzwerte fest, mit welchen der Screen aus den rohen
+// Ereignissen des Touchscreens die Events Tap, DoubleTap, LongPress, Swipe
+// und Fling erzeugt. Einzelne Nodes koennen diese Werte mit
+// SetGestureConfig (siehe touch.TouchEmbed) fuer sich und alle darin
+// enthaltenen Nodes ueberschreiben.
+func (s *Screen) SetGestureConfig(cfg touch.GestureConfig) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.gestureConfig = cfg
+}
+
+// Liefert die Grenzwerte fuer die Gesten auf diesem Screen.
+func (s *Screen) GestureConfig() touch.GestureConfig {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.gestureConfig
+}
+
+// Ermittelt die Grenzwerte, welche fuer eine Beruehrung an der Position pt
+// im Fenster w gelten. Massgebend ist der naechste Node (ausgehend vom
+// getroffenen Node in Richtung Wurzel), welcher eigene Werte besitzt.
+func (s *Screen) gestureConfigAt(w *Window, pt geom.Point) touch.GestureConfig {
+	root := w.Root()
+	if root == nil {
+		return s.GestureConfig()
+	}
+	n := root.SelectTarget(pt)
+	if n == nil {
+		n = root
+	}
+	if cfg := nodeGestureConfig(n); cfg != nil {
+		return *cfg
+	}
+	return s.GestureConfig()
+}
+
+// Liefert die eigenen Grenzwerte des naechsten Nodes (ausgehend von n in
+// Richtung Wurzel) oder nil, falls keiner eigene Werte besitzt.
+func nodeGestureConfig(n Node) *touch.GestureConfig {
+	for {
+		if c, ok := n.(interface {
+			GestureConfig() *touch.GestureConfig
+		}); ok && c.GestureConfig() != nil {
+			return c.GestureConfig()
+		}
+		p := n.Wrappee().Parent
+		if p == nil {
+			return nil
+		}
+		n = p.Wrapper
+	}
+}
+
+// Erzeugt aus dem Release-Event evt die Swipe- und Fling-Events. dur ist
+// die Dauer seit dem Press-Event und tracker enthaelt die letzten
+// Positionen der Bewegung. Ein Swipe wird vor einem Fling gemeldet.
+func gestureEvents(evt touch.Event, dur time.Duration,
+	tracker *touch.VelocityTracker, cfg touch.GestureConfig) []touch.Event {
+	var events []touch.Event
+
+	d := evt.Pos.Sub(evt.InitPos)
+	vel := tracker.Velocity()
+	if dist := math.Max(math.Abs(d.X), math.Abs(d.Y)); dist >= cfg.SwipeMinDistance &&
+		dur <= cfg.SwipeMaxDuration {
+		swipeEvt := evt
+		swipeEvt.Type = touch.TypeSwipe
+		swipeEvt.Direction = touch.DirectionOf(d)
+		swipeEvt.Velocity = vel
+		events = append(events, swipeEvt)
+	}
+	if math.Hypot(vel.X, vel.Y) >= cfg.FlingMinVelocity {
+		flingEvt := evt
+		flingEvt.Type = touch.TypeFling
+		flingEvt.Direction = touch.DirectionOf(vel)
+		flingEvt.Velocity = vel
+		events = append(events, flingEvt)
+	}
+	return events
+}
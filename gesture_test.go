@@ -0,0 +1,138 @@
+package adagui_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+	"github.com/stefan-muehlebach/adagui/touch"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+// Baut ein Panel auf, welches das ganze Fenster fuellt und alle Swipe-
+// und Fling-Events aufzeichnet.
+func gesturePanel() (*adagui.Panel, *adaguitest.Recorder) {
+	pnl := adagui.NewPanel(0, 0)
+	rec := adaguitest.NewRecorder()
+	pnl.SetOnSwipe(rec.Record)
+	pnl.SetOnFling(rec.Record)
+	return pnl, rec
+}
+
+func TestSwipeAndFling(t *testing.T) {
+	pnl, rec := gesturePanel()
+	h := adaguitest.New(t, pnl)
+
+	c := h.Center(pnl)
+	h.Swipe(c.AddXY(60, 0), c.AddXY(-60, 0), 150*time.Millisecond, 6)
+	events := rec.Events()
+	if len(events) != 2 {
+		t.Fatalf("got %d events (%v), want Swipe and Fling", len(events),
+			rec.Types())
+	}
+	swipe, fling := events[0], events[1]
+	if swipe.Type != touch.TypeSwipe || swipe.Direction != touch.DirLeft {
+		t.Errorf("got %v %v, want Swipe Left", swipe.Type, swipe.Direction)
+	}
+	if fling.Type != touch.TypeFling || fling.Direction != touch.DirLeft {
+		t.Errorf("got %v %v, want Fling Left", fling.Type, fling.Direction)
+	}
+	if v := fling.Velocity.X; v > -700 || v < -900 {
+		t.Errorf("fling velocity is %v, want about -800 px/s", v)
+	}
+}
+
+func TestSlowDragNoGesture(t *testing.T) {
+	pnl, rec := gesturePanel()
+	h := adaguitest.New(t, pnl)
+
+	c := h.Center(pnl)
+	h.Swipe(c, c.AddXY(0, 60), 2*time.Second, 20)
+	if n := len(rec.Events()); n != 0 {
+		t.Errorf("slow drag produced %d events (%v)", n, rec.Types())
+	}
+}
+
+func TestFlingWithoutSwipe(t *testing.T) {
+	pnl, rec := gesturePanel()
+	h := adaguitest.New(t, pnl)
+
+	c := h.Center(pnl)
+	h.Swipe(c, c.AddXY(0, 30), 60*time.Millisecond, 3)
+	types := rec.Types()
+	if len(types) != 1 || types[0] != touch.TypeFling {
+		t.Fatalf("got events %v, want only Fling", types)
+	}
+	if d := rec.Events()[0].Direction; d != touch.DirDown {
+		t.Errorf("got direction %v, want Down", d)
+	}
+}
+
+// Sendet Press und Release an den Positionen p0, resp. p1 mit dem
+// zeitlichen Abstand d.
+func pressRelease(h *adaguitest.Harness, p0, p1 geom.Point, d time.Duration) {
+	t0 := time.Now()
+	h.Backend.SendEvent(adagui.PenEvent{Type: adagui.PenPress, Pos: p0,
+		Time: t0})
+	h.Backend.SendEvent(adagui.PenEvent{Type: adagui.PenRelease, Pos: p1,
+		Time: t0.Add(d)})
+	h.WaitIdle()
+}
+
+func TestTapDuration(t *testing.T) {
+	btn := adagui.NewButton(60, 40)
+	rec := adaguitest.NewRecorder()
+	btn.SetOnTap(rec.Record)
+	h := adaguitest.New(t, centered(btn))
+
+	c := h.Center(btn)
+	pressRelease(h, c, c, touch.TapDuration+50*time.Millisecond)
+	if n := rec.Count(touch.TypeTap); n != 0 {
+		t.Errorf("got %d taps for a press longer than TapDuration", n)
+	}
+	time.Sleep(touch.DoubleTapDuration)
+	pressRelease(h, c, c, touch.TapDuration/2)
+	if n := rec.Count(touch.TypeTap); n != 1 {
+		t.Errorf("got %d taps, want 1", n)
+	}
+}
+
+func TestNodeGestureConfig(t *testing.T) {
+	btn := adagui.NewButton(100, 60)
+	rec := adaguitest.NewRecorder()
+	btn.SetOnTap(rec.Record)
+	h := adaguitest.New(t, centered(btn))
+
+	c := h.Center(btn)
+	pressRelease(h, c, c.AddXY(20, 0), 0)
+	if n := rec.Count(touch.TypeTap); n != 0 {
+		t.Fatalf("got %d taps with the default NearThreshold", n)
+	}
+
+	cfg := h.Screen.GestureConfig()
+	cfg.NearThreshold = 30
+	btn.SetGestureConfig(&cfg)
+	time.Sleep(touch.DoubleTapDuration)
+	pressRelease(h, c, c.AddXY(20, 0), 0)
+	if n := rec.Count(touch.TypeTap); n != 1 {
+		t.Errorf("got %d taps with a NearThreshold of 30, want 1", n)
+	}
+}
+
+func TestScreenGestureConfig(t *testing.T) {
+	pnl, rec := gesturePanel()
+	h := adaguitest.New(t, pnl)
+	old := h.Screen.GestureConfig()
+	t.Cleanup(func() { h.Screen.SetGestureConfig(old) })
+
+	cfg := old
+	cfg.SwipeMinDistance = 200
+	cfg.FlingMinVelocity = 5000
+	h.Screen.SetGestureConfig(cfg)
+	c := h.Center(pnl)
+	h.Swipe(c.AddXY(60, 0), c.AddXY(-60, 0), 150*time.Millisecond, 6)
+	if n := len(rec.Events()); n != 0 {
+		t.Errorf("got %d events (%v) with raised thresholds", n, rec.Types())
+	}
+}
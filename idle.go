@@ -0,0 +1,313 @@
+package adagui
+
+import (
+	"cmp"
+	"errors"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Ein Schritt der Inaktivitaet: nach der Dauer after ohne Touch- oder
+// Tastatur-Ereignis wird idle aufgerufen, beim Aufwachen wake. Beide
+// Funktionen werden im UI-Thread ausgefuehrt.
+type idleStage struct {
+	after time.Duration
+	idle  func()
+	wake  func()
+	fired bool
+}
+
+// Mit idleState verfolgt der Screen die Zeit seit dem letzten Ereignis.
+// Neben den Funktionen, welche mit AddIdleFunc registriert werden, gibt es
+// je einen Schritt fuer den Bildschirmschoner und das Dimmen, resp.
+// Ausschalten der Hintergrundbeleuchtung.
+type idleState struct {
+	mutex        sync.Mutex
+	lastActivity time.Time
+	stages       []*idleStage
+	saver        idleStage
+	saverWin     *Window
+	dim, off     idleStage
+	dimLevel     float64
+	backlight    float64
+	timer        *time.Timer
+	closed       bool
+}
+
+func (s *Screen) initIdle() {
+	st := &s.idle
+	st.lastActivity = time.Now()
+	st.backlight = 1.0
+	st.saver.idle = s.showScreensaver
+	st.saver.wake = s.hideScreensaver
+	st.dim.idle = func() { s.applyBacklight(s.dimLevel()) }
+	st.dim.wake = func() { s.applyBacklight(s.Backlight()) }
+	st.off.idle = func() { s.applyBacklight(0.0) }
+	st.off.wake = st.dim.wake
+}
+
+// Liefert die Zeit seit dem letzten Touch- oder Tastatur-Ereignis.
+func (s *Screen) IdleTime() time.Duration {
+	s.idle.mutex.Lock()
+	defer s.idle.mutex.Unlock()
+	return time.Since(s.idle.lastActivity)
+}
+
+// Liefert true, falls mindestens ein Schritt der Inaktivitaet erreicht
+// wurde (Idle-Funktion, Bildschirmschoner oder Hintergrundbeleuchtung).
+// Das naechste Ereignis weckt den Screen nur auf und wird nicht an die
+// Widgets weitergeleitet.
+func (s *Screen) Idle() bool {
+	s.idle.mutex.Lock()
+	defer s.idle.mutex.Unlock()
+	return s.idle.anyFired()
+}
+
+// Mit IdleFunc wird eine mit AddIdleFunc registrierte Funktion bezeichnet.
+// Sie kann mit RemoveIdleFunc wieder entfernt werden.
+type IdleFunc struct {
+	stage idleStage
+}
+
+// Mit AddIdleFunc wird die Funktion idle registriert, welche aufgerufen
+// wird, wenn waehrend der Dauer after kein Touch- oder Tastatur-Ereignis
+// eingetroffen ist. Beim naechsten Ereignis wird wake aufgerufen (sofern
+// nicht nil). Beide Funktionen werden im UI-Thread ausgefuehrt.
+func (s *Screen) AddIdleFunc(after time.Duration, idle, wake func()) *IdleFunc {
+	f := &IdleFunc{stage: idleStage{after: after, idle: idle, wake: wake}}
+	s.idle.mutex.Lock()
+	s.idle.stages = append(s.idle.stages, &f.stage)
+	s.idle.mutex.Unlock()
+	s.scheduleIdle()
+	return f
+}
+
+// Entfernt die mit AddIdleFunc registrierte Funktion f. Wurde ihre
+// Idle-Funktion bereits aufgerufen, wird die Wake-Funktion nicht mehr
+// aufgerufen.
+func (s *Screen) RemoveIdleFunc(f *IdleFunc) {
+	s.idle.mutex.Lock()
+	s.idle.stages = slices.DeleteFunc(s.idle.stages, func(stage *idleStage) bool {
+		return stage == &f.stage
+	})
+	s.idle.mutex.Unlock()
+	s.scheduleIdle()
+}
+
+// Mit SetScreensaver wird das Fenster w als Bildschirmschoner festgelegt.
+// Es wird nach der Dauer after ohne Ereignisse als modales Fenster auf den
+// Stack gelegt (siehe PushWindow) und beim Aufwachen wieder entfernt. Mit
+// w gleich nil wird der Bildschirmschoner ausgeschaltet.
+func (s *Screen) SetScreensaver(w *Window, after time.Duration) {
+	s.invoke(func() {
+		s.idle.mutex.Lock()
+		fired := s.idle.saver.fired
+		s.idle.mutex.Unlock()
+		if fired {
+			s.hideScreensaver()
+		}
+		s.idle.mutex.Lock()
+		s.idle.saverWin = w
+		s.idle.saver.after = after
+		s.idle.saver.fired = false
+		if w == nil {
+			s.idle.saver.after = 0
+		}
+		s.idle.mutex.Unlock()
+	})
+	s.scheduleIdle()
+}
+
+// Nach der Dauer dimAfter ohne Ereignisse wird die Hintergrundbeleuchtung
+// auf level gedimmt, nach offAfter ganz ausgeschaltet. Mit einer Dauer von
+// 0 wird der jeweilige Schritt ausgeschaltet. Das Backend muss dazu
+// SetBacklight unterstuetzen.
+func (s *Screen) SetBacklightTimeouts(dimAfter time.Duration, level float64,
+	offAfter time.Duration) {
+	s.idle.mutex.Lock()
+	s.idle.dim.after = dimAfter
+	s.idle.dimLevel = level
+	s.idle.off.after = offAfter
+	s.idle.mutex.Unlock()
+	s.scheduleIdle()
+}
+
+// Setzt die Helligkeit der Hintergrundbeleuchtung im aktiven Zustand (0.0
+// bis 1.0, Default: 1.0).
+func (s *Screen) SetBacklight(level float64) {
+	s.idle.mutex.Lock()
+	s.idle.backlight = level
+	idle := s.idle.dim.fired || s.idle.off.fired
+	s.idle.mutex.Unlock()
+	if !idle {
+		s.applyBacklight(level)
+	}
+}
+
+// Liefert die Helligkeit der Hintergrundbeleuchtung im aktiven Zustand.
+func (s *Screen) Backlight() float64 {
+	s.idle.mutex.Lock()
+	defer s.idle.mutex.Unlock()
+	return s.idle.backlight
+}
+
+// Liefert die Helligkeit, auf welche bei Inaktivitaet gedimmt wird.
+func (s *Screen) dimLevel() float64 {
+	s.idle.mutex.Lock()
+	defer s.idle.mutex.Unlock()
+	return s.idle.dimLevel
+}
+
+func (s *Screen) applyBacklight(level float64) {
+	err := s.backend.SetBacklight(level)
+	if err != nil && !errors.Is(err, ErrNoBacklight) {
+		log.Printf("backlight: %v", err)
+	}
+}
+
+// Legt den Bildschirmschoner auf den Stack. Wird im UI-Thread aufgerufen.
+func (s *Screen) showScreensaver() {
+	s.idle.mutex.Lock()
+	w := s.idle.saverWin
+	s.idle.mutex.Unlock()
+	if w != nil {
+		s.pushWindow(w, WindowModal)
+		s.repaint()
+	}
+}
+
+// Entfernt den Bildschirmschoner vom Stack. Wird im UI-Thread aufgerufen.
+func (s *Screen) hideScreensaver() {
+	s.idle.mutex.Lock()
+	w := s.idle.saverWin
+	s.idle.mutex.Unlock()
+	if w == nil {
+		return
+	}
+	s.mutex.Lock()
+	removed := s.removeWindow(w)
+	if removed {
+		w.stage = StageAlive
+		s.updateStages()
+	}
+	s.mutex.Unlock()
+	if removed {
+		s.repaint()
+	}
+}
+
+// Muss unter dem Lock aufgerufen werden.
+func (st *idleState) allStages() []*idleStage {
+	list := []*idleStage{&st.saver, &st.dim, &st.off}
+	list = append(list, st.stages...)
+	return list
+}
+
+// Muss unter dem Lock aufgerufen werden.
+func (st *idleState) anyFired() bool {
+	for _, stage := range st.allStages() {
+		if stage.fired {
+			return true
+		}
+	}
+	return false
+}
+
+// Stellt den Timer auf den naechsten noch nicht erreichten Schritt.
+func (s *Screen) scheduleIdle() {
+	s.idle.mutex.Lock()
+	defer s.idle.mutex.Unlock()
+	st := &s.idle
+	if st.closed {
+		return
+	}
+	next := time.Duration(-1)
+	for _, stage := range st.allStages() {
+		if stage.after <= 0 || stage.fired {
+			continue
+		}
+		if next < 0 || stage.after < next {
+			next = stage.after
+		}
+	}
+	if next < 0 {
+		if st.timer != nil {
+			st.timer.Stop()
+		}
+		return
+	}
+	d := next - time.Since(st.lastActivity)
+	if st.timer == nil {
+		st.timer = time.AfterFunc(d, func() { s.Post(s.checkIdle) })
+	} else {
+		st.timer.Reset(d)
+	}
+}
+
+// Ruft die Funktionen aller Schritte auf, deren Dauer seit dem letzten
+// Ereignis abgelaufen ist. Wird im UI-Thread ausgefuehrt.
+func (s *Screen) checkIdle() {
+	s.idle.mutex.Lock()
+	elapsed := time.Since(s.idle.lastActivity)
+	var due []*idleStage
+	for _, stage := range s.idle.allStages() {
+		if stage.after > 0 && !stage.fired && stage.after <= elapsed {
+			stage.fired = true
+			due = append(due, stage)
+		}
+	}
+	s.idle.mutex.Unlock()
+	slices.SortStableFunc(due, func(a, b *idleStage) int {
+		return cmp.Compare(a.after, b.after)
+	})
+	for _, stage := range due {
+		if stage.idle != nil {
+			stage.idle()
+		}
+	}
+	s.scheduleIdle()
+}
+
+// Haelt ein Touch- oder Tastatur-Ereignis fest. War der Screen inaktiv
+// (siehe Idle), werden die Wake-Funktionen im UI-Thread aufgerufen und true
+// retourniert. Das Ereignis soll in diesem Fall nicht weitergeleitet werden.
+func (s *Screen) registerActivity() bool {
+	s.idle.mutex.Lock()
+	s.idle.lastActivity = time.Now()
+	var woken []*idleStage
+	for _, stage := range s.idle.allStages() {
+		if stage.fired {
+			stage.fired = false
+			woken = append(woken, stage)
+		}
+	}
+	s.idle.mutex.Unlock()
+	if len(woken) == 0 {
+		return false
+	}
+	// Aufgeweckt wird in umgekehrter Reihenfolge.
+	slices.SortStableFunc(woken, func(a, b *idleStage) int {
+		return cmp.Compare(b.after, a.after)
+	})
+	s.PostSync(func() {
+		for _, stage := range woken {
+			if stage.wake != nil {
+				stage.wake()
+			}
+		}
+	})
+	s.scheduleIdle()
+	return true
+}
+
+// Haelt den Timer an. Wird beim Beenden des Screens aufgerufen.
+func (s *Screen) stopIdle() {
+	s.idle.mutex.Lock()
+	defer s.idle.mutex.Unlock()
+	s.idle.closed = true
+	if s.idle.timer != nil {
+		s.idle.timer.Stop()
+	}
+}
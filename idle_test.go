@@ -0,0 +1,104 @@
+package adagui_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+	"github.com/stefan-muehlebach/adagui/touch"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+func TestIdleWake(t *testing.T) {
+	adaguitest.Shutdown()
+	b := adagui.NewMemBackend(adagui.DefaultMemWidth, adagui.DefaultMemHeight)
+	s, err := adagui.NewScreenWithBackend(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	btn := adagui.NewButton(60, 40)
+	btn.SetPos(geom.Point{X: 20, Y: 20})
+	rec := adaguitest.NewRecorder()
+	btn.SetTouchFunc(rec.Record, touch.TypePress)
+	grp := adagui.NewGroup()
+	grp.Add(btn)
+	w := s.NewWindow()
+	w.SetRoot(grp)
+	s.SetWindow(w)
+
+	var numIdle, numWake atomic.Int32
+	saver := s.NewWindow()
+	s.SetScreensaver(saver, 300*time.Millisecond)
+	s.SetBacklightTimeouts(400*time.Millisecond, 0.3, 500*time.Millisecond)
+	s.AddIdleFunc(300*time.Millisecond,
+		func() { numIdle.Add(1) }, func() { numWake.Add(1) })
+
+	time.Sleep(700 * time.Millisecond)
+	if !s.Idle() || s.IdleTime() < 500*time.Millisecond {
+		t.Errorf("screen not idle after %v", s.IdleTime())
+	}
+	if s.Window() != saver {
+		t.Errorf("screensaver not shown")
+	}
+	if lvl := b.Backlight(); lvl != 0.0 {
+		t.Errorf("backlight is %v, want 0", lvl)
+	}
+	if n := numIdle.Load(); n != 1 {
+		t.Errorf("idle function called %d times, want 1", n)
+	}
+
+	// Die erste Beruehrung weckt den Screen nur auf.
+	pt := geom.Point{X: 50, Y: 40}
+	b.Press(pt)
+	b.Release(pt)
+	time.Sleep(50 * time.Millisecond)
+	if s.Idle() {
+		t.Errorf("screen still idle after touch")
+	}
+	if s.Window() != w {
+		t.Errorf("screensaver not removed")
+	}
+	if lvl := b.Backlight(); lvl != 1.0 {
+		t.Errorf("backlight is %v after wake, want 1", lvl)
+	}
+	if n := numWake.Load(); n != 1 {
+		t.Errorf("wake function called %d times, want 1", n)
+	}
+	if n := len(rec.Types()); n != 0 {
+		t.Errorf("waking touch delivered %d events to the button", n)
+	}
+
+	b.Press(pt)
+	b.Release(pt)
+	time.Sleep(50 * time.Millisecond)
+	if n := len(rec.Types()); n != 1 {
+		t.Errorf("second touch delivered %d events, want 1", n)
+	}
+}
+
+func TestRemoveIdleFunc(t *testing.T) {
+	h := adaguitest.New(t, centered(adagui.NewButton(60, 40)))
+	var numIdle atomic.Int32
+	f := h.Screen.AddIdleFunc(100*time.Millisecond,
+		func() { numIdle.Add(1) }, nil)
+	h.Screen.RemoveIdleFunc(f)
+
+	time.Sleep(200 * time.Millisecond)
+	if n := numIdle.Load(); n != 0 {
+		t.Errorf("removed idle function called %d times", n)
+	}
+	if h.Screen.Idle() {
+		t.Errorf("screen idle without idle functions")
+	}
+}
@@ -2,6 +2,7 @@ package adagui
 
 import (
     "container/list"
+    "github.com/stefan-muehlebach/adagui/key"
     "github.com/stefan-muehlebach/adagui/touch"
     "github.com/stefan-muehlebach/gg/geom"
     "github.com/stefan-muehlebach/gg"
@@ -13,13 +14,15 @@ type Node interface {
     Wrappee() *Embed
 
     // Bewegt den aufrufenden Node an das Ende, resp. den Anfang der
-    // Node-Liste seines Parents.
-    ToBack()
-    ToFront()
+    // Node-Liste seines Parents. Hat der Node keinen Parent, wird
+    // ErrNotAttached retourniert.
+    ToBack() (error)
+    ToFront() (error)
     IsAtFront() (bool)
 
-    // Loescht den aufrufenden Node aus der Node-Liste seines Parents.
-    Remove()
+    // Loescht den aufrufenden Node aus der Node-Liste seines Parents (oder
+    // retourniert ErrNotAttached).
+    Remove() (error)
 
     // Setzt den Node an die angegebene Stelle, resp. retourniert die Position
     // des Widgets. Da jedes Widget seine 'Position' grundsätzlich selber
@@ -94,12 +97,29 @@ type Node interface {
 // Dieses Interface implementieren zusaetzlich alle Nodes, welche als
 // Container agieren koennen, d.h. eine Liste von weiteren Nodes fuehren.
 type Container interface {
-    Add(n ...Node)
+    Add(n ...Node) (error)
     Del(n Node)
     DelAll()
     layout()
 }
 
+// Nodes, welche Tastatur-Ereignisse selber verarbeiten wollen, implementieren
+// zusaetzlich dieses Interface. Hat der Node den Fokus, erhaelt er alle
+// Tastatur-Ereignisse seines Fensters als erster. Liefert OnKeyEvent true,
+// dann gilt das Ereignis als verarbeitet.
+type KeyHandler interface {
+    OnKeyEvent(evt key.Event) (bool)
+}
+
+// Widgets, deren Groesse (oder andere zwischengespeicherte Werte wie
+// Schriften) von den Properties abhaengt, implementieren zusaetzlich dieses
+// Interface. OnThemeChanged wird nach einem Wechsel des Themes (siehe
+// Screen.SetTheme) oder nach der Aenderung einer Schrift oder Groesse in
+// den Properties des Widgets im UI-Thread aufgerufen.
+type ThemeHandler interface {
+    OnThemeChanged()
+}
+
 // LayoutManager eben...
 type LayoutManager interface {
     Layout(childList *list.List, size geom.Point)
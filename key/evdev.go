@@ -0,0 +1,119 @@
+package key
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Typ der evdev-Ereignisse fuer Tasten (siehe 'linux/input-event-codes.h').
+const evKey = 0x01
+
+// Groesse einer 'struct input_event': zwei Woerter fuer den Zeitstempel
+// (abhaengig von der Wortbreite der Plattform), gefolgt von Typ, Code (je
+// 16 Bit) und Wert (32 Bit).
+var evdevEventSize = 2*strconv.IntSize/8 + 8
+
+// EvdevSource liest Tastatur-Ereignisse von einem Linux evdev-Geraet
+// (bspw. '/dev/input/event0').
+type EvdevSource struct {
+	fh        *os.File
+	eventQ    chan Event
+	closeQ    chan bool
+	closeOnce sync.Once
+	mod       Modifier
+}
+
+// Oeffnet das evdev-Geraet unter path und startet das Lesen der
+// Ereignisse.
+func OpenEvdev(path string) (*EvdevSource, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	src := newEvdevSource(fh)
+	src.fh = fh
+	return src, nil
+}
+
+func newEvdevSource(r io.Reader) *EvdevSource {
+	src := &EvdevSource{}
+	src.eventQ = make(chan Event)
+	src.closeQ = make(chan bool)
+	go src.readThread(r)
+	return src
+}
+
+func (src *EvdevSource) EventQ() <-chan Event {
+	return src.eventQ
+}
+
+// Schliesst das Geraet. Der Kanal EventQ wird anschliessend geschlossen,
+// auch wenn niemand mehr Ereignisse abholt. Weitere Aufrufe haben keine
+// Wirkung.
+func (src *EvdevSource) Close() {
+	src.closeOnce.Do(func() {
+		close(src.closeQ)
+		if src.fh != nil {
+			src.fh.Close()
+		}
+	})
+}
+
+// Liest die rohen Ereignisse, filtert alle Tasten-Ereignisse heraus und
+// fuehrt den Zustand der Modifier-Tasten nach.
+func (src *EvdevSource) readThread(r io.Reader) {
+	defer close(src.eventQ)
+	buf := make([]byte, evdevEventSize)
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return
+		}
+		evt, ok := src.decode(buf)
+		if !ok {
+			continue
+		}
+		select {
+		case src.eventQ <- evt:
+		case <-src.closeQ:
+			return
+		}
+	}
+}
+
+// Dekodiert eine 'struct input_event' aus buf. Liefert false, falls es sich
+// nicht um ein Tasten-Ereignis handelt.
+func (src *EvdevSource) decode(buf []byte) (Event, bool) {
+	var sec, usec int64
+	n := strconv.IntSize / 8
+	if n == 8 {
+		sec = int64(binary.LittleEndian.Uint64(buf[0:]))
+		usec = int64(binary.LittleEndian.Uint64(buf[8:]))
+	} else {
+		sec = int64(int32(binary.LittleEndian.Uint32(buf[0:])))
+		usec = int64(int32(binary.LittleEndian.Uint32(buf[4:])))
+	}
+	typ := binary.LittleEndian.Uint16(buf[2*n:])
+	code := Code(binary.LittleEndian.Uint16(buf[2*n+2:]))
+	value := int32(binary.LittleEndian.Uint32(buf[2*n+4:]))
+	if typ != evKey || value < 0 || value > int32(Repeat) {
+		return Event{}, false
+	}
+	evt := Event{
+		Code:   code,
+		Action: Action(value),
+		Time:   time.Unix(sec, usec*1000),
+	}
+	if mod := modifierOf(code); mod != 0 {
+		if evt.Action == Release {
+			src.mod &^= mod
+		} else {
+			src.mod |= mod
+		}
+	}
+	evt.Mod = src.mod
+	return evt, true
+}
@@ -0,0 +1,60 @@
+package key
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeSource ist eine Source, deren Ereignisse programmatisch erzeugt
+// werden. Sie ist fuer automatisierte Tests gedacht.
+type FakeSource struct {
+	eventQ chan Event
+	closeQ chan bool
+	once   sync.Once
+}
+
+func NewFakeSource() *FakeSource {
+	src := &FakeSource{}
+	src.eventQ = make(chan Event)
+	src.closeQ = make(chan bool)
+	return src
+}
+
+func (src *FakeSource) EventQ() <-chan Event {
+	return src.eventQ
+}
+
+func (src *FakeSource) Close() {
+	src.once.Do(func() {
+		close(src.closeQ)
+	})
+}
+
+// Uebermittelt das Ereignis evt. Die Methode blockiert, bis das Ereignis
+// abgeholt oder die Source geschlossen wurde. Ist der Zeitstempel von evt
+// nicht gesetzt, wird die aktuelle Zeit verwendet.
+func (src *FakeSource) Send(evt Event) {
+	if evt.Time.IsZero() {
+		evt.Time = time.Now()
+	}
+	select {
+	case src.eventQ <- evt:
+	case <-src.closeQ:
+	}
+}
+
+// Simuliert das Druecken der Taste code mit den Modifiern mod.
+func (src *FakeSource) Press(code Code, mod Modifier) {
+	src.Send(Event{Code: code, Action: Press, Mod: mod})
+}
+
+// Simuliert das Loslassen der Taste code mit den Modifiern mod.
+func (src *FakeSource) Release(code Code, mod Modifier) {
+	src.Send(Event{Code: code, Action: Release, Mod: mod})
+}
+
+// Simuliert das Druecken und anschliessende Loslassen der Taste code.
+func (src *FakeSource) Type(code Code, mod Modifier) {
+	src.Press(code, mod)
+	src.Release(code, mod)
+}
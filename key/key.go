@@ -0,0 +1,207 @@
+// Das Package key enthaelt alles, um Ereignisse von einer Tastatur (bspw.
+// einem per USB angeschlossenen Keypad) zu verarbeiten. Die Ereignisse
+// stammen von einer Source, welche entweder ein Linux evdev-Geraet liest
+// (siehe OpenEvdev) oder fuer Tests manuell gespiesen wird (siehe
+// FakeSource).
+package key
+
+import (
+	"fmt"
+	"time"
+)
+
+// Mit Code werden die einzelnen Tasten bezeichnet. Die Werte entsprechen
+// den Key-Codes von Linux (siehe 'linux/input-event-codes.h').
+type Code uint16
+
+const (
+	CodeEsc        Code = 1
+	Code1          Code = 2
+	Code2          Code = 3
+	Code3          Code = 4
+	Code4          Code = 5
+	Code5          Code = 6
+	Code6          Code = 7
+	Code7          Code = 8
+	Code8          Code = 9
+	Code9          Code = 10
+	Code0          Code = 11
+	CodeMinus      Code = 12
+	CodeEqual      Code = 13
+	CodeBackspace  Code = 14
+	CodeTab        Code = 15
+	CodeEnter      Code = 28
+	CodeLeftCtrl   Code = 29
+	CodeLeftShift  Code = 42
+	CodeRightShift Code = 54
+	CodeKPAsterisk Code = 55
+	CodeLeftAlt    Code = 56
+	CodeSpace      Code = 57
+	CodeNumLock    Code = 69
+	CodeKP7        Code = 71
+	CodeKP8        Code = 72
+	CodeKP9        Code = 73
+	CodeKPMinus    Code = 74
+	CodeKP4        Code = 75
+	CodeKP5        Code = 76
+	CodeKP6        Code = 77
+	CodeKPPlus     Code = 78
+	CodeKP1        Code = 79
+	CodeKP2        Code = 80
+	CodeKP3        Code = 81
+	CodeKP0        Code = 82
+	CodeKPDot      Code = 83
+	CodeKPEnter    Code = 96
+	CodeRightCtrl  Code = 97
+	CodeKPSlash    Code = 98
+	CodeRightAlt   Code = 100
+	CodeHome       Code = 102
+	CodeUp         Code = 103
+	CodePageUp     Code = 104
+	CodeLeft       Code = 105
+	CodeRight      Code = 106
+	CodeEnd        Code = 107
+	CodeDown       Code = 108
+	CodePageDown   Code = 109
+	CodeInsert     Code = 110
+	CodeDelete     Code = 111
+)
+
+var codeNames = map[Code]string{
+	CodeEsc:        "Esc",
+	Code1:          "1",
+	Code2:          "2",
+	Code3:          "3",
+	Code4:          "4",
+	Code5:          "5",
+	Code6:          "6",
+	Code7:          "7",
+	Code8:          "8",
+	Code9:          "9",
+	Code0:          "0",
+	CodeMinus:      "Minus",
+	CodeEqual:      "Equal",
+	CodeBackspace:  "Backspace",
+	CodeTab:        "Tab",
+	CodeEnter:      "Enter",
+	CodeLeftCtrl:   "LeftCtrl",
+	CodeLeftShift:  "LeftShift",
+	CodeRightShift: "RightShift",
+	CodeKPAsterisk: "KPAsterisk",
+	CodeLeftAlt:    "LeftAlt",
+	CodeSpace:      "Space",
+	CodeNumLock:    "NumLock",
+	CodeKP7:        "KP7",
+	CodeKP8:        "KP8",
+	CodeKP9:        "KP9",
+	CodeKPMinus:    "KPMinus",
+	CodeKP4:        "KP4",
+	CodeKP5:        "KP5",
+	CodeKP6:        "KP6",
+	CodeKPPlus:     "KPPlus",
+	CodeKP1:        "KP1",
+	CodeKP2:        "KP2",
+	CodeKP3:        "KP3",
+	CodeKP0:        "KP0",
+	CodeKPDot:      "KPDot",
+	CodeKPEnter:    "KPEnter",
+	CodeRightCtrl:  "RightCtrl",
+	CodeKPSlash:    "KPSlash",
+	CodeRightAlt:   "RightAlt",
+	CodeHome:       "Home",
+	CodeUp:         "Up",
+	CodePageUp:     "PageUp",
+	CodeLeft:       "Left",
+	CodeRight:      "Right",
+	CodeEnd:        "End",
+	CodeDown:       "Down",
+	CodePageDown:   "PageDown",
+	CodeInsert:     "Insert",
+	CodeDelete:     "Delete",
+}
+
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Code(%d)", uint16(c))
+}
+
+// Mit Action wird unterschieden, ob eine Taste gedrueckt, losgelassen oder
+// (durch laengeres Druecken) automatisch wiederholt wurde. Die Werte
+// entsprechen denjenigen von evdev.
+type Action uint8
+
+const (
+	Release Action = iota
+	Press
+	Repeat
+)
+
+func (a Action) String() string {
+	switch a {
+	case Release:
+		return "Release"
+	case Press:
+		return "Press"
+	case Repeat:
+		return "Repeat"
+	default:
+		return "(Unknown Action)"
+	}
+}
+
+// Modifier enthaelt den Zustand der Umschalt-, Control- und Alt-Tasten zum
+// Zeitpunkt eines Ereignisses.
+type Modifier uint8
+
+const (
+	ModShift Modifier = 1 << iota
+	ModCtrl
+	ModAlt
+)
+
+// Ein Event beschreibt ein einzelnes Ereignis von der Tastatur.
+type Event struct {
+	Code   Code
+	Action Action
+	Mod    Modifier
+	Time   time.Time
+}
+
+// Fuer das Debugging implementiert Event das Stringer-Interface.
+func (evt Event) String() string {
+	return fmt.Sprintf("%v %v %d %s", evt.Code, evt.Action, evt.Mod,
+		evt.Time.Format("15:04:05.000000"))
+}
+
+// Liefert true, falls die Taste gedrueckt oder automatisch wiederholt
+// wurde.
+func (evt Event) Down() bool {
+	return evt.Action == Press || evt.Action == Repeat
+}
+
+// Alle Callback-Handler fuer Tastatur-Ereignisse muessen dieses Profil
+// aufweisen.
+type KeyFunction func(evt Event)
+
+// Eine Source liefert Tastatur-Ereignisse ueber den Kanal EventQ. Nach dem
+// Schliessen mit Close werden keine Ereignisse mehr geliefert.
+type Source interface {
+	EventQ() <-chan Event
+	Close()
+}
+
+// Liefert den Modifier, welcher der Taste code entspricht oder 0, falls
+// code keine Umschalt-, Control- oder Alt-Taste ist.
+func modifierOf(code Code) Modifier {
+	switch code {
+	case CodeLeftShift, CodeRightShift:
+		return ModShift
+	case CodeLeftCtrl, CodeRightCtrl:
+		return ModCtrl
+	case CodeLeftAlt, CodeRightAlt:
+		return ModAlt
+	}
+	return 0
+}
@@ -0,0 +1,59 @@
+package key
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Erzeugt eine 'struct input_event' fuer die aktuelle Plattform.
+func rawEvent(typ uint16, code Code, value int32) []byte {
+	buf := make([]byte, evdevEventSize)
+	n := strconv.IntSize / 8
+	binary.LittleEndian.PutUint16(buf[2*n:], typ)
+	binary.LittleEndian.PutUint16(buf[2*n+2:], uint16(code))
+	binary.LittleEndian.PutUint32(buf[2*n+4:], uint32(value))
+	return buf
+}
+
+func TestEvdevDecode(t *testing.T) {
+	src := &EvdevSource{}
+	if _, ok := src.decode(rawEvent(0x00, 0, 0)); ok {
+		t.Errorf("sync event decoded as key event")
+	}
+	src.decode(rawEvent(evKey, CodeLeftShift, 1))
+	evt, ok := src.decode(rawEvent(evKey, CodeTab, 1))
+	if !ok || evt.Code != CodeTab || evt.Action != Press {
+		t.Fatalf("got %v, want Tab Press", evt)
+	}
+	if evt.Mod != ModShift {
+		t.Errorf("got modifier %d, want ModShift", evt.Mod)
+	}
+	src.decode(rawEvent(evKey, CodeLeftShift, 0))
+	evt, _ = src.decode(rawEvent(evKey, CodeTab, 0))
+	if evt.Action != Release || evt.Mod != 0 {
+		t.Errorf("got %v, want Tab Release without modifier", evt)
+	}
+}
+
+// Werden die Ereignisse nicht mehr abgeholt, beendet Close das Lesen
+// trotzdem und EventQ wird geschlossen.
+func TestEvdevClose(t *testing.T) {
+	data := append(rawEvent(evKey, CodeTab, 1), rawEvent(evKey, CodeTab, 0)...)
+	src := newEvdevSource(bytes.NewReader(data))
+	src.Close()
+	src.Close()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-src.EventQ():
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("EventQ not closed after Close")
+		}
+	}
+}
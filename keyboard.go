@@ -0,0 +1,82 @@
+package adagui
+
+import (
+	"github.com/stefan-muehlebach/adagui/key"
+)
+
+// Oeffnet die Tastatur am evdev-Geraet keyDevice (siehe
+// ScreenOptions.Keyboard). Ohne Geraet wird nil retourniert.
+func openKeyboard(keyDevice string) (key.Source, error) {
+	if keyDevice == "" {
+		return nil, nil
+	}
+	src, err := key.OpenEvdev(keyDevice)
+	if err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// Mit SetKeySource wird die Quelle der Tastatur-Ereignisse festgelegt. Die
+// Ereignisse gehen jeweils an das aktive Fenster (siehe Window.SetFocus).
+// Eine bisherige Quelle wird geschlossen. Mit nil werden keine
+// Tastatur-Ereignisse mehr verarbeitet.
+func (s *Screen) SetKeySource(src key.Source) {
+	s.mutex.Lock()
+	old, oldStopQ := s.keySource, s.keyStopQ
+	s.keySource, s.keyStopQ, s.keySyncQ = src, nil, nil
+	if src != nil {
+		s.keyStopQ = make(chan bool)
+		s.keySyncQ = make(chan chan bool)
+		go s.keyThread(src, s.keyStopQ, s.keySyncQ)
+	}
+	s.mutex.Unlock()
+	if old != nil {
+		close(oldStopQ)
+		old.Close()
+	}
+}
+
+// Liefert die aktuelle Quelle der Tastatur-Ereignisse.
+func (s *Screen) KeySource() key.Source {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.keySource
+}
+
+// Mit dieser Go-Routine werden die Ereignisse der Tastatur an das aktive
+// Fenster weitergeleitet. Waehrend eines Uebergangs zwischen zwei Fenstern
+// werden, wie bei den Touch-Events, keine Ereignisse verarbeitet.
+func (s *Screen) keyThread(src key.Source, stopQ chan bool,
+	syncQ chan chan bool) {
+	for {
+		select {
+		case <-stopQ:
+			return
+		case done := <-syncQ:
+			close(done)
+		case evt, ok := <-src.EventQ():
+			if !ok {
+				return
+			}
+			if s.registerActivity() || s.TransitionRunning() {
+				continue
+			}
+			s.mutex.Lock()
+			w := s.window
+			s.mutex.Unlock()
+			if w == nil {
+				continue
+			}
+			s.pendingInput.Add(1)
+			select {
+			case w.keyQ <- evt:
+			case <-w.eventCloseQ:
+				s.pendingInput.Add(-1)
+			case <-stopQ:
+				s.pendingInput.Add(-1)
+				return
+			}
+		}
+	}
+}
@@ -265,7 +265,7 @@ func (l *CenterLayout) Layout(childList *list.List, size geom.Point) {
 		childMin := child.MinSize()
 		dp := size.Sub(childMin).Mul(0.5)
 		child.SetSize(childMin)
-		child.SetPos(child.Pos().Add(dp))
+		child.SetPos(dp)
 	}
 }
 
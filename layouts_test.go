@@ -0,0 +1,159 @@
+package adagui_test
+
+import (
+	"container/list"
+	"testing"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+	"github.com/stefan-muehlebach/adagui/binding"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+func TestHBoxLayout(t *testing.T) {
+	grp := adagui.NewGroup()
+	grp.Layout = adagui.NewHBoxLayout(10)
+	b1 := adagui.NewButton(40, 20)
+	b2 := adagui.NewButton(60, 30)
+	grp.Add(b1, adagui.NewSpacer(), b2)
+	grp.SetSize(geom.Point{X: 200, Y: 50})
+	grp.UpdateLayout()
+
+	if p := b1.Pos(); !p.Eq(geom.Point{X: 0, Y: 0}) {
+		t.Errorf("b1 at %v, want (0, 0)", p)
+	}
+	if p := b2.Pos(); !p.Eq(geom.Point{X: 140, Y: 0}) {
+		t.Errorf("b2 at %v, want (140, 0)", p)
+	}
+	if s := b2.Size(); !s.Eq(geom.Point{X: 60, Y: 50}) {
+		t.Errorf("b2 has size %v, want (60, 50)", s)
+	}
+	if s := grp.MinSize(); !s.Eq(geom.Point{X: 110, Y: 30}) {
+		t.Errorf("group has min size %v, want (110, 30)", s)
+	}
+}
+
+func TestVBoxLayout(t *testing.T) {
+	grp := adagui.NewGroup()
+	grp.Layout = adagui.NewVBoxLayout(5)
+	b1 := adagui.NewButton(40, 20)
+	b2 := adagui.NewButton(60, 30)
+	grp.Add(b1, b2)
+	grp.SetSize(grp.MinSize())
+	grp.UpdateLayout()
+
+	if s := grp.MinSize(); !s.Eq(geom.Point{X: 60, Y: 55}) {
+		t.Errorf("group has min size %v, want (60, 55)", s)
+	}
+	if p := b2.Pos(); !p.Eq(geom.Point{X: 0, Y: 25}) {
+		t.Errorf("b2 at %v, want (0, 25)", p)
+	}
+	if s := b1.Size(); !s.Eq(geom.Point{X: 60, Y: 20}) {
+		t.Errorf("b1 has size %v, want (60, 20)", s)
+	}
+}
+
+func TestPaddedLayout(t *testing.T) {
+	grp := adagui.NewGroup()
+	grp.Layout = adagui.NewPaddedLayout(5, 10)
+	b := adagui.NewButton(10, 10)
+	grp.Add(b)
+	grp.SetSize(geom.Point{X: 100, Y: 100})
+	grp.UpdateLayout()
+
+	if p := b.Pos(); !p.Eq(geom.Point{X: 5, Y: 10}) {
+		t.Errorf("button at %v, want (5, 10)", p)
+	}
+	if s := b.Size(); !s.Eq(geom.Point{X: 90, Y: 80}) {
+		t.Errorf("button has size %v, want (90, 80)", s)
+	}
+}
+
+// Aendert sich der Text eines Labels, muss die BoxLayout, in welcher es
+// angeordnet ist, vor dem naechsten Bildaufbau neu berechnet werden.
+func TestLabelRelayout(t *testing.T) {
+	lbl := adagui.NewLabel("Short")
+	btn := adagui.NewButton(40, 20)
+	grp := adagui.NewGroup()
+	grp.Layout = adagui.NewHBoxLayout(10)
+	grp.Add(lbl, btn)
+	h := adaguitest.New(t, grp)
+
+	var before, after, lblWidth geom.Point
+	h.Do(func() { before = btn.Pos() })
+	h.Do(func() { lbl.SetText("A much longer label text") })
+	h.WaitIdle()
+	h.Do(func() {
+		after = btn.Pos()
+		lblWidth = lbl.Size()
+	})
+	if after.X <= before.X {
+		t.Errorf("button stays at %v after the label grew", after)
+	}
+	if want := lblWidth.X + 10; after.X != want {
+		t.Errorf("button at x=%v, want %v", after.X, want)
+	}
+}
+
+// Dasselbe gilt, wenn sich der Wert des Bind-Objektes eines Labels aendert.
+func TestLabelWithDataRelayout(t *testing.T) {
+	str := binding.NewString()
+	str.Set("Short")
+	lbl := adagui.NewLabelWithData(str)
+	btn := adagui.NewButton(40, 20)
+	grp := adagui.NewGroup()
+	grp.Layout = adagui.NewHBoxLayout(10)
+	grp.Add(lbl, btn)
+	h := adaguitest.New(t, grp)
+
+	var before, after, lblWidth geom.Point
+	h.Do(func() { before = btn.Pos() })
+	str.Set("A much longer label text")
+	h.WaitIdle()
+	h.Do(func() {
+		after = btn.Pos()
+		lblWidth = lbl.Size()
+	})
+	if after.X <= before.X {
+		t.Errorf("button stays at %v after the label text grew", after)
+	}
+	if want := lblWidth.X + 10; after.X != want {
+		t.Errorf("button at x=%v, want %v", after.X, want)
+	}
+}
+
+// LayoutManager, welcher die Anzahl Aufrufe von Layout zaehlt.
+type countingLayout struct {
+	adagui.LayoutManager
+	calls int
+}
+
+func (l *countingLayout) Layout(childList *list.List, size geom.Point) {
+	l.calls++
+	l.LayoutManager.Layout(childList, size)
+}
+
+// Beim Hinzufuegen vieler Kinder darf das Layout nur einmal berechnet
+// werden.
+func TestLayoutDeferred(t *testing.T) {
+	layout := &countingLayout{LayoutManager: adagui.NewVBoxLayout(0)}
+	grp := adagui.NewGroup()
+	grp.Layout = layout
+	var last adagui.Node
+	for i := 0; i < 100; i++ {
+		last = adagui.NewButton(20, 10)
+		grp.Add(last)
+	}
+	grp.SetSize(geom.Point{X: 50, Y: 1000})
+	if layout.calls != 0 {
+		t.Errorf("layout ran %d times before it was needed", layout.calls)
+	}
+	grp.UpdateLayout()
+	grp.UpdateLayout()
+	if p := last.Pos(); !p.Eq(geom.Point{X: 0, Y: 990}) {
+		t.Errorf("last button at %v, want (0, 990)", p)
+	}
+	if layout.calls != 1 {
+		t.Errorf("layout ran %d times, want 1", layout.calls)
+	}
+}
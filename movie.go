@@ -0,0 +1,477 @@
+package adagui
+
+import (
+	"bufio"
+	"compress/lzw"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// Maximale Dauer einer Filmaufnahme, falls in MovieOptions nichts
+	// angegeben wird.
+	DefaultMovieDuration = time.Minute
+
+	// Maximale Groesse einer Filmaufnahme in Bytes, falls in MovieOptions
+	// nichts angegeben wird. Damit wird verhindert, dass eine vergessene
+	// Aufnahme die SD-Karte fuellt.
+	DefaultMovieSize int64 = 100 << 20
+)
+
+// Mit MovieFormat wird das Format einer Filmaufnahme bestimmt.
+type MovieFormat int
+
+const (
+	// Das Format wird aus dem Namen der Aufnahme bestimmt: endet er auf
+	// '.gif', wird ein animiertes GIF erstellt, andernfalls eine Folge von
+	// PNG-Dateien.
+	MovieAuto MovieFormat = iota
+	// Jedes Bild wird als eigene PNG-Datei im Verzeichnis der Aufnahme
+	// abgelegt ('frame00000.png', 'frame00001.png', etc.). Die
+	// Zeitpunkte der Bilder (in ms seit Beginn der Aufnahme) werden in die
+	// Datei 'frames.txt' geschrieben.
+	MoviePNG
+	// Alle Bilder werden in einem animierten GIF abgelegt. Die Farben
+	// werden dazu auf eine Palette mit 256 Farben reduziert, die
+	// Zeitpunkte der Bilder bestimmen die Verzoegerungen. Die Bilder werden
+	// laufend in die Datei geschrieben, im Speicher wird jeweils nur das
+	// letzte Bild gehalten.
+	MovieGIF
+)
+
+// Mit MovieOptions werden die Einstellungen fuer eine Filmaufnahme
+// festgelegt (siehe Screen.StartMovie).
+type MovieOptions struct {
+	Format MovieFormat
+	// Nach dieser Dauer wird die Aufnahme automatisch beendet (Default:
+	// DefaultMovieDuration).
+	MaxDuration time.Duration
+	// Erreicht die Aufnahme diese Groesse in Bytes, wird sie automatisch
+	// beendet (Default: DefaultMovieSize).
+	MaxSize int64
+	// Ist Dither gesetzt, wird beim Reduzieren der Farben fuer GIF-Aufnahmen
+	// das Verfahren von Floyd-Steinberg verwendet.
+	Dither bool
+}
+
+// Ein einzelnes Bild einer Filmaufnahme: img enthaelt nur die veraenderten
+// Bereiche (bounds) des Bildschirms.
+type movieFrame struct {
+	img    *image.RGBA
+	bounds image.Rectangle
+	time   time.Time
+}
+
+// Mit movieRecorder werden die dargestellten Bilder aufgenommen. Die
+// Bilder werden im UI-Thread erfasst (siehe capture) und in einer eigenen
+// Go-Routine geschrieben. Kommt diese nicht nach, werden Bilder verworfen
+// (siehe dropped), damit der UI-Thread nie auf sie warten muss.
+type movieRecorder struct {
+	name     string
+	opts     MovieOptions
+	canvas   *image.RGBA
+	pending  image.Rectangle
+	dropped  atomic.Int64
+	frameQ   chan movieFrame
+	doneQ    chan bool
+	stopped  atomic.Bool
+	start    time.Time
+	size     int64
+	numFrame int
+	index    *os.File
+	indexW   *bufio.Writer
+	gif      *gifWriter
+	err      error
+}
+
+// Mit StartMovie wird eine Filmaufnahme gestartet. Ab sofort wird jedes
+// dargestellte Bild mit seinem Zeitpunkt aufgenommen, bis StopMovie
+// aufgerufen oder eine der Grenzen aus opts erreicht wird. Bei einer Folge
+// von PNG-Dateien (MoviePNG) ist name ein Verzeichnis, welches bei Bedarf
+// erstellt wird, bei einem animierten GIF (MovieGIF) der Name der Datei.
+func (s *Screen) StartMovie(name string, opts MovieOptions) error {
+	if opts.Format == MovieAuto {
+		opts.Format = MoviePNG
+		if strings.EqualFold(filepath.Ext(name), ".gif") {
+			opts.Format = MovieGIF
+		}
+	}
+	if opts.MaxDuration <= 0 {
+		opts.MaxDuration = DefaultMovieDuration
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultMovieSize
+	}
+	s.movieMutex.Lock()
+	running := s.movie != nil
+	s.movieMutex.Unlock()
+	if running {
+		return ErrMovieRunning
+	}
+	m := &movieRecorder{name: name, opts: opts}
+	if err := m.open(s.Size()); err != nil {
+		return err
+	}
+
+	s.movieMutex.Lock()
+	if s.movie != nil {
+		s.movieMutex.Unlock()
+		close(m.frameQ)
+		<-m.doneQ
+		return ErrMovieRunning
+	}
+	s.movie = m
+	s.movieMutex.Unlock()
+	// Damit das erste Bild den ganzen Bildschirm enthaelt, werden alle
+	// Fenster neu gezeichnet.
+	s.mutex.Lock()
+	for _, w := range s.stack {
+		w.damageAll()
+	}
+	s.mutex.Unlock()
+	return nil
+}
+
+// Beendet die laufende Filmaufnahme und schliesst die Dateien. Retourniert
+// wird ggf. ein Fehler, der beim Schreiben der Aufnahme aufgetreten ist.
+func (s *Screen) StopMovie() error {
+	// Die Bilder werden unter dem Lock erfasst (siehe present), danach
+	// nimmt der UI-Thread keine Bilder mehr in diese Aufnahme auf.
+	s.movieMutex.Lock()
+	m := s.movie
+	s.movie = nil
+	s.movieMutex.Unlock()
+	if m == nil {
+		return ErrNoMovie
+	}
+	close(m.frameQ)
+	<-m.doneQ
+	if n := m.dropped.Load(); n > 0 {
+		log.Printf("%s: %d frames dropped", m.name, n)
+	}
+	return m.err
+}
+
+// Liefert die Anzahl Bilder, welche bei der laufenden Filmaufnahme
+// verworfen werden mussten, weil sie nicht schnell genug geschrieben
+// werden konnten.
+func (s *Screen) MovieDroppedFrames() int {
+	s.movieMutex.Lock()
+	defer s.movieMutex.Unlock()
+	if s.movie == nil {
+		return 0
+	}
+	return int(s.movie.dropped.Load())
+}
+
+// Liefert true, solange eine Filmaufnahme laeuft.
+func (s *Screen) MovieRunning() bool {
+	s.movieMutex.Lock()
+	defer s.movieMutex.Unlock()
+	return s.movie != nil && !s.movie.stopped.Load()
+}
+
+// Bereitet die Aufnahme fuer einen Bildschirm der Groesse width x height
+// vor und startet die Go-Routine, welche die Bilder schreibt.
+func (m *movieRecorder) open(width, height int) error {
+	switch m.opts.Format {
+	case MoviePNG:
+		if err := os.MkdirAll(m.name, 0755); err != nil {
+			return err
+		}
+		fh, err := os.Create(filepath.Join(m.name, "frames.txt"))
+		if err != nil {
+			return err
+		}
+		m.index = fh
+		m.indexW = bufio.NewWriter(fh)
+	case MovieGIF:
+		fh, err := os.Create(m.name)
+		if err != nil {
+			return err
+		}
+		m.gif = newGIFWriter(fh, width, height, palette.Plan9)
+	default:
+		return fmt.Errorf("adagui: unknown movie format %d", m.opts.Format)
+	}
+	m.canvas = image.NewRGBA(image.Rect(0, 0, width, height))
+	m.frameQ = make(chan movieFrame, 8)
+	m.doneQ = make(chan bool)
+	go m.writeThread()
+	return nil
+}
+
+// Nimmt die Bereiche rects des Bildes img als neues Bild in die Aufnahme
+// auf. Wird im UI-Thread aufgerufen, nachdem das Bild an das Backend
+// uebermittelt wurde.
+func (m *movieRecorder) capture(img image.Image, rects []image.Rectangle,
+	t time.Time) {
+	if m.stopped.Load() || len(rects) == 0 {
+		return
+	}
+	bounds := image.Rectangle{}
+	for _, r := range rects {
+		r = r.Intersect(m.canvas.Bounds())
+		draw.Draw(m.canvas, r, img, r.Min, draw.Src)
+		bounds = bounds.Union(r)
+	}
+	if bounds.Empty() {
+		return
+	}
+	// Die Bereiche verworfener Bilder werden mit dem naechsten Bild
+	// nachgeliefert.
+	bounds = bounds.Union(m.pending)
+	if m.opts.Format == MoviePNG {
+		bounds = m.canvas.Bounds()
+	}
+	frame := image.NewRGBA(bounds)
+	draw.Draw(frame, bounds, m.canvas, bounds.Min, draw.Src)
+	select {
+	case m.frameQ <- movieFrame{img: frame, bounds: bounds, time: t}:
+		m.pending = image.Rectangle{}
+	default:
+		m.pending = bounds
+		m.dropped.Add(1)
+	}
+}
+
+// Schreibt die aufgenommenen Bilder, bis die Aufnahme beendet wird.
+func (m *movieRecorder) writeThread() {
+	defer close(m.doneQ)
+	for frame := range m.frameQ {
+		if m.stopped.Load() {
+			continue
+		}
+		if m.start.IsZero() {
+			m.start = frame.time
+		}
+		if frame.time.Sub(m.start) > m.opts.MaxDuration ||
+			m.size >= m.opts.MaxSize {
+			m.finish(frame.time)
+			log.Printf("%s: %v", m.name, ErrMovieLimit)
+			continue
+		}
+		if err := m.write(frame); err != nil {
+			m.err = err
+			m.finish(frame.time)
+		}
+	}
+	m.finish(time.Now())
+}
+
+func (m *movieRecorder) write(frame movieFrame) error {
+	offset := frame.time.Sub(m.start)
+	switch m.opts.Format {
+	case MoviePNG:
+		name := filepath.Join(m.name, fmt.Sprintf("frame%05d.png", m.numFrame))
+		fh, err := os.Create(name)
+		if err != nil {
+			return err
+		}
+		if err := png.Encode(fh, frame.img); err != nil {
+			fh.Close()
+			return err
+		}
+		if fi, err := fh.Stat(); err == nil {
+			m.size += fi.Size()
+		}
+		if err := fh.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(m.indexW, "%05d %d\n", m.numFrame, offset.Milliseconds())
+	case MovieGIF:
+		img := image.NewPaletted(frame.bounds, palette.Plan9)
+		if m.opts.Dither {
+			draw.FloydSteinberg.Draw(img, frame.bounds, frame.img,
+				frame.bounds.Min)
+		} else {
+			draw.Draw(img, frame.bounds, frame.img, frame.bounds.Min,
+				draw.Src)
+		}
+		if err := m.gif.add(img, frame.time); err != nil {
+			return err
+		}
+		m.size = m.gif.size
+	}
+	m.numFrame++
+	return nil
+}
+
+// Schliesst die Aufnahme ab. Beim animierten GIF bleibt das letzte Bild
+// bis zum Zeitpunkt end stehen. Weitere Aufrufe haben keine Wirkung.
+func (m *movieRecorder) finish(end time.Time) {
+	if m.stopped.Swap(true) {
+		return
+	}
+	switch m.opts.Format {
+	case MoviePNG:
+		if err := m.indexW.Flush(); err != nil && m.err == nil {
+			m.err = err
+		}
+		if err := m.index.Close(); err != nil && m.err == nil {
+			m.err = err
+		}
+	case MovieGIF:
+		if err := m.gif.close(end); err != nil && m.err == nil {
+			m.err = err
+		}
+	}
+}
+
+// Mit gifWriter wird ein animiertes GIF Bild fuer Bild geschrieben (das
+// Package image/gif kann nur alle Bilder auf einmal schreiben). Da die
+// Verzoegerung eines Bildes erst mit dem Zeitpunkt des naechsten Bildes
+// bekannt ist, wird jeweils ein Bild zurueckbehalten. Alle Bilder
+// verwenden die globale Palette p.
+type gifWriter struct {
+	fh            *os.File
+	w             *bufio.Writer
+	width, height int
+	p             color.Palette
+	last          *image.Paletted
+	lastTime      time.Time
+	started       bool
+	size          int64
+	err           error
+}
+
+func newGIFWriter(fh *os.File, width, height int,
+	p color.Palette) *gifWriter {
+	return &gifWriter{fh: fh, w: bufio.NewWriter(fh), width: width,
+		height: height, p: p}
+}
+
+// Nimmt das Bild img mit dem Zeitpunkt t auf. Geschrieben wird das vorher
+// aufgenommene Bild.
+func (g *gifWriter) add(img *image.Paletted, t time.Time) error {
+	if g.last != nil {
+		g.writeFrame(g.last, t.Sub(g.lastTime))
+	}
+	g.last, g.lastTime = img, t
+	return g.err
+}
+
+// Schreibt das letzte Bild (mit Anzeige bis zum Zeitpunkt end) sowie den
+// Abschluss der Datei und schliesst diese. Wurde kein Bild aufgenommen,
+// bleibt die Datei leer.
+func (g *gifWriter) close(end time.Time) error {
+	if g.last != nil {
+		g.writeFrame(g.last, end.Sub(g.lastTime))
+		g.last = nil
+	}
+	if g.started {
+		g.writeBytes(0x3b)
+	}
+	if err := g.w.Flush(); err != nil && g.err == nil {
+		g.err = err
+	}
+	if err := g.fh.Close(); err != nil && g.err == nil {
+		g.err = err
+	}
+	return g.err
+}
+
+// Schreibt den Kopf der Datei: Groesse, globale Palette und die Angabe,
+// dass die Animation endlos wiederholt wird.
+func (g *gifWriter) writeHeader() {
+	g.writeBytes([]byte("GIF89a")...)
+	g.writeUint16(g.width, g.height)
+	// Globale Palette mit 256 Eintraegen, 8 Bit pro Farbe.
+	g.writeBytes(0xf7, 0x00, 0x00)
+	for i := 0; i < 256; i++ {
+		var r, gr, b uint32
+		if i < len(g.p) {
+			r, gr, b, _ = g.p[i].RGBA()
+		}
+		g.writeBytes(byte(r>>8), byte(gr>>8), byte(b>>8))
+	}
+	g.writeBytes(0x21, 0xff, 0x0b)
+	g.writeBytes([]byte("NETSCAPE2.0")...)
+	g.writeBytes(0x03, 0x01, 0x00, 0x00, 0x00)
+}
+
+// Schreibt das Bild img, welches waehrend d angezeigt wird.
+func (g *gifWriter) writeFrame(img *image.Paletted, d time.Duration) {
+	if !g.started {
+		g.writeHeader()
+		g.started = true
+	}
+	// Die Verzoegerungen werden in 1/100 s angegeben.
+	delay := max(int(d/(10*time.Millisecond)), 1)
+	b := img.Bounds()
+	// Graphic Control Extension mit Disposal 'none', gefolgt vom
+	// Image Descriptor (ohne lokale Palette).
+	g.writeBytes(0x21, 0xf9, 0x04, byte(gif.DisposalNone<<2))
+	g.writeUint16(min(delay, 0xffff))
+	g.writeBytes(0x00, 0x00, 0x2c)
+	g.writeUint16(b.Min.X, b.Min.Y, b.Dx(), b.Dy())
+	g.writeBytes(0x00, 0x08)
+
+	bw := &gifBlockWriter{w: g}
+	lw := lzw.NewWriter(bw, lzw.LSB, 8)
+	for y := b.Min.Y; y < b.Max.Y && g.err == nil; y++ {
+		i := img.PixOffset(b.Min.X, y)
+		if _, err := lw.Write(img.Pix[i : i+b.Dx()]); err != nil {
+			g.err = err
+		}
+	}
+	if err := lw.Close(); err != nil && g.err == nil {
+		g.err = err
+	}
+	bw.flush()
+	g.writeBytes(0x00)
+}
+
+func (g *gifWriter) writeBytes(b ...byte) {
+	if g.err != nil {
+		return
+	}
+	n, err := g.w.Write(b)
+	g.size += int64(n)
+	g.err = err
+}
+
+func (g *gifWriter) writeUint16(vals ...int) {
+	for _, v := range vals {
+		g.writeBytes(byte(v), byte(v>>8))
+	}
+}
+
+// Die komprimierten Bilddaten werden in Bloecken von hoechstens 255 Bytes
+// abgelegt, welchen jeweils ihre Laenge vorangestellt wird.
+type gifBlockWriter struct {
+	w   *gifWriter
+	buf [255]byte
+	n   int
+}
+
+func (bw *gifBlockWriter) Write(p []byte) (int, error) {
+	for _, c := range p {
+		bw.buf[bw.n] = c
+		bw.n++
+		if bw.n == len(bw.buf) {
+			bw.flush()
+		}
+	}
+	return len(p), bw.w.err
+}
+
+func (bw *gifBlockWriter) flush() {
+	if bw.n == 0 {
+		return
+	}
+	bw.w.writeBytes(byte(bw.n))
+	bw.w.writeBytes(bw.buf[:bw.n]...)
+	bw.n = 0
+}
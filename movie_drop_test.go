@@ -0,0 +1,37 @@
+package adagui
+
+import (
+	"image"
+	"testing"
+	"time"
+)
+
+func TestMovieDropsFrames(t *testing.T) {
+	m := &movieRecorder{
+		opts:   MovieOptions{Format: MovieGIF},
+		canvas: image.NewRGBA(image.Rect(0, 0, 100, 100)),
+		frameQ: make(chan movieFrame, 1),
+	}
+	img := image.NewRGBA(m.canvas.Bounds())
+	rects := []image.Rectangle{
+		image.Rect(0, 0, 10, 10),
+		image.Rect(20, 20, 30, 30),
+		image.Rect(50, 50, 60, 60),
+	}
+	now := time.Now()
+	for _, r := range rects {
+		m.capture(img, []image.Rectangle{r}, now)
+	}
+	if n := m.dropped.Load(); n != 2 {
+		t.Fatalf("got %d dropped frames, want 2", n)
+	}
+	<-m.frameQ
+	m.capture(img, []image.Rectangle{image.Rect(90, 90, 100, 100)}, now)
+	frame := <-m.frameQ
+	if want := image.Rect(20, 20, 100, 100); frame.bounds != want {
+		t.Errorf("got bounds %v, want %v", frame.bounds, want)
+	}
+	if !m.pending.Empty() {
+		t.Errorf("pending region %v not cleared", m.pending)
+	}
+}
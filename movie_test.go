@@ -0,0 +1,126 @@
+package adagui_test
+
+import (
+	"bufio"
+	"errors"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+)
+
+// Nimmt einige Taps auf den Button btn als Film im Verzeichnis, resp. in
+// der Datei name auf.
+func recordTaps(t *testing.T, h *adaguitest.Harness, btn adagui.Node,
+	name string, opts adagui.MovieOptions) {
+	t.Helper()
+	if err := h.Screen.StartMovie(name, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Screen.StartMovie(name, opts); !errors.Is(err, adagui.ErrMovieRunning) {
+		t.Errorf("second StartMovie: got %v, want ErrMovieRunning", err)
+	}
+	h.WaitIdle()
+	for i := 0; i < 3; i++ {
+		h.Tap(h.Center(btn))
+		h.WaitIdle()
+	}
+	if err := h.Screen.StopMovie(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Screen.StopMovie(); !errors.Is(err, adagui.ErrNoMovie) {
+		t.Errorf("second StopMovie: got %v, want ErrNoMovie", err)
+	}
+}
+
+func TestMoviePNG(t *testing.T) {
+	btn := adagui.NewButton(60, 40)
+	h := adaguitest.New(t, centered(btn))
+	dir := filepath.Join(t.TempDir(), "movie")
+	recordTaps(t, h, btn, dir, adagui.MovieOptions{})
+
+	files, err := filepath.Glob(filepath.Join(dir, "frame*.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) < 2 {
+		t.Fatalf("got %d frames, want at least 2", len(files))
+	}
+	fh, err := os.Open(filepath.Join(dir, "frames.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fh.Close()
+	lines := 0
+	for sc := bufio.NewScanner(fh); sc.Scan(); {
+		lines++
+	}
+	if lines != len(files) {
+		t.Errorf("got %d timestamps for %d frames", lines, len(files))
+	}
+}
+
+func TestMovieGIF(t *testing.T) {
+	btn := adagui.NewButton(60, 40)
+	h := adaguitest.New(t, centered(btn))
+	name := filepath.Join(t.TempDir(), "movie.gif")
+	recordTaps(t, h, btn, name, adagui.MovieOptions{Dither: true})
+
+	fh, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fh.Close()
+	anim, err := gif.DecodeAll(fh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) < 2 {
+		t.Fatalf("got %d frames, want at least 2", len(anim.Image))
+	}
+	width, height := h.Screen.Size()
+	if anim.Config.Width != width || anim.Config.Height != height {
+		t.Errorf("got size %dx%d, want %dx%d", anim.Config.Width,
+			anim.Config.Height, width, height)
+	}
+	if b := anim.Image[0].Bounds(); b.Dx() != width || b.Dy() != height {
+		t.Errorf("first frame covers %v, want the whole screen", b)
+	}
+	for i, d := range anim.Delay {
+		if d < 1 {
+			t.Errorf("frame %d has delay %d", i, d)
+		}
+	}
+}
+
+func TestMovieLimit(t *testing.T) {
+	btn := adagui.NewButton(60, 40)
+	h := adaguitest.New(t, centered(btn))
+	dir := filepath.Join(t.TempDir(), "movie")
+	err := h.Screen.StartMovie(dir, adagui.MovieOptions{MaxSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3 && h.Screen.MovieRunning(); i++ {
+		h.Tap(h.Center(btn))
+		h.WaitIdle()
+	}
+	deadline := time.Now().Add(time.Second)
+	for h.Screen.MovieRunning() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if h.Screen.MovieRunning() {
+		t.Errorf("recording still running after reaching the size limit")
+	}
+	if err := h.Screen.StopMovie(); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "frame*.png"))
+	if len(files) != 1 {
+		t.Errorf("got %d frames, want 1", len(files))
+	}
+}
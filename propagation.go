@@ -0,0 +1,65 @@
+package adagui
+
+import (
+	"github.com/stefan-muehlebach/adagui/touch"
+)
+
+// Stellt das Event evt (mit Positionen in Bildschirmkoordinaten) dem Ziel-
+// Node target zu. Dabei durchlaeuft das Event zuerst alle Container von der
+// Wurzel bis zum Parent von target (Capture-Phase), dann target selber
+// (Target-Phase) und schliesslich alle Container in umgekehrter Reihenfolge
+// (Bubble-Phase). Beansprucht ein Node die Geste, wird er retourniert,
+// andernfalls nil. Muss im UI-Thread aufgerufen werden.
+func (w *Window) dispatch(target Node, evt touch.Event) Node {
+	prop := &touch.Propagation{}
+	evt.Propagation = prop
+	path := ancestors(target)
+
+	for _, n := range path {
+		if w.deliver(n, evt, touch.PhaseCapture); prop.Stopped() {
+			return claimedBy(n, prop)
+		}
+	}
+	if w.deliver(target, evt, touch.PhaseTarget); prop.Stopped() {
+		return claimedBy(target, prop)
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		if w.deliver(path[i], evt, touch.PhaseBubble); prop.Stopped() {
+			return claimedBy(path[i], prop)
+		}
+	}
+	return nil
+}
+
+// Stellt das Event evt dem Node n in der Phase phase zu. Die Positionen
+// werden dazu in lokale Koordinaten von n umgerechnet. Inaktive Nodes
+// (siehe Embed.Enabled) werden uebersprungen.
+func (w *Window) deliver(n Node, evt touch.Event, phase touch.Phase) {
+	if !n.Wrappee().Enabled() {
+		return
+	}
+	evt.Phase = phase
+	evt.InitPos = n.Screen2Local(evt.InitPos)
+	evt.Pos = n.Screen2Local(evt.Pos)
+	Debugf(Events, "%v phase: %T, pos: %v", phase, n, evt.Pos)
+	n.OnInputEvent(evt)
+}
+
+func claimedBy(n Node, prop *touch.Propagation) Node {
+	if prop.Claimed() {
+		return n
+	}
+	return nil
+}
+
+// Liefert alle Container oberhalb von n, beginnend bei der Wurzel.
+func ancestors(n Node) []Node {
+	var path []Node
+	for p := n.Wrappee().Parent; p != nil; p = p.Parent {
+		path = append(path, p.Wrapper)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
@@ -0,0 +1,117 @@
+package adagui_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+	"github.com/stefan-muehlebach/adagui/touch"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+// Liefert einen Handler, welcher das Press-Event mit dem Namen name und der
+// Phase in log protokolliert.
+func logPhase(log *[]string, name string) touch.TouchFunction {
+	return func(evt touch.Event) {
+		*log = append(*log, fmt.Sprintf("%s:%v", name, evt.Phase))
+	}
+}
+
+// Baut den SceneGraph Group > Panel > Button auf.
+func nestedButton() (*adagui.Group, *adagui.Panel, *adagui.Button) {
+	btn := adagui.NewButton(60, 40)
+	pnl := adagui.NewPanel(200, 150)
+	pnl.Add(btn)
+	btn.SetPos(geom.Point{X: 70, Y: 55})
+	return centered(pnl), pnl, btn
+}
+
+func TestEventPhases(t *testing.T) {
+	grp, pnl, btn := nestedButton()
+	var log []string
+	grp.SetCaptureFunc(logPhase(&log, "group"), touch.TypePress)
+	grp.SetBubbleFunc(logPhase(&log, "group"), touch.TypePress)
+	pnl.SetCaptureFunc(logPhase(&log, "panel"), touch.TypePress)
+	pnl.SetBubbleFunc(logPhase(&log, "panel"), touch.TypePress)
+	// Die Handler von SetTouchFunc erhalten die Events der Kinder nicht.
+	pnl.SetOnPress(logPhase(&log, "own"))
+	btn.SetOnPress(logPhase(&log, "button"))
+	h := adaguitest.New(t, grp)
+
+	h.Tap(h.Center(btn))
+	want := []string{"group:Capture", "panel:Capture", "button:Target",
+		"panel:Bubble", "group:Bubble"}
+	if fmt.Sprint(log) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", log, want)
+	}
+}
+
+func TestStopPropagation(t *testing.T) {
+	grp, pnl, btn := nestedButton()
+	var log []string
+	pnl.SetOnTap(logPhase(&log, "panel"))
+	pnl.SetBubbleFunc(logPhase(&log, "panel"), touch.TypeTap)
+	btn.SetOnTap(func(evt touch.Event) {
+		log = append(log, "button")
+		evt.StopPropagation()
+	})
+	h := adaguitest.New(t, grp)
+
+	h.Tap(h.Center(btn))
+	if fmt.Sprint(log) != "[button]" {
+		t.Errorf("got %v, want only the button", log)
+	}
+	log = nil
+	h.Tap(h.Center(pnl).AddXY(0, 60))
+	if fmt.Sprint(log) != "[panel:Target]" {
+		t.Errorf("got %v for a tap beside the button", log)
+	}
+}
+
+// Beginnt eine Bewegung auf einem Button, beansprucht das ScrollPanel die
+// Geste, sobald der Finger die Schwelle fuer einen Drag ueberschreitet.
+func TestScrollPanelDrag(t *testing.T) {
+	btn := adagui.NewTextButton("Scroll")
+	sp := adagui.NewScrollPanel(200, 150)
+	sp.Add(btn)
+	btn.SetPos(geom.Point{X: 50, Y: 50})
+	btnRec := adaguitest.NewRecorder()
+	btn.SetTouchFunc(btnRec.Record, touch.TypeLeave, touch.TypeRelease,
+		touch.TypeTap)
+	spRec := adaguitest.NewRecorder()
+	sp.SetTouchFunc(spRec.Record, touch.TypeDrag, touch.TypeRelease)
+	h := adaguitest.New(t, centered(sp))
+	h.Do(func() { sp.SetVirtualSize(geom.Point{X: 200, Y: 400}) })
+
+	c := h.Center(btn)
+	h.Press(c)
+	h.Drag(c.AddXY(0, -5))
+	h.WaitIdle()
+	if !btn.Pushed() {
+		t.Fatalf("button not pushed below the drag threshold")
+	}
+	h.Drag(c.AddXY(0, -20))
+	h.Drag(c.AddXY(0, -40))
+	h.Release(c.AddXY(0, -40))
+	h.WaitIdle()
+
+	if btn.Pushed() {
+		t.Errorf("button still pushed after the gesture was claimed")
+	}
+	if got := btnRec.Types(); fmt.Sprint(got) != "[Leave]" {
+		t.Errorf("button got %v, want only Leave", got)
+	}
+	var view geom.Point
+	h.Do(func() { view = sp.ViewPort() })
+	if !view.Eq(geom.Point{X: 0, Y: 40}) {
+		t.Errorf("view port at %v, want (0, 40)", view)
+	}
+	// Der beanspruchende Drag wird in der Capture-Phase verarbeitet, alle
+	// weiteren Events gehen direkt an das ScrollPanel.
+	got := spRec.Events()
+	if len(got) != 2 || got[0].Phase != touch.PhaseTarget ||
+		got[1].Type != touch.TypeRelease {
+		t.Errorf("scroll panel got %v, want Drag and Release", spRec.Types())
+	}
+}
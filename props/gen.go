@@ -38,6 +38,31 @@ func (pe *PropertyEmbed) Set{{ .Name }}(s float64) {
 }
 `
 
+const gradientPropTempl = `
+func (pe *PropertyEmbed) {{ .Name }}() (*Gradient) {
+    return pe.prop.Gradient({{ .Name }})
+}
+func (pe *PropertyEmbed) Set{{ .Name }}(g *Gradient) {
+    pe.prop.SetGradient({{ .Name }}, g)
+}
+`
+const imagePropTempl = `
+func (pe *PropertyEmbed) {{ .Name }}() (*NineSlice) {
+    return pe.prop.Image({{ .Name }})
+}
+func (pe *PropertyEmbed) Set{{ .Name }}(i *NineSlice) {
+    pe.prop.SetImage({{ .Name }}, i)
+}
+`
+const shadowPropTempl = `
+func (pe *PropertyEmbed) {{ .Name }}() (*Shadow) {
+    return pe.prop.Shadow({{ .Name }})
+}
+func (pe *PropertyEmbed) Set{{ .Name }}(s *Shadow) {
+    pe.prop.SetShadow({{ .Name }}, s)
+}
+`
+
 type ColorPropInfo struct {
 	Templ *template.Template
 	List  []props.ColorPropertyName
@@ -51,6 +76,19 @@ type SizePropInfo struct {
 	List  []props.SizePropertyName
 }
 
+type GradientPropInfo struct {
+	Templ *template.Template
+	List  []props.GradientPropertyName
+}
+type ImagePropInfo struct {
+	Templ *template.Template
+	List  []props.ImagePropertyName
+}
+type ShadowPropInfo struct {
+	Templ *template.Template
+	List  []props.ShadowPropertyName
+}
+
 type PropName struct {
 	Name string
 }
@@ -80,11 +118,20 @@ type PropertyEmbed struct {
     prop *Properties
 }
 
+// Erzeugt die Properties des Objektes auf Objektebene. Sie gehoeren
+// zunaechst pe (siehe Properties.Owner).
 func (pe *PropertyEmbed) Init(parent *Properties) {
     pe.prop = NewProperties(parent)
+    pe.prop.owner = pe
 }
 func (pe *PropertyEmbed) InitByName(name string) {
-    pe.prop = NewProperties(PropsMap[name])
+    pe.Init(PropsMap[name])
+}
+
+// Liefert die Properties des Objektes. Ueber deren Parents sind sie mit
+// den Properties des Typs verbunden (siehe Properties.Inherits).
+func (pe *PropertyEmbed) Properties() (*Properties) {
+    return pe.prop
 }
 
 `)
@@ -101,6 +148,18 @@ func (pe *PropertyEmbed) InitByName(name string) {
 		Templ: template.Must(template.New("size").Parse(sizePropTempl)),
 		List:  props.EmbedSizeProps,
 	}
+	gradientProps := GradientPropInfo{
+		Templ: template.Must(template.New("gradient").Parse(gradientPropTempl)),
+		List:  props.EmbedGradientProps,
+	}
+	imageProps := ImagePropInfo{
+		Templ: template.Must(template.New("image").Parse(imagePropTempl)),
+		List:  props.EmbedImageProps,
+	}
+	shadowProps := ShadowPropInfo{
+		Templ: template.Must(template.New("shadow").Parse(shadowPropTempl)),
+		List:  props.EmbedShadowProps,
+	}
 
 	for _, pr := range colorProps.List {
 		pn := PropName{Name: pr.String()}
@@ -120,4 +179,22 @@ func (pe *PropertyEmbed) InitByName(name string) {
 			log.Fatalf("Unable to write file: %v", err)
 		}
 	}
+	for _, pr := range gradientProps.List {
+		pn := PropName{Name: pr.String()}
+		if err := gradientProps.Templ.Execute(propFile, pn); err != nil {
+			log.Fatalf("Unable to write file: %v", err)
+		}
+	}
+	for _, pr := range imageProps.List {
+		pn := PropName{Name: pr.String()}
+		if err := imageProps.Templ.Execute(propFile, pn); err != nil {
+			log.Fatalf("Unable to write file: %v", err)
+		}
+	}
+	for _, pr := range shadowProps.List {
+		pn := PropName{Name: pr.String()}
+		if err := shadowProps.Templ.Execute(propFile, pn); err != nil {
+			log.Fatalf("Unable to write file: %v", err)
+		}
+	}
 }
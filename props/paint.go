@@ -0,0 +1,382 @@
+package props
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"os"
+
+	"github.com/stefan-muehlebach/gg/colors"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+// Neben Farben, Schriften und Groessen kennen die Properties drei weitere
+// Arten von Eigenschaften, mit welchen die Flaechen von Widgets gestaltet
+// werden koennen: Farbverlaeufe, Bilder (mit Nine-Slice-Raendern) und
+// Schatten. Im Gegensatz zu den Farben muessen sie in den Default-Properties
+// nicht definiert sein; fehlt ein Eintrag, wird nil geliefert und die
+// Widgets verwenden die entsprechende Farbe.
+
+type GradientPropertyName int
+
+const (
+	FillGradient GradientPropertyName = iota
+	PushedFillGradient
+	SelectedFillGradient
+	NumGradientProperties
+)
+
+var (
+	GradientPropertyList = []string{
+		"FillGradient",
+		"PushedFillGradient",
+		"SelectedFillGradient",
+	}
+)
+
+func (p GradientPropertyName) String() string {
+	return GradientPropertyList[p]
+}
+
+func (p GradientPropertyName) MarshalText() ([]byte, error) {
+	return []byte(GradientPropertyList[p]), nil
+}
+
+func (p *GradientPropertyName) UnmarshalText(text []byte) error {
+	txt := string(text)
+	if txt[0] == '_' {
+		return nil
+	}
+	for i, t := range GradientPropertyList {
+		if t == txt {
+			*p = GradientPropertyName(i)
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf("Gradient property '%s' in file but not in the prop list", txt))
+}
+
+var (
+	EmbedGradientProps = []GradientPropertyName{
+		FillGradient,
+		PushedFillGradient,
+		SelectedFillGradient,
+	}
+)
+
+type ImagePropertyName int
+
+const (
+	BackgroundImage ImagePropertyName = iota
+	PushedBackgroundImage
+	SelectedBackgroundImage
+	NumImageProperties
+)
+
+var (
+	ImagePropertyList = []string{
+		"BackgroundImage",
+		"PushedBackgroundImage",
+		"SelectedBackgroundImage",
+	}
+)
+
+func (p ImagePropertyName) String() string {
+	return ImagePropertyList[p]
+}
+
+func (p ImagePropertyName) MarshalText() ([]byte, error) {
+	return []byte(ImagePropertyList[p]), nil
+}
+
+func (p *ImagePropertyName) UnmarshalText(text []byte) error {
+	txt := string(text)
+	if txt[0] == '_' {
+		return nil
+	}
+	for i, t := range ImagePropertyList {
+		if t == txt {
+			*p = ImagePropertyName(i)
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf("Image property '%s' in file but not in the prop list", txt))
+}
+
+var (
+	EmbedImageProps = []ImagePropertyName{
+		BackgroundImage,
+		PushedBackgroundImage,
+		SelectedBackgroundImage,
+	}
+)
+
+type ShadowPropertyName int
+
+const (
+	DropShadow ShadowPropertyName = iota
+	PushedDropShadow
+	NumShadowProperties
+)
+
+var (
+	ShadowPropertyList = []string{
+		"DropShadow",
+		"PushedDropShadow",
+	}
+)
+
+func (p ShadowPropertyName) String() string {
+	return ShadowPropertyList[p]
+}
+
+func (p ShadowPropertyName) MarshalText() ([]byte, error) {
+	return []byte(ShadowPropertyList[p]), nil
+}
+
+func (p *ShadowPropertyName) UnmarshalText(text []byte) error {
+	txt := string(text)
+	if txt[0] == '_' {
+		return nil
+	}
+	for i, t := range ShadowPropertyList {
+		if t == txt {
+			*p = ShadowPropertyName(i)
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf("Shadow property '%s' in file but not in the prop list", txt))
+}
+
+var (
+	EmbedShadowProps = []ShadowPropertyName{
+		DropShadow,
+		PushedDropShadow,
+	}
+)
+
+// ----------------------------------------------------------------------------
+
+// Art des Farbverlaufs.
+type GradientType int
+
+const (
+	LinearGradient GradientType = iota
+	RadialGradient
+)
+
+func (t GradientType) MarshalText() ([]byte, error) {
+	if t == RadialGradient {
+		return []byte("Radial"), nil
+	}
+	return []byte("Linear"), nil
+}
+
+func (t *GradientType) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "Linear":
+		*t = LinearGradient
+	case "Radial":
+		*t = RadialGradient
+	default:
+		return fmt.Errorf("unknown gradient type '%s'", text)
+	}
+	return nil
+}
+
+// Ein Farbwert innerhalb eines Farbverlaufs. Offset liegt zwischen 0.0
+// (Anfang) und 1.0 (Ende des Verlaufs).
+type ColorStop struct {
+	Offset float64
+	Color  colors.RGBA
+}
+
+// Ein linearer oder radialer Farbverlauf. Alle Koordinaten sind relativ
+// zur Groesse des Widgets, (0,0) ist die linke obere und (1,1) die rechte
+// untere Ecke. Lineare Verlaeufe fuehren von From nach To (Default: von oben
+// nach unten), radiale Verlaeufe von Center nach aussen bis zum Radius
+// Radius, welcher relativ zur groesseren Seite des Widgets ist (Default:
+// Mitte, 0.5).
+//
+// Im JSON-File sieht ein Farbverlauf bspw. so aus:
+//
+//	"FillGradient": {
+//	    "Type": "Linear", "From": {"X": 0, "Y": 0}, "To": {"X": 0, "Y": 1},
+//	    "Stops": [{"Offset": 0, "Color": {"Name": "Teal"}},
+//	              {"Offset": 1, "Color": "0x000019"}]
+//	}
+//
+// Die Farben der Stops werden wie die uebrigen Farben angegeben.
+type Gradient struct {
+	Type     GradientType
+	From, To geom.Point
+	Center   geom.Point
+	Radius   float64
+	Stops    []ColorStop
+}
+
+// Abstaende vom Rand eines Bildes (in Pixeln), welche bei Nine-Slice-Bildern
+// nicht gestreckt werden.
+type Insets struct {
+	Top, Right, Bottom, Left float64
+}
+
+// Ein Bild, welches als Hintergrund eines Widgets auf dessen Groesse
+// gestreckt wird. Sind Insets angegeben, werden Ecken und Raender nicht
+// (resp. nur in einer Richtung) gestreckt, sondern nur der mittlere Teil
+// (Nine-Slice). Im JSON-File wird nur der Dateiname angegeben, das Bild
+// (PNG oder JPEG) wird beim Einlesen geladen:
+//
+//	"BackgroundImage": {
+//	    "File": "panel.png", "Insets": {"Top": 8, "Right": 8, "Bottom": 8, "Left": 8}
+//	}
+type NineSlice struct {
+	File   string
+	Insets Insets
+	Image  image.Image `json:"-"`
+}
+
+// Erzeugt ein Nine-Slice-Bild aus img mit den Raendern insets.
+func NewNineSlice(img image.Image, insets Insets) *NineSlice {
+	return &NineSlice{Insets: insets, Image: img}
+}
+
+// Ein Schatten, welcher um Offset verschoben unter das Widget gezeichnet
+// wird. Mit Blur wird die Breite des weichen Randes angegeben.
+//
+//	"DropShadow": {
+//	    "Offset": {"X": 2, "Y": 3}, "Blur": 4,
+//	    "Color": {"Name": "Black", "Alpha": 0.5}
+//	}
+type Shadow struct {
+	Offset geom.Point
+	Blur   float64
+	Color  colors.RGBA
+}
+
+// Diese Fehler werden (wie die Fehler bei den Farben) beim Einlesen der
+// Properties retourniert, ergaenzt um den Namen des Properties.
+var (
+	// Das Bild konnte nicht gelesen werden. Das Property wird ohne Bild
+	// erzeugt.
+	ErrImageNotFound = errors.New("image not found")
+	// Der Farbverlauf, resp. der Schatten ist ungueltig und wird ignoriert.
+	ErrInvalidGradient = errors.New("invalid gradient")
+	ErrInvalidShadow   = errors.New("invalid shadow")
+)
+
+// Liest eine Farbe, welche entweder als Name (mit Dark, Bright und Alpha)
+// oder als RGBA-Wert angegeben ist. Im Fehlerfall wird FallbackColor
+// retourniert.
+func parseColor(data json.RawMessage) (colors.RGBA, error) {
+	namedCol := namedColor{Alpha: 1.0}
+	rgbaCol := colors.RGBA{}
+
+	err := json.Unmarshal(data, &namedCol)
+	if err == nil {
+		if col, ok := colors.Map[namedCol.Name]; ok {
+			return col.Dark(namedCol.Dark).Bright(namedCol.Bright).Alpha(namedCol.Alpha), nil
+		}
+		return FallbackColor, fmt.Errorf("%w: '%s'", ErrColorNotFound,
+			namedCol.Name)
+	}
+	switch err.(type) {
+	case *json.UnmarshalTypeError:
+	default:
+		log.Printf("[2]: failed unmarshaling data: %v", err)
+	}
+
+	err = json.Unmarshal(data, &rgbaCol)
+	if err == nil {
+		return rgbaCol, nil
+	}
+	return FallbackColor, fmt.Errorf("%w: %s (%v)", ErrInvalidColor, data, err)
+}
+
+// Liest einen Farbverlauf. Mit null wird ein geerbter Verlauf ausgeschaltet.
+func parseGradient(data json.RawMessage) (*Gradient, error) {
+	var entry struct {
+		Gradient
+		Stops []struct {
+			Offset float64
+			Color  json.RawMessage
+		}
+	}
+	var errList []error
+
+	if string(data) == "null" {
+		return nil, nil
+	}
+	entry.To = geom.Point{X: 0, Y: 1}
+	entry.Center = geom.Point{X: 0.5, Y: 0.5}
+	entry.Radius = 0.5
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("%w: %s (%v)", ErrInvalidGradient, data, err)
+	}
+	if len(entry.Stops) == 0 {
+		return nil, fmt.Errorf("%w: %s (no color stops)", ErrInvalidGradient,
+			data)
+	}
+	g := entry.Gradient
+	g.Stops = make([]ColorStop, len(entry.Stops))
+	for i, stop := range entry.Stops {
+		col, err := parseColor(stop.Color)
+		if err != nil {
+			errList = append(errList, err)
+		}
+		g.Stops[i] = ColorStop{Offset: stop.Offset, Color: col}
+	}
+	return &g, errors.Join(errList...)
+}
+
+// Liest die Angaben zu einem Bild und laedt dieses. Gesucht wird zuerst
+// eine eingebettete, dann eine lokale Datei.
+func parseNineSlice(data json.RawMessage) (*NineSlice, error) {
+	var ns NineSlice
+
+	if string(data) == "null" {
+		return nil, nil
+	}
+	if err := json.Unmarshal(data, &ns); err != nil {
+		return nil, fmt.Errorf("%w: %s (%v)", ErrImageNotFound, data, err)
+	}
+	fh, err := propFiles.Open(ns.File)
+	if err != nil {
+		fh, err = os.Open(ns.File)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: '%s'", ErrImageNotFound, ns.File)
+	}
+	defer fh.Close()
+	if ns.Image, _, err = image.Decode(fh); err != nil {
+		return nil, fmt.Errorf("%w: '%s' (%v)", ErrImageNotFound, ns.File, err)
+	}
+	return &ns, nil
+}
+
+// Liest einen Schatten. Mit null wird ein geerbter Schatten ausgeschaltet.
+func parseShadow(data json.RawMessage) (*Shadow, error) {
+	var entry struct {
+		Shadow
+		Color json.RawMessage
+	}
+
+	if string(data) == "null" {
+		return nil, nil
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("%w: %s (%v)", ErrInvalidShadow, data, err)
+	}
+	sh := entry.Shadow
+	sh.Color = colors.Black.Alpha(0.5)
+	if entry.Color == nil {
+		return &sh, nil
+	}
+	col, err := parseColor(entry.Color)
+	sh.Color = col
+	return &sh, err
+}
@@ -10,6 +10,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 
 	"github.com/stefan-muehlebach/gg/colors"
 	"github.com/stefan-muehlebach/gg/fonts"
@@ -37,6 +39,10 @@ const (
 	PushedBarColor
 	BackgroundColor
 	MenuBackgroundColor
+	FocusColor
+	DisabledColor
+	DisabledBorderColor
+	DisabledTextColor
 	NumColorProperties
 )
 
@@ -58,6 +64,10 @@ var (
 		"PushedBarColor",
 		"BackgroundColor",
 		"MenuBackgroundColor",
+		"FocusColor",
+		"DisabledColor",
+		"DisabledBorderColor",
+		"DisabledTextColor",
 	}
 )
 
@@ -101,6 +111,10 @@ var (
 		PushedBarColor,
 		BackgroundColor,
 		MenuBackgroundColor,
+		FocusColor,
+		DisabledColor,
+		DisabledBorderColor,
+		DisabledTextColor,
 	}
 )
 
@@ -174,6 +188,7 @@ const (
 	BarSize
 	CtrlSize
 	FieldSize
+	FocusWidth
 	NumSizeProperties
 )
 
@@ -192,6 +207,7 @@ var (
 		"BarSize",
 		"CtrlSize",
 		"FieldSize",
+		"FocusWidth",
 	}
 )
 
@@ -232,6 +248,7 @@ var (
 		BarSize,
 		CtrlSize,
 		FieldSize,
+		FocusWidth,
 	}
 )
 
@@ -244,13 +261,36 @@ var (
 //   - Schriftarten (Datentyp: *opentype.Font)
 //   - Zahlen (Datentyp: float64).
 //
+// Dazu kommen Farbverlaeufe, Bilder und Schatten (siehe paint.go).
+//
 // Durch die Hierarchie ist es möglich für einzelne Widgets vom Standard
 // abweichende Eigenschaften zu definieren.
 type Properties struct {
 	parent   *Properties
+	owner    any
 	ColorMap map[ColorPropertyName]colors.RGBA
 	FontMap  map[FontPropertyName]*fonts.Font
 	SizeMap  map[SizePropertyName]float64
+
+	GradientMap map[GradientPropertyName]*Gradient
+	ImageMap    map[ImagePropertyName]*NineSlice
+	ShadowMap   map[ShadowPropertyName]*Shadow
+
+	// Generation der letzten Aenderung an diesen Properties (siehe
+	// changed).
+	gen atomic.Uint64
+
+	// Von den Parents geerbte Werte werden hier zwischengespeichert. Sie
+	// werden verworfen, sobald diese Properties oder einer ihrer Parents
+	// nach cacheGen geaendert wurden (siehe checkCache).
+	cacheGen   uint64
+	colorCache map[ColorPropertyName]colors.RGBA
+	fontCache  map[FontPropertyName]*fonts.Font
+	sizeCache  map[SizePropertyName]float64
+
+	gradientCache map[GradientPropertyName]*Gradient
+	imageCache    map[ImagePropertyName]*NineSlice
+	shadowCache   map[ShadowPropertyName]*Shadow
 }
 
 // Erzeugt ein neues Property-Objekt und hinterlegt parent als Vater-Property.
@@ -261,82 +301,119 @@ func NewProperties(parent *Properties) *Properties {
 	p.ColorMap = make(map[ColorPropertyName]colors.RGBA)
 	p.FontMap = make(map[FontPropertyName]*fonts.Font)
 	p.SizeMap = make(map[SizePropertyName]float64)
+	p.GradientMap = make(map[GradientPropertyName]*Gradient)
+	p.ImageMap = make(map[ImagePropertyName]*NineSlice)
+	p.ShadowMap = make(map[ShadowPropertyName]*Shadow)
 
 	return p
 }
 
+// Diese Fehler werden beim Einlesen der Properties retourniert, ergaenzt
+// um den Namen des Properties. Mit errors.Is kann geprueft werden, um
+// welchen Fehler es sich handelt.
+var (
+	// Der Name einer Farbe ist in colors.Map nicht vorhanden. Anstelle der
+	// Farbe wird FallbackColor verwendet.
+	ErrColorNotFound = errors.New("color not found")
+	// Die Farbe ist weder ein Farbname noch ein RGBA-Wert. Auch hier wird
+	// FallbackColor verwendet.
+	ErrInvalidColor = errors.New("invalid color")
+	// Das Parent-Property ist (noch) nicht definiert. Das Property wird ohne
+	// Parent erzeugt.
+	ErrParentNotFound = errors.New("parent property not found")
+	// Zum Namen eines Themes existiert weder eine eingebettete noch eine
+	// lokale Datei (siehe SetTheme).
+	ErrThemeNotFound = errors.New("theme not found")
+)
+
+var (
+	// Diese Farbe wird fuer alle Farben verwendet, welche nicht eingelesen
+	// werden konnten. Sie ist bewusst auffaellig gewaehlt.
+	FallbackColor = colors.Magenta
+)
+
 // Erzeugt ein neues Property-Objekt mit Daten aus einem JSON-File, welches
 // in diesem Verzeichnis zu finden sein muss.
-func NewPropsMapFromEmbedFile(fileName string) map[string]*Properties {
+func NewPropsMapFromEmbedFile(fileName string) (map[string]*Properties, error) {
 	data, err := propFiles.ReadFile(filepath.Join(fileName))
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	return NewPropsMapFromData(data)
 }
 
 // Erzeugt ein neues Property-Objekt mit Daten aus einem JSON-File, welches
 // vom User zur Verfuegung gestellt wird.
-func NewPropsMapFromUserFile(fileName string) map[string]*Properties {
+func NewPropsMapFromUserFile(fileName string) (map[string]*Properties, error) {
 	data, err := os.ReadFile(fileName)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	return NewPropsMapFromData(data)
 }
 
-// Erzeugt ein neues Property-Objekt mit JSON-Daten aus [data].
-func NewPropsMapFromData(data []byte) map[string]*Properties {
-	var propList []struct {
-		Name       string
-		ParentName string
-		Colors     map[ColorPropertyName]json.RawMessage
-		Fonts      map[FontPropertyName]*fonts.Font
-		Sizes      map[SizePropertyName]float64
-	}
-	var parent *Properties
-	var ok bool
+// Erzeugt ein neues Property-Objekt mit JSON-Daten aus [data]. Sind die
+// Daten kein gueltiges JSON, wird nur der Fehler retourniert. Fehler in
+// einzelnen Properties (unbekannte Farben oder Parents) fuehren dagegen
+// nicht zum Abbruch: die betroffenen Werte werden durch FallbackColor,
+// resp. ein Property ohne Parent ersetzt und die Map wird zusammen mit
+// allen aufgetretenen Fehlern retourniert.
+func NewPropsMapFromData(data []byte) (map[string]*Properties, error) {
+	var propList []propEntry
 
-	propsMap := make(map[string]*Properties)
 	err := json.Unmarshal(data, &propList)
 	if err != nil {
-		log.Fatalf("[1]: failed unmarshaling data: %v", err)
+		return nil, fmt.Errorf("failed unmarshaling data: %w", err)
 	}
+	propsMap := make(map[string]*Properties)
+	return propsMap, addProps(propsMap, propList)
+}
+
+// Ein Eintrag in einem JSON-File mit Properties.
+type propEntry struct {
+	Name       string
+	ParentName string
+	Colors     map[ColorPropertyName]json.RawMessage
+	Fonts      map[FontPropertyName]*fonts.Font
+	Sizes      map[SizePropertyName]float64
+	Gradients  map[GradientPropertyName]json.RawMessage
+	Images     map[ImagePropertyName]json.RawMessage
+	Shadows    map[ShadowPropertyName]json.RawMessage
+}
+
+// Fuegt die Eintraege aus propList der Map propsMap hinzu. Existiert ein
+// Property bereits, dann werden nur die angegebenen Werte (und ggf. der
+// Parent) ueberschrieben, alle uebrigen bleiben erhalten. Farbverlaeufe,
+// Bilder und Schatten, welche nicht gelesen werden konnten, werden nicht
+// eingetragen und weiterhin vom Parent geerbt; nur mit einem expliziten
+// null wird der geerbte Wert ausgeschaltet.
+func addProps(propsMap map[string]*Properties, propList []propEntry) error {
+	var parent *Properties
+	var ok bool
+	var errList []error
+
 	for _, val := range propList {
 		if val.ParentName == "" {
 			parent = nil
 		} else {
 			if parent, ok = propsMap[val.ParentName]; !ok {
-				log.Fatalf("on processing '%s', parent property '%s' not found", val.Name, val.ParentName)
+				errList = append(errList, fmt.Errorf("%s: %w: '%s'",
+					val.Name, ErrParentNotFound, val.ParentName))
 			}
 		}
-		p := NewProperties(parent)
+		p, ok := propsMap[val.Name]
+		if !ok {
+			p = NewProperties(parent)
+		} else if parent != nil {
+			p.parent = parent
+		}
 		for colorName, jsonData := range val.Colors {
-			namedCol := namedColor{Alpha: 1.0}
-			rgbaCol := colors.RGBA{}
-
-			err = json.Unmarshal(jsonData, &namedCol)
-			if err == nil {
-				if col, ok := colors.Map[namedCol.Name]; ok {
-					p.ColorMap[colorName] = col.Dark(namedCol.Dark).Bright(namedCol.Bright).Alpha(namedCol.Alpha)
-				} else {
-					log.Printf("jsonData: %s", jsonData)
-					log.Fatalf("%#v: color not found: %s", namedCol, namedCol.Name)
-				}
-				continue
-			}
-			switch err.(type) {
-			case *json.UnmarshalTypeError:
-			default:
-				log.Printf("[2]: failed unmarshaling data: %v", err)
-			}
-
-			err = json.Unmarshal(jsonData, &rgbaCol)
-			if err == nil {
-				p.ColorMap[colorName] = rgbaCol
-				continue
+			col, err := parseColor(jsonData)
+			if err != nil {
+				errList = append(errList, fmt.Errorf("%s.%s: %w",
+					val.Name, colorName, err))
 			}
-			log.Fatalf("[3]: failed unmarshaling data for color '%s': %v, data: %s", colorName, err, jsonData)
+			p.ColorMap[colorName] = col
 		}
 		for key, val := range val.Fonts {
 			p.FontMap[key] = val
@@ -344,9 +421,42 @@ func NewPropsMapFromData(data []byte) map[string]*Properties {
 		for key, val := range val.Sizes {
 			p.SizeMap[key] = val
 		}
+		for gradName, jsonData := range val.Gradients {
+			grad, err := parseGradient(jsonData)
+			if err != nil {
+				errList = append(errList, fmt.Errorf("%s.%s: %w",
+					val.Name, gradName, err))
+				if grad == nil {
+					continue
+				}
+			}
+			p.GradientMap[gradName] = grad
+		}
+		for imgName, jsonData := range val.Images {
+			img, err := parseNineSlice(jsonData)
+			if err != nil {
+				errList = append(errList, fmt.Errorf("%s.%s: %w",
+					val.Name, imgName, err))
+				if img == nil {
+					continue
+				}
+			}
+			p.ImageMap[imgName] = img
+		}
+		for shadowName, jsonData := range val.Shadows {
+			shadow, err := parseShadow(jsonData)
+			if err != nil {
+				errList = append(errList, fmt.Errorf("%s.%s: %w",
+					val.Name, shadowName, err))
+				if shadow == nil {
+					continue
+				}
+			}
+			p.ShadowMap[shadowName] = shadow
+		}
 		propsMap[val.Name] = p
 	}
-	return propsMap
+	return errors.Join(errList...)
 }
 
 var (
@@ -354,7 +464,11 @@ var (
 )
 
 func init() {
-	PropsMap = NewPropsMapFromEmbedFile("Props.json")
+	var err error
+	PropsMap, err = NewPropsMapFromEmbedFile("Props.json")
+	if err != nil {
+		log.Print(err)
+	}
 }
 
 type namedColor struct {
@@ -371,6 +485,32 @@ func (p *Properties) Parent() *Properties {
 
 func (p *Properties) SetParent(parent *Properties) {
 	p.parent = parent
+	p.changed(true)
+}
+
+// Liefert das Objekt, zu welchem die Properties gehoeren (siehe SetOwner).
+// Properties auf Objektebene gehoeren nach dem Erzeugen ihrem
+// PropertyEmbed, fuer Properties auf Typenebene wird nil retourniert.
+func (p *Properties) Owner() any {
+	return p.owner
+}
+
+// Mit SetOwner werden die Properties dem Objekt owner zugeordnet. Damit
+// koennen die ChangeListener Aenderungen an Properties auf Objektebene
+// direkt diesem Objekt zuordnen.
+func (p *Properties) SetOwner(owner any) {
+	p.owner = owner
+}
+
+// Liefert true, falls p gleich q ist oder q (direkt oder indirekt) als
+// Parent hat, d.h. falls sich Aenderungen an q auf p auswirken koennen.
+func (p *Properties) Inherits(q *Properties) bool {
+	for ; p != nil; p = p.parent {
+		if p == q {
+			return true
+		}
+	}
+	return false
 }
 
 func (p *Properties) Color(name ColorPropertyName) colors.RGBA {
@@ -378,8 +518,11 @@ func (p *Properties) Color(name ColorPropertyName) colors.RGBA {
 	var found bool
 
 	if col, found = p.ColorMap[name]; !found && p.parent != nil {
-		col = p.parent.Color(name)
-		p.ColorMap[name] = col
+		p.checkCache()
+		if col, found = p.colorCache[name]; !found {
+			col = p.parent.Color(name)
+			p.colorCache[name] = col
+		}
 	}
 	return col
 }
@@ -389,8 +532,11 @@ func (p *Properties) Font(name FontPropertyName) *fonts.Font {
 	var found bool
 
 	if fnt, found = p.FontMap[name]; !found && p.parent != nil {
-		fnt = p.parent.Font(name)
-		p.FontMap[name] = fnt
+		p.checkCache()
+		if fnt, found = p.fontCache[name]; !found {
+			fnt = p.parent.Font(name)
+			p.fontCache[name] = fnt
+		}
 	}
 	return fnt
 }
@@ -400,24 +546,161 @@ func (p *Properties) Size(name SizePropertyName) float64 {
 	var found bool
 
 	if siz, found = p.SizeMap[name]; !found && p.parent != nil {
-		siz = p.parent.Size(name)
-		p.SizeMap[name] = siz
+		p.checkCache()
+		if siz, found = p.sizeCache[name]; !found {
+			siz = p.parent.Size(name)
+			p.sizeCache[name] = siz
+		}
 	}
 	return siz
 }
 
+// Liefert den Farbverlauf name oder nil, falls keiner definiert ist.
+func (p *Properties) Gradient(name GradientPropertyName) *Gradient {
+	var grad *Gradient
+	var found bool
+
+	if grad, found = p.GradientMap[name]; !found && p.parent != nil {
+		p.checkCache()
+		if grad, found = p.gradientCache[name]; !found {
+			grad = p.parent.Gradient(name)
+			p.gradientCache[name] = grad
+		}
+	}
+	return grad
+}
+
+// Liefert das Bild name oder nil, falls keines definiert ist.
+func (p *Properties) Image(name ImagePropertyName) *NineSlice {
+	var img *NineSlice
+	var found bool
+
+	if img, found = p.ImageMap[name]; !found && p.parent != nil {
+		p.checkCache()
+		if img, found = p.imageCache[name]; !found {
+			img = p.parent.Image(name)
+			p.imageCache[name] = img
+		}
+	}
+	return img
+}
+
+// Liefert den Schatten name oder nil, falls keiner definiert ist.
+func (p *Properties) Shadow(name ShadowPropertyName) *Shadow {
+	var shadow *Shadow
+	var found bool
+
+	if shadow, found = p.ShadowMap[name]; !found && p.parent != nil {
+		p.checkCache()
+		if shadow, found = p.shadowCache[name]; !found {
+			shadow = p.parent.Shadow(name)
+			p.shadowCache[name] = shadow
+		}
+	}
+	return shadow
+}
+
+// Verwirft die zwischengespeicherten Werte, falls p oder einer seiner
+// Parents seit dem Fuellen des Caches geaendert wurde.
+func (p *Properties) checkCache() {
+	gen := generation.Load()
+	valid := true
+	for q := p; q != nil && valid; q = q.parent {
+		valid = q.gen.Load() <= p.cacheGen
+	}
+	if p.colorCache == nil {
+		p.colorCache = make(map[ColorPropertyName]colors.RGBA)
+		p.fontCache = make(map[FontPropertyName]*fonts.Font)
+		p.sizeCache = make(map[SizePropertyName]float64)
+		p.gradientCache = make(map[GradientPropertyName]*Gradient)
+		p.imageCache = make(map[ImagePropertyName]*NineSlice)
+		p.shadowCache = make(map[ShadowPropertyName]*Shadow)
+	} else if !valid {
+		clear(p.colorCache)
+		clear(p.fontCache)
+		clear(p.sizeCache)
+		clear(p.gradientCache)
+		clear(p.imageCache)
+		clear(p.shadowCache)
+	}
+	p.cacheGen = gen
+}
+
+// Objekte, welche ueber Aenderungen an Properties informiert werden wollen,
+// implementieren dieses Interface. Betroffen sind alle Properties, welche
+// p erben (siehe Inherits). Mit layout gleich true wurde eine Schrift, eine
+// Groesse oder der Parent geaendert, d.h. die Groesse von Widgets kann
+// sich dadurch aendern. Die Listener werden in der Go-Routine aufgerufen,
+// welche die Aenderung vorgenommen hat.
+type ChangeListener interface {
+	PropertiesChanged(p *Properties, layout bool)
+}
+
+var (
+	// Wird bei jeder Aenderung an Properties und bei jedem Wechsel des
+	// Themes erhoeht. Der neue Wert wird bei den geaenderten Properties
+	// hinterlegt (siehe gen), womit die zwischengespeicherten Werte aller
+	// Properties ungueltig werden, welche diese erben.
+	generation atomic.Uint64
+
+	listeners sync.Map
+)
+
+// Mit AddChangeListener wird l ueber alle kuenftigen Aenderungen an
+// Properties informiert (SetColor, DelColor, SetParent, etc.).
+func AddChangeListener(l ChangeListener) {
+	listeners.Store(l, true)
+}
+
+// Mit RemoveChangeListener koennen Listener wieder entfernt werden.
+func RemoveChangeListener(l ChangeListener) {
+	listeners.Delete(l)
+}
+
+// Macht die zwischengespeicherten, geerbten Werte aller Properties
+// ungueltig, welche p erben, und informiert die Listener ueber die
+// Aenderung an p.
+func (p *Properties) changed(layout bool) {
+	p.gen.Store(generation.Add(1))
+	listeners.Range(func(key, value any) bool {
+		key.(ChangeListener).PropertiesChanged(p, layout)
+		return true
+	})
+}
+
 // Über diese Methoden können einzelne Eigenschaften auf Typen- oder Objekt-
-// ebene definiert werden.
+// ebene definiert werden. Die Änderung wirkt sich sofort auf alle
+// Properties aus, welche die Eigenschaft von p erben.
 func (p *Properties) SetColor(name ColorPropertyName, col colors.RGBA) {
 	p.ColorMap[name] = col
+	p.changed(false)
 }
 
 func (p *Properties) SetFont(name FontPropertyName, fnt *fonts.Font) {
 	p.FontMap[name] = fnt
+	p.changed(true)
 }
 
 func (p *Properties) SetSize(name SizePropertyName, size float64) {
 	p.SizeMap[name] = size
+	p.changed(true)
+}
+
+// Mit nil wird ein geerbter Farbverlauf, resp. ein geerbtes Bild oder ein
+// geerbter Schatten ausgeschaltet.
+func (p *Properties) SetGradient(name GradientPropertyName, grad *Gradient) {
+	p.GradientMap[name] = grad
+	p.changed(false)
+}
+
+func (p *Properties) SetImage(name ImagePropertyName, img *NineSlice) {
+	p.ImageMap[name] = img
+	p.changed(false)
+}
+
+func (p *Properties) SetShadow(name ShadowPropertyName, shadow *Shadow) {
+	p.ShadowMap[name] = shadow
+	p.changed(false)
 }
 
 // Auf Typen- oder Objekt-Stufe definierte Eigenschaften können mit den
@@ -429,19 +712,58 @@ func (p *Properties) DelColor(name ColorPropertyName) {
 	if p.parent == nil {
 		return
 	}
-	delete(p.ColorMap, name)
+	if _, ok := p.ColorMap[name]; ok {
+		delete(p.ColorMap, name)
+		p.changed(false)
+	}
 }
 
 func (p *Properties) DelFont(name FontPropertyName) {
 	if p.parent == nil {
 		return
 	}
-	delete(p.FontMap, name)
+	if _, ok := p.FontMap[name]; ok {
+		delete(p.FontMap, name)
+		p.changed(true)
+	}
 }
 
 func (p *Properties) DelSize(name SizePropertyName) {
 	if p.parent == nil {
 		return
 	}
-	delete(p.SizeMap, name)
+	if _, ok := p.SizeMap[name]; ok {
+		delete(p.SizeMap, name)
+		p.changed(true)
+	}
+}
+
+func (p *Properties) DelGradient(name GradientPropertyName) {
+	if p.parent == nil {
+		return
+	}
+	if _, ok := p.GradientMap[name]; ok {
+		delete(p.GradientMap, name)
+		p.changed(false)
+	}
+}
+
+func (p *Properties) DelImage(name ImagePropertyName) {
+	if p.parent == nil {
+		return
+	}
+	if _, ok := p.ImageMap[name]; ok {
+		delete(p.ImageMap, name)
+		p.changed(false)
+	}
+}
+
+func (p *Properties) DelShadow(name ShadowPropertyName) {
+	if p.parent == nil {
+		return
+	}
+	if _, ok := p.ShadowMap[name]; ok {
+		delete(p.ShadowMap, name)
+		p.changed(false)
+	}
 }
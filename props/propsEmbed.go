@@ -13,11 +13,20 @@ type PropertyEmbed struct {
     prop *Properties
 }
 
+// Erzeugt die Properties des Objektes auf Objektebene. Sie gehoeren
+// zunaechst pe (siehe Properties.Owner).
 func (pe *PropertyEmbed) Init(parent *Properties) {
     pe.prop = NewProperties(parent)
+    pe.prop.owner = pe
 }
 func (pe *PropertyEmbed) InitByName(name string) {
-    pe.prop = NewProperties(PropsMap[name])
+    pe.Init(PropsMap[name])
+}
+
+// Liefert die Properties des Objektes. Ueber deren Parents sind sie mit
+// den Properties des Typs verbunden (siehe Properties.Inherits).
+func (pe *PropertyEmbed) Properties() (*Properties) {
+    return pe.prop
 }
 
 
@@ -133,6 +142,34 @@ func (pe *PropertyEmbed) SetMenuBackgroundColor(c colors.RGBA) {
     pe.prop.SetColor(MenuBackgroundColor, c)
 }
 
+func (pe *PropertyEmbed) FocusColor() (colors.RGBA) {
+    return pe.prop.Color(FocusColor)
+}
+func (pe *PropertyEmbed) SetFocusColor(c colors.RGBA) {
+    pe.prop.SetColor(FocusColor, c)
+}
+
+func (pe *PropertyEmbed) DisabledColor() (colors.RGBA) {
+    return pe.prop.Color(DisabledColor)
+}
+func (pe *PropertyEmbed) SetDisabledColor(c colors.RGBA) {
+    pe.prop.SetColor(DisabledColor, c)
+}
+
+func (pe *PropertyEmbed) DisabledBorderColor() (colors.RGBA) {
+    return pe.prop.Color(DisabledBorderColor)
+}
+func (pe *PropertyEmbed) SetDisabledBorderColor(c colors.RGBA) {
+    pe.prop.SetColor(DisabledBorderColor, c)
+}
+
+func (pe *PropertyEmbed) DisabledTextColor() (colors.RGBA) {
+    return pe.prop.Color(DisabledTextColor)
+}
+func (pe *PropertyEmbed) SetDisabledTextColor(c colors.RGBA) {
+    pe.prop.SetColor(DisabledTextColor, c)
+}
+
 func (pe *PropertyEmbed) Font() (*fonts.Font) {
     return pe.prop.Font(Font)
 }
@@ -237,3 +274,66 @@ func (pe *PropertyEmbed) FieldSize() (float64) {
 func (pe *PropertyEmbed) SetFieldSize(s float64) {
     pe.prop.SetSize(FieldSize, s)
 }
+
+func (pe *PropertyEmbed) FocusWidth() (float64) {
+    return pe.prop.Size(FocusWidth)
+}
+func (pe *PropertyEmbed) SetFocusWidth(s float64) {
+    pe.prop.SetSize(FocusWidth, s)
+}
+
+func (pe *PropertyEmbed) FillGradient() (*Gradient) {
+    return pe.prop.Gradient(FillGradient)
+}
+func (pe *PropertyEmbed) SetFillGradient(g *Gradient) {
+    pe.prop.SetGradient(FillGradient, g)
+}
+
+func (pe *PropertyEmbed) PushedFillGradient() (*Gradient) {
+    return pe.prop.Gradient(PushedFillGradient)
+}
+func (pe *PropertyEmbed) SetPushedFillGradient(g *Gradient) {
+    pe.prop.SetGradient(PushedFillGradient, g)
+}
+
+func (pe *PropertyEmbed) SelectedFillGradient() (*Gradient) {
+    return pe.prop.Gradient(SelectedFillGradient)
+}
+func (pe *PropertyEmbed) SetSelectedFillGradient(g *Gradient) {
+    pe.prop.SetGradient(SelectedFillGradient, g)
+}
+
+func (pe *PropertyEmbed) BackgroundImage() (*NineSlice) {
+    return pe.prop.Image(BackgroundImage)
+}
+func (pe *PropertyEmbed) SetBackgroundImage(i *NineSlice) {
+    pe.prop.SetImage(BackgroundImage, i)
+}
+
+func (pe *PropertyEmbed) PushedBackgroundImage() (*NineSlice) {
+    return pe.prop.Image(PushedBackgroundImage)
+}
+func (pe *PropertyEmbed) SetPushedBackgroundImage(i *NineSlice) {
+    pe.prop.SetImage(PushedBackgroundImage, i)
+}
+
+func (pe *PropertyEmbed) SelectedBackgroundImage() (*NineSlice) {
+    return pe.prop.Image(SelectedBackgroundImage)
+}
+func (pe *PropertyEmbed) SetSelectedBackgroundImage(i *NineSlice) {
+    pe.prop.SetImage(SelectedBackgroundImage, i)
+}
+
+func (pe *PropertyEmbed) DropShadow() (*Shadow) {
+    return pe.prop.Shadow(DropShadow)
+}
+func (pe *PropertyEmbed) SetDropShadow(s *Shadow) {
+    pe.prop.SetShadow(DropShadow, s)
+}
+
+func (pe *PropertyEmbed) PushedDropShadow() (*Shadow) {
+    return pe.prop.Shadow(PushedDropShadow)
+}
+func (pe *PropertyEmbed) SetPushedDropShadow(s *Shadow) {
+    pe.prop.SetShadow(PushedDropShadow, s)
+}
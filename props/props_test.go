@@ -1,6 +1,11 @@
 package props
 
 import (
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stefan-muehlebach/gg/colors"
@@ -106,6 +111,158 @@ func TestColorHierarchy(t *testing.T) {
 	}
 }
 
+// Aenderungen an einem Parent muessen auch bei Objekten ankommen, welche
+// den Wert bereits gelesen haben. Nach DelColor gilt wieder der geerbte
+// Wert.
+func TestLiveInheritance(t *testing.T) {
+	obj := NewProperties(typeProps)
+	orig := defProps.Color(TextColor)
+	c1 = obj.Color(TextColor)
+	if c1 != orig {
+		t.Fatalf("object color %v differs from default %v", c1, orig)
+	}
+
+	defProps.SetColor(TextColor, colors.FireBrick)
+	defer defProps.SetColor(TextColor, orig)
+	if c2 = obj.Color(TextColor); c2 != colors.FireBrick {
+		t.Errorf("got stale color %v after changing the default", c2)
+	}
+
+	typeProps.SetColor(TextColor, colors.Yellow)
+	if c2 = obj.Color(TextColor); c2 != colors.Yellow {
+		t.Errorf("got color %v, want the type color", c2)
+	}
+	typeProps.DelColor(TextColor)
+	if c2 = obj.Color(TextColor); c2 != colors.FireBrick {
+		t.Errorf("got color %v after DelColor, want the default", c2)
+	}
+
+	s1 = obj.Size(FontSize)
+	other := NewProperties(nil)
+	other.SetSize(FontSize, s1+10)
+	obj.SetParent(other)
+	if siz := obj.Size(FontSize); siz != s1+10 {
+		t.Errorf("got size %v after SetParent, want %v", siz, s1+10)
+	}
+}
+
+// Aenderungen an Properties, welche nicht geerbt werden, duerfen die
+// zwischengespeicherten Werte nicht verwerfen.
+func TestCacheInvalidation(t *testing.T) {
+	obj := NewProperties(typeProps)
+	c1 = obj.Color(BarColor)
+	other := NewProperties(typeProps)
+	other.SetColor(BarColor, colors.Yellow)
+	if n := len(obj.colorCache); n != 1 {
+		t.Errorf("cache holds %d colors after an unrelated change, want 1", n)
+	}
+
+	typeProps.SetColor(BarColor, colors.FireBrick)
+	defer typeProps.DelColor(BarColor)
+	if c2 = obj.Color(BarColor); c2 != colors.FireBrick {
+		t.Errorf("got stale color %v after changing the type", c2)
+	}
+}
+
+// Zaehlt die Aenderungen, welche Properties betreffen, die von prop erben.
+type countListener struct {
+	prop          *Properties
+	count, layout int
+}
+
+func (l *countListener) PropertiesChanged(p *Properties, layout bool) {
+	if !l.prop.Inherits(p) {
+		return
+	}
+	l.count++
+	if layout {
+		l.layout++
+	}
+}
+
+func TestChangeListener(t *testing.T) {
+	obj := NewProperties(typeProps)
+	l := &countListener{prop: obj}
+	AddChangeListener(l)
+	defer RemoveChangeListener(l)
+
+	typeProps.SetColor(BarColor, colors.Yellow)
+	typeProps.DelColor(BarColor)
+	typeProps.DelColor(BarColor)
+	typeProps.SetSize(BarSize, 7)
+	typeProps.DelSize(BarSize)
+	NewProperties(nil).SetColor(BarColor, colors.Yellow)
+	if l.count != 4 || l.layout != 2 {
+		t.Errorf("got %d changes (%d layout), want 4 (2 layout)",
+			l.count, l.layout)
+	}
+
+	RemoveChangeListener(l)
+	typeProps.SetColor(BarColor, colors.Yellow)
+	typeProps.DelColor(BarColor)
+	if l.count != 4 {
+		t.Errorf("removed listener still called")
+	}
+}
+
+// Beim Wechsel des Themes erhalten auch bestehende Properties die neuen
+// Werte, auf Objektebene gesetzte Werte bleiben jedoch erhalten.
+// Properties auf Objektebene gehoeren nach dem Erzeugen ihrem
+// PropertyEmbed, solche auf Typenebene niemandem.
+func TestPropertyOwner(t *testing.T) {
+	var pe PropertyEmbed
+	pe.InitByName("Button")
+	if owner := pe.Properties().Owner(); owner != &pe {
+		t.Errorf("object properties owned by %v, want their PropertyEmbed",
+			owner)
+	}
+	if owner := PropsMap["Button"].Owner(); owner != nil {
+		t.Errorf("type properties owned by %v", owner)
+	}
+}
+
+func TestSetTheme(t *testing.T) {
+	obj := NewProperties(typeProps)
+	own := NewProperties(typeProps)
+	own.SetColor(TextColor, colors.Yellow)
+	c1 = obj.Color(Color)
+	s1 = obj.Size(FontSize)
+
+	if err := SetTheme("HighContrast"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetTheme(DefaultTheme)
+	if name := Theme(); name != "HighContrast" {
+		t.Errorf("theme is '%s', want 'HighContrast'", name)
+	}
+	if col := obj.Color(Color); col == c1 || col != defProps.Color(Color) {
+		t.Errorf("got color %v after theme change", col)
+	}
+	if siz := obj.Size(FontSize); siz == s1 {
+		t.Errorf("font size still %v after theme change", siz)
+	}
+	if col := own.Color(TextColor); col != colors.Yellow {
+		t.Errorf("object color replaced by %v", col)
+	}
+	if PropsMap["Button"] != typeProps {
+		t.Errorf("type properties replaced instead of updated")
+	}
+
+	if err := SetTheme("NoSuchTheme"); !errors.Is(err, ErrThemeNotFound) {
+		t.Errorf("got error %v, want ErrThemeNotFound", err)
+	}
+	if name := Theme(); name != "HighContrast" {
+		t.Errorf("theme changed to '%s' by a missing theme", name)
+	}
+
+	if err := SetTheme(DefaultTheme); err != nil {
+		t.Fatal(err)
+	}
+	if col := obj.Color(Color); col != c1 {
+		t.Errorf("got color %v, want %v after switching back", col, c1)
+	}
+}
+
 func TestGetFont(t *testing.T) {
 	f = defProps.Font(BoldFont)
 	t.Logf("Def.BoldFont: %T", f)
@@ -154,6 +311,133 @@ func BenchmarkGetObjSize(b *testing.B) {
 }
 
 func TestPropFileRead(t *testing.T) {
-	PropsMap = NewPropsMapFromUserFile("TestProps.json")
+	var err error
+	PropsMap, err = NewPropsMapFromUserFile("TestProps.json")
+	if err != nil {
+		t.Fatal(err)
+	}
 	t.Logf("Default.Color: %+v", PropsMap["Default"].Color(Color))
 }
+
+func TestPropErrors(t *testing.T) {
+	data := []byte(`[
+		{"Name": "Default", "Colors": {"Color": {"Name": "NoSuchColor"},
+			"TextColor": "no color"}},
+		{"Name": "Button", "ParentName": "NoSuchParent",
+			"Sizes": {"Width": 12}}
+	]`)
+	propsMap, err := NewPropsMapFromData(data)
+	if !errors.Is(err, ErrColorNotFound) {
+		t.Errorf("missing ErrColorNotFound in %v", err)
+	}
+	if !errors.Is(err, ErrInvalidColor) {
+		t.Errorf("missing ErrInvalidColor in %v", err)
+	}
+	if !errors.Is(err, ErrParentNotFound) {
+		t.Errorf("missing ErrParentNotFound in %v", err)
+	}
+	if col := propsMap["Default"].Color(Color); col != FallbackColor {
+		t.Errorf("got color %v, want the fallback color", col)
+	}
+	if siz := propsMap["Button"].Size(Width); siz != 12 {
+		t.Errorf("got width %v, want 12", siz)
+	}
+
+	if _, err := NewPropsMapFromData([]byte("[{")); err == nil {
+		t.Errorf("invalid JSON read without error")
+	}
+	if _, err := NewPropsMapFromUserFile("NoSuchFile.json"); err == nil {
+		t.Errorf("missing file read without error")
+	}
+}
+
+func TestPaintProperties(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 9, 9))
+	fileName := filepath.Join(t.TempDir(), "frame.png")
+	fh, err := os.Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(fh, img); err != nil {
+		t.Fatal(err)
+	}
+	fh.Close()
+
+	data := []byte(`[
+		{"Name": "Default",
+			"Gradients": {"FillGradient": {"Stops": [
+				{"Offset": 0, "Color": {"Name": "Teal"}},
+				{"Offset": 1, "Color": "0x000019"}]}},
+			"Shadows": {"DropShadow": {"Offset": {"X": 2, "Y": 3}, "Blur": 4}}},
+		{"Name": "Panel", "ParentName": "Default",
+			"Gradients": {
+				"FillGradient": null,
+				"PushedFillGradient": {"Type": "Radial",
+					"Stops": [{"Offset": 0, "Color": {"Name": "NoSuchColor"}}]}},
+			"Images": {"BackgroundImage": {"File": "` + filepath.ToSlash(fileName) + `",
+				"Insets": {"Top": 3, "Right": 3, "Bottom": 3, "Left": 3}}}},
+		{"Name": "Button", "ParentName": "Default",
+			"Gradients": {"SelectedFillGradient": {"Type": "Linear"},
+				"FillGradient": {"Stops": []}},
+			"Images": {"BackgroundImage": {"File": "NoSuchImage.png"}},
+			"Shadows": {"DropShadow": "no shadow"}}
+	]`)
+	propsMap, err := NewPropsMapFromData(data)
+	if !errors.Is(err, ErrColorNotFound) {
+		t.Errorf("missing ErrColorNotFound in %v", err)
+	}
+	if !errors.Is(err, ErrInvalidGradient) {
+		t.Errorf("missing ErrInvalidGradient in %v", err)
+	}
+	if !errors.Is(err, ErrImageNotFound) {
+		t.Errorf("missing ErrImageNotFound in %v", err)
+	}
+
+	obj := NewProperties(propsMap["Button"])
+	grad := obj.Gradient(FillGradient)
+	if grad == nil {
+		t.Fatalf("gradient not inherited despite an invalid entry")
+	}
+	if grad.Type != LinearGradient || grad.To.Y != 1 || len(grad.Stops) != 2 {
+		t.Errorf("got gradient %+v", grad)
+	}
+	if col := grad.Stops[1].Color; col != (colors.RGBA{R: 0x00, G: 0x00, B: 0x19, A: 0xFF}) {
+		t.Errorf("got stop color %v", col)
+	}
+	if obj.Gradient(SelectedFillGradient) != nil {
+		t.Errorf("gradient without stops was accepted")
+	}
+	if obj.Image(BackgroundImage) != nil {
+		t.Errorf("got an image for a missing file")
+	}
+	sh := obj.Shadow(DropShadow)
+	if sh == nil || sh.Blur != 4 || sh.Offset.Y != 3 || sh.Color.A == 0 {
+		t.Errorf("got shadow %+v", sh)
+	}
+
+	panel := NewProperties(propsMap["Panel"])
+	if panel.Gradient(FillGradient) != nil {
+		t.Errorf("inherited gradient not disabled by null")
+	}
+	if grad := panel.Gradient(PushedFillGradient); grad == nil ||
+		grad.Center.X != 0.5 || grad.Radius != 0.5 ||
+		grad.Stops[0].Color != FallbackColor {
+		t.Errorf("got radial gradient %+v", grad)
+	}
+	ns := panel.Image(BackgroundImage)
+	if ns == nil || ns.Image == nil || ns.Insets.Left != 3 {
+		t.Fatalf("got image %+v", ns)
+	}
+	if ns.Image.Bounds().Dx() != 9 {
+		t.Errorf("got image size %v", ns.Image.Bounds())
+	}
+
+	obj.SetShadow(DropShadow, nil)
+	if obj.Shadow(DropShadow) != nil {
+		t.Errorf("shadow not disabled on object level")
+	}
+	obj.DelShadow(DropShadow)
+	if obj.Shadow(DropShadow) != sh {
+		t.Errorf("shadow of the parent not restored")
+	}
+}
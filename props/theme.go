@@ -0,0 +1,107 @@
+package props
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Ein Theme ist ein Satz von Properties, welcher zur Laufzeit ausgetauscht
+// werden kann (bspw. fuer einen Tag- und Nachtmodus). Grundlage jedes
+// Themes sind die Properties aus Props.json; die Datei des Themes muss nur
+// diejenigen Werte enthalten, welche davon abweichen. Sie hat das gleiche
+// Format wie Props.json.
+const (
+	// Name des Standard-Themes, d.h. der Properties aus Props.json.
+	DefaultTheme = "Default"
+)
+
+var (
+	themeName = DefaultTheme
+)
+
+// Liefert den Namen des aktuellen Themes.
+func Theme() string {
+	return themeName
+}
+
+// Mit SetTheme wird das Theme name aktiviert. Gesucht wird zuerst eine
+// eingebettete Datei name.json (bspw. "Night" oder "HighContrast"), danach
+// eine lokale Datei mit dem Namen name. Die Properties in PropsMap werden
+// dabei nicht ersetzt, sondern mit den neuen Werten ueberschrieben, damit
+// alle bestehenden Widgets die Werte des neuen Themes erhalten. Werte,
+// welche auf Objektebene gesetzt wurden, bleiben bestehen.
+//
+// Kann das Theme nicht gelesen werden, bleibt das aktuelle Theme aktiv und
+// der Fehler wird retourniert. Fehler in einzelnen Properties werden wie
+// bei NewPropsMapFromData behandelt. SetTheme darf nicht parallel zu den
+// Widgets aufgerufen werden, welche die Properties verwenden (siehe
+// Screen.SetTheme in adagui).
+func SetTheme(name string) error {
+	propsMap, err := NewThemeMap(name)
+	if propsMap == nil {
+		return err
+	}
+	InstallTheme(name, propsMap)
+	return err
+}
+
+// Aktiviert die mit NewThemeMap erzeugten Properties propsMap als Theme
+// name. Damit kann das Theme in einer beliebigen Go-Routine gelesen und
+// spaeter dort aktiviert werden, wo die Widgets die Properties verwenden.
+func InstallTheme(name string, propsMap map[string]*Properties) {
+	installTheme(propsMap)
+	themeName = name
+}
+
+// Erzeugt die Properties zum Theme name (siehe SetTheme), ohne das Theme
+// zu aktivieren.
+func NewThemeMap(name string) (map[string]*Properties, error) {
+	propsMap, err := NewPropsMapFromEmbedFile("Props.json")
+	if propsMap == nil || name == "" || name == DefaultTheme {
+		return propsMap, err
+	}
+	data, readErr := propFiles.ReadFile(name + ".json")
+	if readErr != nil {
+		data, readErr = os.ReadFile(name)
+	}
+	if readErr != nil {
+		return nil, fmt.Errorf("%w: '%s'", ErrThemeNotFound, name)
+	}
+	var propList []propEntry
+	if err := json.Unmarshal(data, &propList); err != nil {
+		return nil, fmt.Errorf("theme '%s': failed unmarshaling data: %w",
+			name, err)
+	}
+	return propsMap, errors.Join(err, addProps(propsMap, propList))
+}
+
+// Uebernimmt die Werte aus propsMap in die bestehenden Properties von
+// PropsMap. Parents werden dabei auf die bestehenden Properties umgebogen.
+func installTheme(propsMap map[string]*Properties) {
+	if PropsMap == nil {
+		PropsMap = make(map[string]*Properties)
+	}
+	gen := generation.Add(1)
+	target := make(map[*Properties]*Properties)
+	for name, p := range propsMap {
+		if old, ok := PropsMap[name]; ok {
+			target[p] = old
+		} else {
+			target[p] = p
+		}
+	}
+	for name, p := range propsMap {
+		old := target[p]
+		old.parent = target[p.parent]
+		old.ColorMap = p.ColorMap
+		old.FontMap = p.FontMap
+		old.SizeMap = p.SizeMap
+		old.GradientMap = p.GradientMap
+		old.ImageMap = p.ImageMap
+		old.ShadowMap = p.ShadowMap
+		old.gen.Store(gen)
+		PropsMap[name] = old
+	}
+}
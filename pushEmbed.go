@@ -16,13 +16,14 @@ type PushEmbed struct {
 // und der Moeglichkeit, den Status mit anderen Widgets zu teilen.
 func (e *PushEmbed) Init(node Node, extData binding.Bool) {
     e.node = node
+    node.Wrappee().focusable = true
     if extData == nil {
         e.pushed = binding.NewBool()
         e.pushed.Set(false)
     } else {
         e.pushed = extData
     }
-    e.pushed.AddListener(e)
+    e.pushed.AddCallback(onUI(e.DataChanged))
 }
 
 // Ermittelt den Status des Embed.
@@ -43,7 +44,15 @@ func (e *PushEmbed) OnInputEvent(evt touch.Event) {
     }
 }
 
-// Wird autom. aufgerufen, sobald der Wert von 'pushed' veraendert wird.
+// Setzt den Status zurueck, bspw. wenn das Widget deaktiviert wird.
+func (e *PushEmbed) release() {
+    if e.pushed.Get() {
+        e.pushed.Set(false)
+    }
+}
+
+// Wird autom. (im UI-Thread) aufgerufen, sobald der Wert von 'pushed'
+// veraendert wird.
 func (e *PushEmbed) DataChanged(pushed binding.DataItem) {
     e.node.Mark(MarkNeedsPaint)
 }
@@ -0,0 +1,33 @@
+package adagui
+
+import (
+	"image"
+	"math"
+
+	"github.com/stefan-muehlebach/gg"
+)
+
+// Mit RenderNode wird der Node n (inkl. aller Kinder) in ein neues Bild
+// gezeichnet, unabhaengig davon, ob und wo er in einem Fenster angezeigt
+// wird. Das Bild hat die Groesse des Nodes (siehe Size) multipliziert mit
+// scale, mit scale > 1.0 erhaelt man damit eine hoehere Aufloesung. Der
+// Hintergrund ist transparent. Wie die Methoden der Nodes muss RenderNode
+// in der Go-Routine aufgerufen werden, welcher n gehoert; fuer angezeigte
+// Nodes ist dies der UI-Thread (aus anderen Go-Routinen bspw. mit
+// Screen.PostSync).
+//
+// Verwendet werden kann das Bild bspw. fuer Vorschaubilder in der
+// Dokumentation, als Abbild beim Verschieben von Nodes oder zum Pruefen
+// eines Widgets in Tests.
+func RenderNode(n Node, scale float64) image.Image {
+	if scale <= 0.0 {
+		scale = 1.0
+	}
+	size := n.Size()
+	width := max(int(math.Ceil(scale*size.X)), 1)
+	height := max(int(math.Ceil(scale*size.Y)), 1)
+	gc := gg.NewContext(width, height)
+	gc.Scale(scale, scale)
+	n.Paint(gc)
+	return gc.Image()
+}
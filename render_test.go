@@ -0,0 +1,60 @@
+package adagui_test
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+// Das Abbild eines Nodes muss dem entsprechenden Ausschnitt des Fensters
+// entsprechen.
+func TestRenderNode(t *testing.T) {
+	btn := adagui.NewTextButton("Render me")
+	btn.SetPos(geom.Point{X: 20, Y: 30})
+	grp := adagui.NewGroup()
+	grp.Add(btn)
+	h := adaguitest.New(t, grp)
+
+	var img image.Image
+	h.Do(func() { img = adagui.RenderNode(btn, 1.0) })
+	size := btn.Size()
+	if b := img.Bounds(); b.Dx() != int(size.X) || b.Dy() != int(size.Y) {
+		t.Fatalf("image size is %v, want %v", b.Size(), size)
+	}
+
+	min := image.Pt(20, 30)
+	win := h.Image()
+	want := image.NewRGBA(img.Bounds())
+	draw.Draw(want, want.Bounds(), win, min, draw.Src)
+	got := image.NewRGBA(img.Bounds())
+	draw.Draw(got, got.Bounds(), win, min, draw.Src)
+	draw.Draw(got, got.Bounds(), img, image.Point{}, draw.Over)
+
+	// Am Rand wird der Rahmen des Buttons im Fenster nicht beschnitten,
+	// verglichen wird daher nur das Innere.
+	inner := img.Bounds().Inset(2)
+	numDiff, _, err := adaguitest.Compare(got.SubImage(inner),
+		want.SubImage(inner), adaguitest.DefaultTolerance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numDiff > 0 {
+		t.Errorf("%d pixels differ from the window content", numDiff)
+	}
+}
+
+func TestRenderNodeScaled(t *testing.T) {
+	lbl := adagui.NewLabel("Detached")
+	img := adagui.RenderNode(lbl, 2.0)
+	size := lbl.Size()
+	if b := img.Bounds(); b.Dx() != int(2*size.X) || b.Dy() != int(2*size.Y) {
+		t.Errorf("image size is %v, want twice %v", b.Size(), size)
+	}
+	if lbl.Wrappee().Win != nil {
+		t.Errorf("detached label got attached to a window")
+	}
+}
@@ -1,72 +1,255 @@
 package adagui
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"flag"
+	"image"
+	"image/draw"
 	"log"
+	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/stefan-muehlebach/adagui/binding"
+	"github.com/stefan-muehlebach/adagui/key"
+	"github.com/stefan-muehlebach/adagui/props"
 	"github.com/stefan-muehlebach/adagui/touch"
 	"github.com/stefan-muehlebach/adatft"
 	"github.com/stefan-muehlebach/gg/geom"
 )
 
 var (
-	screen      *Screen = nil
-	refreshRate         = 30 * time.Millisecond
+	screen      atomic.Pointer[Screen]
+	refreshRate = 30 * time.Millisecond
 )
 
 // Dies ist die Datenstruktur, welche das TFT-Display aus einer hoeheren
-// Abstraktion beschreibt. Diese Struktur darf es nur einmal (1) in einer
-// Applikation geben.
+// Abstraktion beschreibt. Diese Struktur darf es zu jedem Zeitpunkt nur
+// einmal (1) in einer Applikation geben. Nach dem Ende von Run kann jedoch
+// ein neuer Screen erzeugt werden.
 type Screen struct {
-	disp                     *adatft.Display
-	touch                    *adatft.Touch
-	window                   *Window
-	paintTicker              *time.Ticker
-	paintCloseQ, eventCloseQ chan bool
-	wg                       sync.WaitGroup
-	mutex                    *sync.Mutex
+	backend            Backend
+	window             *Window
+	stack              []*Window
+	windows            map[*Window]bool
+	frame              *image.RGBA
+	ui                 dispatcher
+	transition         Transition
+	trans              *transitionState
+	paintTicker        *time.Ticker
+	movie              *movieRecorder
+	movieMutex         sync.Mutex
+	theme              string
+	idle               idleState
+	frames             frameClock
+	propsChanges       propsChanges
+	paintCloseQ, quitQ chan bool
+	quitOnce           sync.Once
+	longPressQ         chan int
+	syncQ              chan chan bool
+	pendingInput       atomic.Int64
+	wg                 sync.WaitGroup
+	mutex              *sync.Mutex
+	animList           []*Animation
+	animMutex          sync.Mutex
+	keySource          key.Source
+	keyStopQ           chan bool
+	keySyncQ           chan chan bool
+	gestureConfig      touch.GestureConfig
+	recorder           *touch.SessionWriter
+	recordFile         *os.File
+	recMutex           sync.Mutex
+	replayFile         string
+	replaySpeed        float64
+}
+
+// Mit ScreenOptions werden die optionalen Einstellungen fuer NewScreen
+// festgelegt. Der Nullwert verwendet das TftBackend ohne Tastatur,
+// Aufzeichnung oder Anzeige der Bildrate.
+type ScreenOptions struct {
+	// Name des Backends: "tft" (Display und Touchscreen) oder "mem"
+	// (im Speicher, ohne Hardware). Leer bedeutet "tft".
+	Backend string
+	// evdev-Geraet einer Tastatur oder eines Keypads (bspw.
+	// '/dev/input/event0').
+	Keyboard string
+	// JSON-Datei mit den Grenzwerten fuer die Gesten (siehe
+	// touch.GestureConfig).
+	Gestures string
+	// Datei, in welche alle Touch-Events aufgezeichnet werden.
+	Record string
+	// Datei, deren Touch-Events beim Start von Run abgespielt werden.
+	Replay string
+	// Geschwindigkeitsfaktor fuer Replay (2.0 spielt doppelt so schnell
+	// ab). Werte <= 0 bedeuten 1.0.
+	ReplaySpeed float64
+	// Zeigt die Kennzahlen zum Bildaufbau an (siehe SetShowFPS).
+	ShowFPS bool
+}
+
+// Registriert fuer alle Felder von o ein Flag im FlagSet fs. Damit lassen
+// sich die Einstellungen ueber die Kommandozeile setzen:
+//
+//	var opts adagui.ScreenOptions
+//	opts.RegisterFlags(flag.CommandLine)
+//	flag.Parse()
+//	s, err := adagui.NewScreen(adatft.Rotate090, opts)
+func (o *ScreenOptions) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Backend, "backend", "tft",
+		"backend used by NewScreen: 'tft' (display and touchscreen) or 'mem' (in-memory, no hardware)")
+	fs.StringVar(&o.Keyboard, "keyboard", "",
+		"evdev device of a keyboard or keypad used by NewScreen (e.g. '/dev/input/event0')")
+	fs.StringVar(&o.Gestures, "gestures", "",
+		"JSON file with the gesture thresholds used by NewScreen (see touch.GestureConfig)")
+	fs.StringVar(&o.Record, "record", "",
+		"record all touch events to this file (JSON lines)")
+	fs.StringVar(&o.Replay, "replay", "",
+		"replay the touch events from this file when the screen starts running")
+	fs.Float64Var(&o.ReplaySpeed, "replaySpeed", 1.0,
+		"speed factor for '-replay' (2.0 replays twice as fast)")
+	fs.BoolVar(&o.ShowFPS, "showfps", false,
+		"show the frame rate and frame times in the upper left corner")
 }
 
 // Mit NewScreen wird ein neues Screen-Objekt erzeugt und alle technischen
 // Objekte in Zusammenhang mit der Ansteuerung des Bildschirm und Touch-
-// Screens erzeugt. Aktuell darf es nur ein (1) solches Objekt geben - ein
-// mehrfaches Aufrufen von NewScreen führt zu einem Abbruch der Applikation.
-func NewScreen(rotation adatft.RotationType) *Screen {
-	if screen != nil {
-		log.Fatal("there is already a 'Screen' object in this application")
+// Screens erzeugt. Es darf jeweils nur ein (1) solches Objekt geben - wird
+// NewScreen aufgerufen, bevor Run des bestehenden Screens zurueckgekehrt
+// ist, wird ErrScreenExists retourniert.
+// Welches Backend verwendet wird, bestimmt opts.Backend (Default:
+// TftBackend). Ueber die weiteren Felder von opts kann zusaetzlich eine
+// Tastatur (bspw. ein Keypad) angeschlossen, eine Datei mit den
+// Grenzwerten fuer die Gesten geladen, die Touch-Events aufgezeichnet,
+// resp. wieder abgespielt und die Kennzahlen zum Bildaufbau angezeigt
+// werden (siehe ScreenOptions). Kann eine der Dateien nicht geoeffnet
+// werden, wird kein Screen erzeugt und der Fehler retourniert.
+func NewScreen(rotation adatft.RotationType, opts ScreenOptions) (*Screen,
+	error) {
+	if screen.Load() != nil {
+		return nil, ErrScreenExists
+	}
+	cfg, err := loadGestureConfig(opts.Gestures)
+	if err != nil {
+		return nil, err
+	}
+	src, err := openKeyboard(opts.Keyboard)
+	if err != nil {
+		return nil, err
+	}
+	// Schlaegt einer der folgenden Schritte fehl, werden die bereits
+	// geoeffneten Ressourcen wieder freigegeben.
+	var fh *os.File
+	var b Backend
+	cleanup := func() {
+		if b != nil {
+			b.Close()
+		}
+		if src != nil {
+			src.Close()
+		}
+		if fh != nil {
+			fh.Close()
+		}
 	}
+	if fh, err = openRecording(opts.Record); err != nil {
+		cleanup()
+		return nil, err
+	}
+	if b, err = newDefaultBackend(opts.Backend, rotation); err != nil {
+		cleanup()
+		return nil, err
+	}
+	s, err := NewScreenWithBackend(b)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+	s.SetGestureConfig(cfg)
+	if src != nil {
+		s.SetKeySource(src)
+	}
+	if fh != nil {
+		s.StartRecording(fh)
+		s.recordFile = fh
+	}
+	s.replayFile = opts.Replay
+	s.replaySpeed = opts.ReplaySpeed
+	if s.replaySpeed <= 0 {
+		s.replaySpeed = 1.0
+	}
+	s.frames.showFPS.Store(opts.ShowFPS)
+	return s, nil
+}
+
+// Wie NewScreen, verwendet fuer die Ausgabe und die Touch-Ereignisse jedoch
+// das Backend b. Damit laesst sich eine Applikation bspw. mit einem
+// MemBackend ohne angeschlossene Hardware betreiben.
+func NewScreenWithBackend(b Backend) (*Screen, error) {
 	s := &Screen{}
-	s.disp = adatft.OpenDisplay(rotation)
-	s.touch = adatft.OpenTouch(rotation)
-	s.paintTicker = time.NewTicker(refreshRate)
+	if !screen.CompareAndSwap(nil, s) {
+		return nil, ErrScreenExists
+	}
+	s.backend = b
+	s.frames.init()
+	s.paintTicker = time.NewTicker(s.frames.interval)
 	s.window = nil
+	s.windows = make(map[*Window]bool)
 	s.paintCloseQ = make(chan bool)
-	s.eventCloseQ = make(chan bool)
+	s.quitQ = make(chan bool)
+	s.longPressQ = make(chan int, 1)
+	s.syncQ = make(chan chan bool)
 	s.wg.Add(2)
 	s.mutex = &sync.Mutex{}
+	s.gestureConfig = touch.DefaultGestureConfig()
+	s.theme = props.Theme()
+	s.ui.init()
+	s.initIdle()
 
-	screen = s
+	binding.SetDispatcher(s.Post)
 
+	go s.uiThread()
 	go s.paintThread()
 
-	return s
+	return s, nil
 }
 
 // Mit CurrentScreen wird die Referenz auf den aktuellen (einzigen) Bildschirm
 // retourniert. Man könnte dies auch über eine globale Variable lösen.
+// Nach dem Ende von Run wird nil retourniert, bis ein neuer Screen erzeugt
+// wird.
 func CurrentScreen() *Screen {
-	return screen
+	return screen.Load()
+}
+
+// Liefert das Backend, mit welchem dieser Screen betrieben wird.
+func (s *Screen) Backend() Backend {
+	return s.backend
 }
 
-func (s *Screen) SaveScreenshot(name string) {
-	s.Window().SaveScreenshot(name)
+// Liefert die Groesse des Bildschirms in Pixel.
+func (s *Screen) Size() (int, int) {
+	return s.backend.Size()
 }
 
-func (s *Screen) SaveMovie(name string) {
-	fileName := fmt.Sprintf(name, 0)
-	s.Window().SaveScreenshot(fileName)
+// Speichert den Inhalt des aktiven Fensters als PNG-Datei.
+func (s *Screen) SaveScreenshot(name string) error {
+	w := s.Window()
+	if w == nil {
+		return ErrNoWindow
+	}
+	return w.SaveScreenshot(name)
+}
+
+// Zeichnet waehrend der Dauer d alle dargestellten Bilder auf (siehe
+// StartMovie) und kehrt erst nach dem Ende der Aufnahme zurueck.
+func (s *Screen) SaveMovie(name string, d time.Duration) error {
+	if err := s.StartMovie(name, MovieOptions{MaxDuration: d}); err != nil {
+		return err
+	}
+	time.Sleep(d)
+	return s.StopMovie()
 }
 
 // Mit NewWindow wird ein neues Fenster erzeugt. Im Gegensatz zum Screen
@@ -77,178 +260,663 @@ func (s *Screen) NewWindow() *Window {
 	return w
 }
 
-// Liefert das aktuell angezeigte Window zurueck.
+// Liefert das aktuell angezeigte Window zurueck. Liegen mehrere Fenster auf
+// dem Stack, dann ist dies das oberste.
 func (s *Screen) Window() *Window {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	return s.window
 }
 
 // Mit SetWindow wird das übergebene Fenster zum sichtbaren und aktiven
-// Fenster. Nur aktive Fenster erhalten die Touch-Events vom Touchscreen und
-// nur aktive Fenster werden dargestellt.
+// Fenster. Es ersetzt das oberste Fenster auf dem Stack (siehe PushWindow),
+// resp. wird als einziges Fenster auf den Stack gelegt, falls dieser leer
+// ist. Nur aktive Fenster erhalten die Touch-Events vom Touchscreen. Fuer
+// den Wechsel wird der mit SetTransition festgelegte Uebergang verwendet.
 func (s *Screen) SetWindow(w *Window) {
-	if s.window == w {
-		return
-	}
+	s.SetWindowWithTransition(w, s.Transition())
+}
+
+func (s *Screen) setWindow(w *Window) {
 	s.mutex.Lock()
-	if s.window != nil {
-		s.window.stage = StageAlive
+	if n := len(s.stack); n > 0 {
+		s.stack[n-1].stage = StageAlive
+		s.stack = s.stack[:n-1]
 	}
-	s.window = w
-	s.window.stage = StageVisible
+	s.removeWindow(w)
+	w.flags = 0
+	s.stack = append(s.stack, w)
+	s.updateStages()
 	s.mutex.Unlock()
+}
+
+// Legt das Fenster w zuoberst auf den Stack und macht es zum aktiven
+// Fenster. Mit flags kann bestimmt werden, ob das Fenster modal ist und ob
+// es ueber die darunterliegenden Fenster gezeichnet wird (siehe
+// WindowModal und WindowTranslucent). Mit PopWindow gelangt man wieder
+// zum vorherigen Fenster zurueck.
+func (s *Screen) PushWindow(w *Window, flags WindowFlags) {
+	s.pushWindow(w, flags)
 	s.Repaint()
 }
 
+func (s *Screen) pushWindow(w *Window, flags WindowFlags) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.removeWindow(w)
+	w.flags = flags
+	s.stack = append(s.stack, w)
+	s.updateStages()
+}
+
+// Entfernt das oberste Fenster vom Stack und retourniert es. Das Fenster
+// darunter wird wieder zum aktiven Fenster. Das entfernte Fenster wird
+// nicht geschlossen und kann spaeter erneut verwendet werden. Ist der Stack
+// leer, wird nil retourniert.
+func (s *Screen) PopWindow() *Window {
+	w := s.popWindow()
+	if w != nil {
+		s.Repaint()
+	}
+	return w
+}
+
+func (s *Screen) popWindow() *Window {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	n := len(s.stack)
+	if n == 0 {
+		return nil
+	}
+	w := s.stack[n-1]
+	s.stack = s.stack[:n-1]
+	w.stage = StageAlive
+	s.updateStages()
+	return w
+}
+
+// Entfernt das Fenster w vom Stack, falls es darauf liegt, und retourniert
+// true in diesem Fall. Muss unter dem Lock des Screens aufgerufen werden.
+func (s *Screen) removeWindow(w *Window) bool {
+	for i, win := range s.stack {
+		if win == w {
+			s.stack = append(s.stack[:i], s.stack[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Liefert alle Fenster auf dem Stack. Das unterste Fenster steht am Anfang,
+// das aktive Fenster am Ende der Liste.
+func (s *Screen) Windows() []*Window {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stack := make([]*Window, len(s.stack))
+	copy(stack, s.stack)
+	return stack
+}
+
+// Bestimmt nach einer Aenderung am Stack fuer alle Fenster, ob sie sichtbar
+// sind. Alle sichtbaren Fenster werden beim naechsten Bildaufbau komplett
+// neu gezeichnet. Muss unter dem Lock des Screens aufgerufen werden.
+func (s *Screen) updateStages() {
+	visible := true
+	for i := len(s.stack) - 1; i >= 0; i-- {
+		w := s.stack[i]
+		switch {
+		case i == len(s.stack)-1:
+			w.stage = StageFocused
+		case visible:
+			w.stage = StageVisible
+		default:
+			w.stage = StageAlive
+		}
+		if visible {
+			w.damageAll()
+		}
+		if w.flags&WindowTranslucent == 0 {
+			visible = false
+		}
+	}
+	if len(s.stack) > 0 {
+		s.window = s.stack[len(s.stack)-1]
+	} else {
+		s.window = nil
+	}
+}
+
+// Liefert alle sichtbaren Fenster, beginnend mit dem untersten.
+func (s *Screen) visibleWindows() []*Window {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	windows := make([]*Window, 0, len(s.stack))
+	for _, w := range s.stack {
+		if w.stage >= StageVisible {
+			windows = append(windows, w)
+		}
+	}
+	return windows
+}
+
+// Bestimmt das Fenster, welches die Events einer neuen Beruehrung an der
+// Position pt erhaelt. Gesucht wird vom obersten Fenster abwaerts das erste
+// Fenster, welches an dieser Stelle ein Widget hat. Modale Fenster erhalten
+// die Events in jedem Fall, die Suche endet bei ihnen.
+func (s *Screen) eventTarget(pt geom.Point) *Window {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i := len(s.stack) - 1; i >= 0; i-- {
+		w := s.stack[i]
+		if w.stage < StageVisible {
+			break
+		}
+		if w.flags&WindowModal != 0 {
+			return w
+		}
+		if w.root != nil && w.root.SelectTarget(pt) != nil {
+			return w
+		}
+	}
+	return s.window
+}
+
 // Mit Run schliesslich wird der MainEvent-Loop der Applikation gestartet,
 // das aktive Fenster wird dargestellt und mit Touch-Events beliefert.
-// Wichtig: diese Methode kehrt nicht zurück, solange die Applikation läuft.
-// Ein Aufruf dieser Methode via Go-Routine ist nicht sinnvoll, da sonst
-// die Applikation gar nie richtig läuft (siehe auch Methode Quit).
-func (s *Screen) Run() {
-	s.eventThread()
-	s.disp.Close()
-}
-
-// Mit Quit wird die Applikation (d.h. der MainEvent-Loop) terminiert.
-// Da Run im Main-Thread gestartet wird und während der Laufzeit der
-// Applikation nicht zurückkehrt, muss diese Methode aus einer weiteren
-// Go-Routine (bspw. dem Callback-Handler eines Buttons) aufgerufen werden.
+// Wichtig: diese Methode kehrt erst zurueck, wenn der Context ctx beendet
+// oder Quit aufgerufen wird. Zuvor werden alle Fenster geschlossen, die
+// Go-Routinen des Screens beendet und das Backend (Display und Touchscreen)
+// freigegeben. Retourniert wird ctx.Err(), resp. nil nach einem Aufruf von
+// Quit. Anschliessend kann mit NewScreen ein neuer Screen erzeugt werden.
+func (s *Screen) Run(ctx context.Context) error {
+	if s.replayFile != "" {
+		go func() {
+			if err := s.ReplayFile(s.replayFile, s.replaySpeed); err != nil {
+				log.Print(err)
+			}
+		}()
+	}
+	err := s.eventThread(ctx)
+	s.release()
+	return err
+}
+
+// Mit Quit wird die Applikation (d.h. der MainEvent-Loop) terminiert. Die
+// Methode kehrt sofort zurueck und kann aus beliebigen Go-Routinen (bspw.
+// dem Callback-Handler eines Buttons) auch mehrfach aufgerufen werden. Die
+// Ressourcen werden von Run freigegeben, bevor es zurueckkehrt.
 func (s *Screen) Quit() {
-	go s.quit()
+	s.quitOnce.Do(func() {
+		close(s.quitQ)
+	})
 }
 
-func (s *Screen) quit() {
-	//fmt.Printf("Screen.Quit() has been called\n")
-	if s.window != nil {
-		//fmt.Printf("Screen.Quit()   closing an open window\n")
-		s.window.Close()
-		//fmt.Printf("Screen.Quit()   done!\n")
-		s.window = nil
+// Schliesst alle Fenster, beendet die Go-Routinen des Screens und gibt das
+// Backend frei. Danach kann ein neuer Screen erzeugt werden.
+func (s *Screen) release() {
+	s.mutex.Lock()
+	s.stack = nil
+	s.window = nil
+	windows := make([]*Window, 0, len(s.windows))
+	for w := range s.windows {
+		windows = append(windows, w)
+	}
+	s.mutex.Unlock()
+	s.stopIdle()
+	s.SetKeySource(nil)
+	s.StopRecording()
+	if err := s.StopMovie(); err != nil && !errors.Is(err, ErrNoMovie) {
+		log.Print(err)
+	}
+	for _, w := range windows {
+		w.Close()
 	}
-	//fmt.Printf("Screen.Quit()   send close to the event thread\n")
-	s.eventCloseQ <- true
-	//fmt.Printf("Screen.Quit()   send close to the paint thread\n")
 	s.paintCloseQ <- true
-	//fmt.Printf("Screen.Quit()   wait for the threads to complete\n")
+	s.paintTicker.Stop()
+	binding.SetDispatcher(nil)
+	s.ui.close()
 	s.wg.Wait()
-	//fmt.Printf("Screen.Quit()   done!\n")
+	s.backend.Close()
+	screen.CompareAndSwap(s, nil)
 }
 
+// Haelt den regelmaessigen Bildaufbau an. Mit Repaint kann weiterhin
+// explizit gezeichnet werden.
 func (s *Screen) StopPaint() {
+	s.frames.mutex.Lock()
+	defer s.frames.mutex.Unlock()
+	s.frames.stopped = true
 	s.paintTicker.Stop()
 }
 
+// Nimmt den regelmaessigen Bildaufbau mit der Ziel-Bildrate wieder auf.
 func (s *Screen) ContPaint() {
-	s.paintTicker.Reset(refreshRate)
+	s.frames.mutex.Lock()
+	defer s.frames.mutex.Unlock()
+	s.frames.stopped = false
+	s.frames.throttled.Store(false)
+	s.frames.stats.Idle = false
+	s.frames.reset(s.paintTicker, s.frames.frameInterval())
 }
 
+// Zeichnet alle sichtbaren Fenster neu und uebermittelt die geaenderten
+// Bereiche an das Backend. Liegen durchscheinende Fenster auf dem Stack,
+// dann werden die sichtbaren Fenster in diesen Bereichen uebereinander
+// gelegt. Gezeichnet wird immer im UI-Thread (siehe invoke).
 func (s *Screen) Repaint() {
-	if s.window == nil || s.window.stage != StageVisible {
+	s.invoke(s.repaint)
+}
+
+// Mit WaitPainted wird gewartet, bis alle bisher empfangenen Touch- und
+// Tastatur-Ereignisse verarbeitet und die daraus resultierenden
+// Aenderungen gezeichnet wurden. Dazu wird mindestens ein Bildaufbau
+// abgewartet, damit auch Animationen und Uebergaenge weitergeschaltet
+// werden. Die Methode ist fuer automatisierte Tests gedacht
+// (siehe adaguitest) und setzt voraus, dass Run laeuft. Sie darf nicht im
+// UI-Thread aufgerufen werden. Wird ctx vorher beendet, dann wird ctx.Err()
+// retourniert.
+func (s *Screen) WaitPainted(ctx context.Context) error {
+	if err := s.syncInput(ctx); err != nil {
+		return err
+	}
+	for painted := false; !painted; {
+		frameDone := s.frames.nextFrame()
+		s.frames.wake()
+		select {
+		case <-frameDone:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		s.PostSync(func() { painted = s.painted() })
+	}
+	return nil
+}
+
+// Wartet, bis die Go-Routinen fuer die Touch- und Tastatur-Ereignisse alle
+// bisher empfangenen Ereignisse an die Fenster uebergeben haben.
+func (s *Screen) syncInput(ctx context.Context) error {
+	s.mutex.Lock()
+	keySyncQ := s.keySyncQ
+	s.mutex.Unlock()
+	for _, syncQ := range []chan chan bool{s.syncQ, keySyncQ} {
+		if syncQ == nil {
+			continue
+		}
+		done := make(chan bool)
+		select {
+		case syncQ <- done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		<-done
+	}
+	return nil
+}
+
+// Liefert true, falls keine Ereignisse mehr ausstehen und keines der
+// sichtbaren Fenster neu gezeichnet werden muss. Wird im UI-Thread
+// aufgerufen.
+func (s *Screen) painted() bool {
+	if s.pendingInput.Load() > 0 {
+		return false
+	}
+	for _, w := range s.visibleWindows() {
+		if w.needsPaint() {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Screen) repaint() {
+	s.mutex.Lock()
+	tr := s.trans
+	s.mutex.Unlock()
+	showFPS := s.ShowFPS()
+	t0 := time.Now()
+	if tr != nil {
+		frame := s.frameImage()
+		tr.paint(frame)
+		if showFPS {
+			s.updateOverlay()
+			s.drawOverlay(frame, frame.Bounds())
+		}
+		s.present(frame, []image.Rectangle{frame.Bounds()}, t0)
+		return
+	}
+	windows := s.visibleWindows()
+	switch {
+	case len(windows) == 0:
+		return
+	case len(windows) == 1 && !showFPS:
+		w := windows[0]
+		if rects := w.repaint(); len(rects) > 0 {
+			s.frames.cur.busy = true
+			s.present(w.gc.Image(), rects, t0)
+		}
+		return
+	}
+	rects := make([]image.Rectangle, 0)
+	for _, w := range windows {
+		rects = append(rects, w.repaint()...)
+	}
+	if len(rects) > 0 {
+		s.frames.cur.busy = true
+	}
+	if showFPS {
+		if r, changed := s.updateOverlay(); changed {
+			rects = append(rects, r)
+		}
+	}
+	rects = mergeRects(rects)
+	if len(rects) == 0 {
 		return
 	}
-	if s.window.Repaint() {
-		s.disp.Draw(s.window.gc.Image())
+	frame := s.frameImage()
+	for _, r := range rects {
+		op := draw.Src
+		for _, w := range windows {
+			draw.Draw(frame, r, w.gc.Image(), r.Min, op)
+			op = draw.Over
+		}
+		if showFPS {
+			s.drawOverlay(frame, r)
+		}
+	}
+	s.present(frame, rects, t0)
+}
+
+// Uebermittelt die Bereiche rects des Bildes img an das Backend und
+// nimmt sie ggf. in die laufende Filmaufnahme auf (siehe StartMovie). Mit
+// t0 wird der Beginn des Bildaufbaus fuer die Statistik angegeben.
+func (s *Screen) present(img image.Image, rects []image.Rectangle,
+	t0 time.Time) {
+	t1 := time.Now()
+	s.backend.DrawRects(img, rects)
+	s.frames.record(t1.Sub(t0), time.Since(t1))
+	s.movieMutex.Lock()
+	if s.movie != nil {
+		s.movie.capture(img, rects, t1)
+	}
+	s.movieMutex.Unlock()
+}
+
+// Liefert das Bild, in welchem mehrere Fenster oder ein Uebergang zwischen
+// zwei Fenstern fuer die Darstellung zusammengesetzt werden.
+func (s *Screen) frameImage() *image.RGBA {
+	if s.frame == nil {
+		width, height := s.Size()
+		s.frame = image.NewRGBA(image.Rect(0, 0, width, height))
 	}
+	return s.frame
 }
 
+// Der paintThread loest im Takt der Bildrate (siehe SetTargetFPS) den
+// Bildaufbau im UI-Thread aus und fuehrt die Statistik dazu nach.
 func (s *Screen) paintThread() {
 PAINT_LOOP:
 	for {
 		select {
 		case <-s.paintCloseQ:
 			break PAINT_LOOP
-		case <-s.paintTicker.C:
-			s.Repaint()
+		case <-s.frames.wakeQ:
+			s.frames.unthrottle(s.paintTicker)
+		case now := <-s.paintTicker.C:
+			var fs frameSample
+			s.PostSync(func() { fs = s.paintFrame(now) })
+			s.frames.update(s.paintTicker, now, fs)
 		}
 	}
 	s.wg.Done()
 }
 
+// Ein Bildaufbau: die Animationen werden weitergeschaltet und alle
+// sichtbaren Fenster neu gezeichnet. Retourniert werden die dafuer
+// benoetigten Zeiten. Wird im UI-Thread aufgerufen.
+func (s *Screen) paintFrame(now time.Time) frameSample {
+	t0 := time.Now()
+	s.animate(now)
+	for _, w := range s.visibleWindows() {
+		w.layout()
+	}
+	s.frames.cur = frameSample{layout: time.Since(t0)}
+	s.repaint()
+	fs := s.frames.cur
+	s.frames.cur = frameSample{}
+
+	s.animMutex.Lock()
+	fs.busy = fs.busy || len(s.animList) > 0
+	s.animMutex.Unlock()
+	return fs
+}
+
+// Im UI-Thread werden alle Aenderungen am SceneGraph vorgenommen (siehe
+// Post und PostSync).
+func (s *Screen) uiThread() {
+	s.ui.run()
+	s.wg.Done()
+}
+
 // In dieser Methode schliesslich spielt die Musik: vom Touch-Screen werden
 // laufend Events empfangen, ggf. 'veredelt' (bspw. werden hier LongPress,
 // Tap oder DoubleTap Events generiert) und dem aktiven Fenster zur
 // Verarbeitung weitergeleitet. Die Positionsdaten aus den Touch-Events
 // beziehen sich auf den gesamten Bildschirm. Die Transformation von
 // Koordianten in Objekt-relative Daten erfolgt im Objekt Window!
-// Gestoppt wird dieser Thread durch das Schliessen der Event-Queue.
-func (s *Screen) eventThread() {
+// Fuer die Suche nach dem Zielfenster wird der SceneGraph im UI-Thread
+// durchsucht, alle uebrigen Daten gehoeren ausschliesslich diesem Thread.
+// Gestoppt wird dieser Thread durch das Beenden von ctx, durch Quit oder
+// durch das Schliessen der Event-Queue des Backends.
+func (s *Screen) eventThread(ctx context.Context) error {
 	var evt, tapEvt touch.Event
 	var seqNumber int = 0
+	var target *Window
+	var tracker touch.VelocityTracker
+	var pressTime time.Time
+	var cfg touch.GestureConfig
+	var waking bool
 
-EVENT_LOOP:
 	for {
 		select {
-		case <-s.eventCloseQ:
-			break EVENT_LOOP
-		case tchEvt := <-s.touch.EventQ:
-			//fmt.Printf("[%d]: %10s: %v -> %v\n", tchEvt.Time.UnixMilli(),
-			//	tchEvt.Type, tchEvt.TouchRawPos, tchEvt.TouchPos)
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.quitQ:
+			return nil
+		case done := <-s.syncQ:
+			close(done)
+		case seqNr := <-s.longPressQ:
+			if target != nil && seqNr == seqNumber &&
+				evt.Type != touch.TypeRelease &&
+				evt.InitPos.Distance(evt.Pos) <= cfg.NearThreshold {
+				evt.LongPressed = true
+				newEvent := evt
+				newEvent.Type = touch.TypeLongPress
+				newEvent.Time = time.Now()
+				s.sendEvent(target, newEvent)
+			}
+		case tchEvt, ok := <-s.backend.EventQ():
+			if !ok {
+				return nil
+			}
+			//fmt.Printf("[%d]: %10s: %v\n", tchEvt.Time.UnixMilli(),
+			//	tchEvt.Type, tchEvt.Pos)
+			woke := s.registerActivity()
+			// Waehrend eines Uebergangs zwischen zwei Fenstern werden keine
+			// Events verarbeitet. Eine laufende Geste wird mit einem
+			// Release-Event abgebrochen, damit das bisherige Ziel nicht im
+			// gedrueckten Zustand verbleibt.
+			if s.TransitionRunning() {
+				if target != nil && evt.Type != touch.TypeRelease {
+					cancelEvt := evt
+					cancelEvt.Type = touch.TypeRelease
+					cancelEvt.Time = time.Now()
+					cancelEvt.Canceled = true
+					s.sendEvent(target, cancelEvt)
+					evt.Type = touch.TypeRelease
+					tapEvt = touch.Event{}
+				}
+				target = nil
+				continue
+			}
+			// Die erste Beruehrung nach einer Phase der Inaktivitaet weckt
+			// den Screen nur auf und wird nicht weitergeleitet.
+			if woke && tchEvt.Type == PenPress {
+				waking = true
+			}
+			if waking {
+				if tchEvt.Type == PenRelease {
+					waking = false
+				}
+				target = nil
+				continue
+			}
+			if tchEvt.Type == PenPress {
+				s.PostSync(func() {
+					target = s.eventTarget(tchEvt.Pos)
+					if target != nil {
+						cfg = s.gestureConfigAt(target, tchEvt.Pos)
+					}
+				})
+			}
+			if target == nil {
+				continue
+			}
 			switch tchEvt.Type {
-			case adatft.PenPress:
+			case PenPress:
 				seqNumber++
 				evt.Type = touch.TypePress
 				evt.SeqNumber = seqNumber
 				evt.LongPressed = false
 				evt.InitTime = time.Now()
-				evt.InitPos = geom.NewPoint(tchEvt.X, tchEvt.Y)
+				evt.InitPos = tchEvt.Pos
 				evt.Time = evt.InitTime
 				evt.Pos = evt.InitPos
-
-				// Setze eine verzoegerte Go-Routine zur Erkennung des Events
-				// 'LongPress'.
-				//
-				go func(seqNr int) {
-					time.Sleep(touch.LongPressThreshold)
-					if seqNr == seqNumber &&
-						evt.Type != touch.TypeRelease &&
-						evt.InitPos.Distance(evt.Pos) <= touch.NearThreshold {
-						evt.LongPressed = true
-						newEvent := evt
-						newEvent.Type = touch.TypeLongPress
-						newEvent.Time = time.Now()
-						s.window.eventQ <- newEvent
+				pressTime = tchEvt.Time
+				tracker.Window = cfg.VelocityWindow
+				tracker.Reset()
+				tracker.Add(tchEvt.Pos, tchEvt.Time)
+
+				// Nach Ablauf der Wartezeit fuer einen 'LongPress' wird die
+				// Sequenznummer ueber longPressQ an diesen Thread gemeldet.
+				// Die Pruefung, ob der Finger noch aufliegt, erfolgt damit
+				// hier und nicht in einer eigenen Go-Routine.
+				seqNr := seqNumber
+				time.AfterFunc(cfg.LongPressThreshold, func() {
+					select {
+					case s.longPressQ <- seqNr:
+					default:
 					}
-				}(seqNumber)
-				s.window.eventQ <- evt
+				})
+				s.sendEvent(target, evt)
 
-			case adatft.PenDrag:
+			case PenDrag:
 				evt.Type = touch.TypeDrag
 				evt.Time = time.Now()
-				evt.Pos = geom.NewPoint(tchEvt.X, tchEvt.Y)
-				s.window.eventQ <- evt
+				evt.Pos = tchEvt.Pos
+				tracker.Add(tchEvt.Pos, tchEvt.Time)
+				s.sendEvent(target, evt)
 
-			case adatft.PenRelease:
+			case PenRelease:
 				evt.Type = touch.TypeRelease
 				evt.Time = time.Now()
-				evt.Pos = geom.NewPoint(tchEvt.X, tchEvt.Y)
-				s.window.eventQ <- evt
-
-				if evt.InitPos.Distance(evt.Pos) <= touch.NearThreshold {
+				evt.Pos = tchEvt.Pos
+				tracker.Add(tchEvt.Pos, tchEvt.Time)
+				s.sendEvent(target, evt)
+
+				dur := tchEvt.Time.Sub(pressTime)
+				if evt.InitPos.Distance(evt.Pos) <= cfg.NearThreshold {
+					// Wurde zu lange gedrueckt, dann ist es kein Tap mehr
+					// (und der naechste Tap auch kein DoubleTap).
+					if dur > cfg.TapDuration {
+						tapEvt = touch.Event{}
+						continue
+					}
 
 					// An dieser Stelle steht fest: es wurde ein korrekter Tap
 					// erkannt. Die Frage ist noch: war es ein DoubleTap?
 					if tapEvt.Type == touch.TypeTap &&
-						evt.Time.Sub(tapEvt.Time) < touch.DoubleTapDuration &&
-						evt.Pos.Distance(tapEvt.Pos) <= touch.NearThreshold {
+						evt.Time.Sub(tapEvt.Time) < cfg.DoubleTapDuration &&
+						evt.Pos.Distance(tapEvt.Pos) <= cfg.NearThreshold {
 						tapEvt = evt
 						tapEvt.Type = touch.TypeDoubleTap
 					} else {
 						tapEvt = evt
 						tapEvt.Type = touch.TypeTap
 					}
-					s.window.eventQ <- tapEvt
+					s.sendEvent(target, tapEvt)
+				} else {
+					// Andernfalls wurde der Finger bewegt: war die Bewegung
+					// schnell genug fuer einen Swipe oder Fling?
+					for _, gestEvt := range gestureEvents(evt, dur, &tracker, cfg) {
+						s.sendEvent(target, gestEvt)
+					}
 				}
 			}
 		}
 	}
-	s.wg.Done()
-	//fmt.Printf("Screen.eventThread()   exits\n")
 }
 
+// Mit StartAnimation wird die Animation a gestartet, resp. neu gestartet,
+// falls sie bereits laeuft. Alle laufenden Animationen werden vom
+// paintThread vor jedem Bildaufbau weitergeschaltet.
 func (s *Screen) StartAnimation(a *Animation) {
-
+	s.animMutex.Lock()
+	defer s.animMutex.Unlock()
+	a.reset(time.Now())
+	if a.running {
+		return
+	}
+	a.running = true
+	s.animList = append(s.animList, a)
+	s.frames.wake()
 }
 
+// Haelt die Animation a an. Die Callback-Funktion OnComplete wird dabei
+// nicht aufgerufen.
 func (s *Screen) StopAnimation(a *Animation) {
+	s.animMutex.Lock()
+	defer s.animMutex.Unlock()
+	s.removeAnimation(a)
+}
 
+func (s *Screen) removeAnimation(a *Animation) {
+	for i, anim := range s.animList {
+		if anim == a {
+			s.animList = append(s.animList[:i], s.animList[i+1:]...)
+			break
+		}
+	}
+	a.running = false
+}
+
+// Schaltet alle laufenden Animationen auf den Zeitpunkt now weiter. Die
+// Tick-Funktionen werden im UI-Thread aufgerufen, damit sie nicht mit der
+// Verarbeitung von Events kollidieren.
+func (s *Screen) animate(now time.Time) {
+	s.animMutex.Lock()
+	if len(s.animList) == 0 {
+		s.animMutex.Unlock()
+		return
+	}
+	animList := make([]*Animation, len(s.animList))
+	copy(animList, s.animList)
+	s.animMutex.Unlock()
+
+	for _, a := range animList {
+		s.animMutex.Lock()
+		if !a.running {
+			s.animMutex.Unlock()
+			continue
+		}
+		s.animMutex.Unlock()
+		if a.step(now) {
+			continue
+		}
+		s.animMutex.Lock()
+		s.removeAnimation(a)
+		s.animMutex.Unlock()
+		if a.OnComplete != nil {
+			a.OnComplete()
+		}
+	}
 }
@@ -0,0 +1,115 @@
+package adagui_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+	"github.com/stefan-muehlebach/adatft"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+func TestScreenRestart(t *testing.T) {
+	adaguitest.Shutdown()
+	defer adaguitest.Shutdown()
+
+	for i, cancelCtx := range []bool{true, false} {
+		b := adagui.NewMemBackend(100, 100)
+		s, err := adagui.NewScreenWithBackend(b)
+		if err != nil {
+			t.Fatalf("run %d: %v", i, err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- s.Run(ctx) }()
+
+		btn := adagui.NewButton(40, 20)
+		btn.SetPos(geom.Point{X: 10, Y: 10})
+		w := s.NewWindow()
+		w.SetRoot(btn)
+		s.SetWindow(w)
+		b.Press(geom.Point{X: 20, Y: 20})
+		b.Release(geom.Point{X: 20, Y: 20})
+		// Ein nicht angezeigtes Fenster wird ebenfalls geschlossen.
+		s.NewWindow()
+
+		var want error
+		if cancelCtx {
+			cancel()
+			want = context.Canceled
+		} else {
+			s.Quit()
+			s.Quit()
+		}
+		select {
+		case err := <-done:
+			if !errors.Is(err, want) {
+				t.Errorf("run %d: Run returned %v, want %v", i, err, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("run %d: Run did not return", i)
+		}
+		cancel()
+		if cur := adagui.CurrentScreen(); cur != nil {
+			t.Errorf("run %d: CurrentScreen is %p after Run", i, cur)
+		}
+		if !b.Closed() {
+			t.Errorf("run %d: backend not closed", i)
+		}
+		if w.Stage() != adagui.StageDead {
+			t.Errorf("run %d: window stage is %v, want StageDead", i, w.Stage())
+		}
+		w.Close()
+	}
+}
+
+func TestScreenOptions(t *testing.T) {
+	adaguitest.Shutdown()
+	defer adaguitest.Shutdown()
+
+	var opts adagui.ScreenOptions
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	opts.RegisterFlags(fs)
+	if err := fs.Parse([]string{"-backend", "unknown"}); err != nil {
+		t.Fatal(err)
+	}
+	if opts.ReplaySpeed != 1.0 {
+		t.Errorf("ReplaySpeed is %v, want 1.0", opts.ReplaySpeed)
+	}
+	if _, err := adagui.NewScreen(adatft.Rotate000, opts); !errors.Is(err,
+		adagui.ErrUnknownBackend) {
+		t.Errorf("NewScreen returned %v, want ErrUnknownBackend", err)
+	}
+
+	if err := fs.Parse([]string{"-backend", "mem", "-showfps"}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := adagui.NewScreen(adatft.Rotate000, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.ShowFPS() {
+		t.Errorf("ShowFPS is false, want true")
+	}
+	s.Quit()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := s.Run(ctx); err != nil {
+		t.Errorf("Run returned %v", err)
+	}
+}
+
+func TestMemBackendRotation(t *testing.T) {
+	b := adagui.NewMemBackend(100, 50)
+	if got := b.Rotation(); got != adagui.Rotate000 {
+		t.Errorf("default rotation is %v, want %v", got, adagui.Rotate000)
+	}
+	b.SetRotation(adagui.Rotate090)
+	if got := b.Rotation(); got != adagui.Rotate090 {
+		t.Errorf("rotation is %v, want %v", got, adagui.Rotate090)
+	}
+}
@@ -22,7 +22,7 @@ func (e *SelectEmbed) Init(node Node, extData binding.Bool) {
     } else {
         e.BindVar = extData
     }
-    e.BindVar.AddListener(e)
+    e.BindVar.AddCallback(onUI(e.DataChanged))
 }
 
 // Ermittelt den Status des Embed.
@@ -45,7 +45,8 @@ func (e *SelectEmbed) OnInputEvent(evt touch.Event) {
     }
 }
 
-// Wird autom. aufgerufen, sobald der Wert von 'BindVar' veraendert wird.
+// Wird autom. (im UI-Thread) aufgerufen, sobald der Wert von 'BindVar'
+// veraendert wird.
 func (e *SelectEmbed) DataChanged(BindVar binding.DataItem) {
     if e.node == nil {
         return
@@ -0,0 +1,132 @@
+package adagui
+
+import (
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/stefan-muehlebach/adagui/touch"
+)
+
+// Erstellt die Datei recordFile fuer die Aufzeichnung (siehe
+// ScreenOptions.Record). Ohne Datei wird nil retourniert.
+func openRecording(recordFile string) (*os.File, error) {
+	if recordFile == "" {
+		return nil, nil
+	}
+	return os.Create(recordFile)
+}
+
+// Mit StartRecording werden ab sofort alle Touch-Events, welche der Screen
+// an die Fenster verteilt, nach w geschrieben (siehe touch.SessionWriter).
+// Eine laufende Aufzeichnung wird dabei beendet.
+func (s *Screen) StartRecording(w io.Writer) {
+	s.StopRecording()
+	s.recMutex.Lock()
+	defer s.recMutex.Unlock()
+	s.recorder = touch.NewSessionWriter(w)
+}
+
+// Beendet die Aufzeichnung der Touch-Events. Wurde die Aufzeichnung von
+// NewScreen gestartet (siehe ScreenOptions.Record), wird die Datei
+// geschlossen.
+func (s *Screen) StopRecording() {
+	s.recMutex.Lock()
+	defer s.recMutex.Unlock()
+	s.recorder = nil
+	if s.recordFile != nil {
+		s.recordFile.Close()
+		s.recordFile = nil
+	}
+}
+
+// Liefert true, falls die Touch-Events aktuell aufgezeichnet werden.
+func (s *Screen) Recording() bool {
+	s.recMutex.Lock()
+	defer s.recMutex.Unlock()
+	return s.recorder != nil
+}
+
+// Zeichnet das Event evt auf (sofern eine Aufzeichnung laeuft) und sendet
+// es an das Fenster w. Ist das Fenster bereits geschlossen, wird das Event
+// verworfen.
+func (s *Screen) sendEvent(w *Window, evt touch.Event) {
+	s.recMutex.Lock()
+	if s.recorder != nil {
+		if err := s.recorder.Write(evt); err != nil {
+			log.Printf("recording stopped: %v", err)
+			s.recorder = nil
+		}
+	}
+	s.recMutex.Unlock()
+	s.deliverEvent(w, evt)
+}
+
+// Sendet das Event evt ohne Aufzeichnung an das Fenster w.
+func (s *Screen) deliverEvent(w *Window, evt touch.Event) {
+	s.pendingInput.Add(1)
+	select {
+	case w.eventQ <- evt:
+	case <-w.eventCloseQ:
+		s.pendingInput.Add(-1)
+	}
+}
+
+// Spielt die Touch-Events aus r (siehe touch.SessionReader) mit dem
+// originalen Zeitverlauf ab. Mit speed kann die Wiedergabe beschleunigt
+// (bspw. 2.0) oder verlangsamt (bspw. 0.5) werden. Die Zeitstempel der
+// Events werden auf den Zeitpunkt der Wiedergabe umgerechnet. Laeuft gerade
+// ein Uebergang zwischen zwei Fenstern, wird mit der Wiedergabe gewartet,
+// bis er abgeschlossen ist. Abgespielte Events werden nicht erneut
+// aufgezeichnet. Die Methode kehrt erst nach dem letzten Event zurueck.
+func (s *Screen) Replay(r io.Reader, speed float64) error {
+	if speed <= 0.0 {
+		speed = 1.0
+	}
+	scale := func(d time.Duration) time.Duration {
+		return time.Duration(float64(d) / speed)
+	}
+	sr := touch.NewSessionReader(r)
+	var target *Window
+	var t0 time.Time
+	start := time.Now()
+	for {
+		evt, err := sr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if t0.IsZero() {
+			t0 = evt.Time
+		}
+		time.Sleep(time.Until(start.Add(scale(evt.Time.Sub(t0)))))
+		for s.TransitionRunning() {
+			time.Sleep(refreshRate)
+			start = start.Add(refreshRate)
+		}
+
+		now := time.Now()
+		evt.InitTime = now.Add(-scale(evt.Time.Sub(evt.InitTime)))
+		evt.Time = now
+		if evt.Type == touch.TypePress || target == nil {
+			s.PostSync(func() { target = s.eventTarget(evt.Pos) })
+		}
+		if target == nil {
+			continue
+		}
+		s.deliverEvent(target, evt)
+	}
+}
+
+// Wie Replay, die Events werden jedoch aus der Datei fileName gelesen.
+func (s *Screen) ReplayFile(fileName string, speed float64) error {
+	fh, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+	return s.Replay(fh, speed)
+}
@@ -0,0 +1,81 @@
+package adagui_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+	"github.com/stefan-muehlebach/adagui/touch"
+)
+
+func TestRecordReplay(t *testing.T) {
+	btn := adagui.NewTextButton("Replay")
+	rec := adaguitest.NewRecorder()
+	btn.SetTouchFunc(rec.Record, touch.TypePress, touch.TypeRelease,
+		touch.TypeTap)
+	h := adaguitest.New(t, centered(btn))
+
+	var buf bytes.Buffer
+	h.Screen.StartRecording(&buf)
+	h.Tap(h.Center(btn))
+	h.Screen.StopRecording()
+	if h.Screen.Recording() {
+		t.Fatalf("still recording after StopRecording")
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 3 {
+		t.Fatalf("recorded %d events, want 3:\n%s", n, buf.String())
+	}
+
+	// Abgespielte Events werden nicht erneut aufgezeichnet.
+	var again bytes.Buffer
+	h.Screen.StartRecording(&again)
+	defer h.Screen.StopRecording()
+	rec.Reset()
+	if err := h.Screen.Replay(&buf, 4.0); err != nil {
+		t.Fatal(err)
+	}
+	h.WaitIdle()
+	want := "[Press Release Tap]"
+	if got := fmt.Sprint(rec.Types()); got != want {
+		t.Errorf("replay delivered %s, want %s", got, want)
+	}
+	if again.Len() != 0 {
+		t.Errorf("replayed events recorded again:\n%s", again.String())
+	}
+}
+
+func TestReplaySpeed(t *testing.T) {
+	btn := adagui.NewButton(60, 40)
+	rec := adaguitest.NewRecorder()
+	btn.SetTouchFunc(rec.Record, touch.TypePress, touch.TypeRelease)
+	h := adaguitest.New(t, centered(btn))
+
+	var buf bytes.Buffer
+	sw := touch.NewSessionWriter(&buf)
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	pt := h.Center(btn)
+	sw.Write(touch.Event{Type: touch.TypePress, InitTime: t0, InitPos: pt,
+		Time: t0, Pos: pt})
+	sw.Write(touch.Event{Type: touch.TypeRelease, InitTime: t0, InitPos: pt,
+		Time: t0.Add(800 * time.Millisecond), Pos: pt})
+
+	start := time.Now()
+	if err := h.Screen.Replay(&buf, 4.0); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 180*time.Millisecond || d > 600*time.Millisecond {
+		t.Errorf("replay took %v, want about 200ms", d)
+	}
+	h.WaitIdle()
+	evts := rec.Events()
+	if len(evts) != 2 {
+		t.Fatalf("got %v, want Press and Release", rec.Types())
+	}
+	if d := evts[1].Time.Sub(evts[1].InitTime); d != 200*time.Millisecond {
+		t.Errorf("press duration is %v after replay, want 200ms", d)
+	}
+}
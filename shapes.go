@@ -44,10 +44,14 @@ func NewPoint() (*Point) {
     p.Wrapper = p
     p.Shape.Init()
     p.PropertyEmbed.InitByName("Point")
-    p.SetMinSize(geom.Point{p.Width(), p.Height()})
+    p.OnThemeChanged()
     return p
 }
 
+func (p *Point) OnThemeChanged() {
+    p.SetMinSize(geom.Point{X: p.Width(), Y: p.Height()})
+}
+
 func (p *Point) Paint(gc *gg.Context) {
     Debugf(Painting, "")
     mp := p.LocalBounds().Center()
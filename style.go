@@ -0,0 +1,131 @@
+package adagui
+
+import (
+	"image"
+	"math"
+
+	"github.com/stefan-muehlebach/adagui/props"
+	"github.com/stefan-muehlebach/gg"
+	"github.com/stefan-muehlebach/gg/geom"
+	"golang.org/x/image/draw"
+)
+
+// Maximale Anzahl Ebenen, mit welchen der weiche Rand eines Schattens
+// gezeichnet wird.
+const maxShadowLayers = 16
+
+// Fuellt den aktuellen Pfad, welcher das Rechteck r (in lokalen
+// Koordinaten) umschliesst, und zeichnet anschliessend den Rand mit der
+// aktuellen Farbe. Ist ein Bild angegeben, wird dieses anstelle der
+// Fuellfarbe auf r gestreckt (siehe props.NineSlice), andernfalls wird mit
+// dem Farbverlauf grad gefuellt (sofern nicht nil).
+func fillStrokeStyled(gc *gg.Context, r geom.Rectangle, grad *props.Gradient,
+	img *props.NineSlice) {
+	if img != nil && img.Image != nil {
+		drawNineSlice(gc, img, r)
+		gc.Stroke()
+		return
+	}
+	if grad != nil {
+		gc.SetFillStyle(gradientPattern(gc, grad, r))
+	}
+	gc.FillStroke()
+}
+
+// Erzeugt aus dem Farbverlauf grad ein Muster fuer das Rechteck r. Da die
+// Muster von gg in Geraetekoordinaten arbeiten, wird die aktuelle
+// Transformation von gc beruecksichtigt.
+func gradientPattern(gc *gg.Context, grad *props.Gradient,
+	r geom.Rectangle) gg.Pattern {
+	var pattern gg.Gradient
+
+	m := gc.Matrix()
+	switch grad.Type {
+	case props.RadialGradient:
+		c := m.Transform(r.RelPos(grad.Center.X, grad.Center.Y))
+		dx, dy := m.TransformVector(grad.Radius*max(r.Dx(), r.Dy()), 0)
+		pattern = gg.NewRadialGradient(c.X, c.Y, 0, c.X, c.Y,
+			math.Hypot(dx, dy))
+	default:
+		p0 := m.Transform(r.RelPos(grad.From.X, grad.From.Y))
+		p1 := m.Transform(r.RelPos(grad.To.X, grad.To.Y))
+		pattern = gg.NewLinearGradient(p0.X, p0.Y, p1.X, p1.Y)
+	}
+	for _, stop := range grad.Stops {
+		pattern.AddColorStop(stop.Offset, stop.Color)
+	}
+	return pattern
+}
+
+// Streckt das Bild ns auf das Rechteck r. Die Ecken (gemaess den Insets)
+// werden in Originalgroesse gezeichnet, die Raender nur in einer Richtung
+// und der mittlere Teil in beide Richtungen gestreckt. Wie beim Bild eines
+// Panels wird eine bestehende Clipping-Maske beruecksichtigt.
+func drawNineSlice(gc *gg.Context, ns *props.NineSlice, r geom.Rectangle) {
+	var opts *draw.Options
+
+	if mask := gc.Mask(); mask != nil {
+		opts = &draw.Options{DstMask: mask}
+	}
+	dst := gc.Image().(*image.RGBA)
+	src := ns.Image.Bounds()
+	in := ns.Insets
+	sx := [4]int{src.Min.X, src.Min.X + int(math.Round(in.Left)),
+		src.Max.X - int(math.Round(in.Right)), src.Max.X}
+	sy := [4]int{src.Min.Y, src.Min.Y + int(math.Round(in.Top)),
+		src.Max.Y - int(math.Round(in.Bottom)), src.Max.Y}
+	dx := [4]float64{r.Min.X, r.Min.X + float64(sx[1]-sx[0]),
+		r.Max.X - float64(sx[3]-sx[2]), r.Max.X}
+	dy := [4]float64{r.Min.Y, r.Min.Y + float64(sy[1]-sy[0]),
+		r.Max.Y - float64(sy[3]-sy[2]), r.Max.Y}
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			sr := image.Rect(sx[i], sy[j], sx[i+1], sy[j+1])
+			dw, dh := dx[i+1]-dx[i], dy[j+1]-dy[j]
+			if sr.Empty() || dw <= 0 || dh <= 0 {
+				continue
+			}
+			m := gc.Matrix().Translate(geom.Point{X: dx[i], Y: dy[j]}).
+				Scale(dw/float64(sr.Dx()), dh/float64(sr.Dy())).
+				Translate(geom.Point{X: float64(-sr.Min.X), Y: float64(-sr.Min.Y)})
+			draw.ApproxBiLinear.Transform(dst, m.AsAff3(), ns.Image, sr,
+				draw.Over, opts)
+		}
+	}
+}
+
+// Zeichnet den Schatten sh unter das Rechteck r (in lokalen Koordinaten)
+// mit dem Eckradius radius. Der weiche Rand wird mit mehreren, leicht
+// transparenten Ebenen angenaehert. Da der Schatten ausserhalb des Nodes
+// liegen kann, wird der gezeichnete Bereich (wie beim Fokus-Rahmen)
+// entsprechend vergroessert.
+func (m *Embed) paintShadow(gc *gg.Context, sh *props.Shadow,
+	r geom.Rectangle, radius float64) {
+	if sh == nil || sh.Color.A == 0 {
+		return
+	}
+	gc.Push()
+	defer gc.Pop()
+	r = r.Add(sh.Offset)
+	n := min(max(int(math.Ceil(sh.Blur)), 1), maxShadowLayers)
+	alpha := 1.0 - math.Pow(1.0-float64(sh.Color.A)/255.0, 1.0/float64(n))
+	gc.SetFillColor(sh.Color.Alpha(alpha))
+	for i := 0; i < n; i++ {
+		// Die Ebenen reichen von Blur/2 innerhalb bis Blur/2 ausserhalb
+		// des verschobenen Rechtecks.
+		d := sh.Blur * ((float64(i)+0.5)/float64(n) - 0.5)
+		q := r.Inset(d, d)
+		if q.Empty() {
+			continue
+		}
+		gc.DrawRoundedRectangle(q.Min.X, q.Min.Y, q.Dx(), q.Dy(),
+			max(radius-d, 0))
+		gc.Fill()
+	}
+	if w := m.window(); w != nil && w.gc == gc {
+		q := r.Inset(-0.5*sh.Blur, -0.5*sh.Blur)
+		rect := boundingRect(gc.Matrix().Translate(q.Min), q.Size())
+		m.paintRect = m.paintRect.Union(rect)
+	}
+}
@@ -0,0 +1,104 @@
+package adagui_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+	"github.com/stefan-muehlebach/adagui/props"
+	"github.com/stefan-muehlebach/gg/colors"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+// Liefert die Farbe des Pixels (x, y) ohne Alpha-Vormultiplikation.
+func pixelAt(img image.Image, x, y int) colors.RGBA {
+	c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+	return colors.RGBA{R: c.R, G: c.G, B: c.B, A: c.A}
+}
+
+func TestPanelGradient(t *testing.T) {
+	pnl := adagui.NewPanel(60, 100)
+	pnl.SetFillGradient(&props.Gradient{
+		Type: props.LinearGradient,
+		To:   geom.Point{X: 0, Y: 1},
+		Stops: []props.ColorStop{
+			{Offset: 0, Color: colors.Red},
+			{Offset: 1, Color: colors.Blue},
+		},
+	})
+	img := adagui.RenderNode(pnl, 1.0)
+	top, bottom := pixelAt(img, 30, 5), pixelAt(img, 30, 94)
+	if top.R <= top.B {
+		t.Errorf("top of the gradient is %v, want mostly red", top)
+	}
+	if bottom.B <= bottom.R {
+		t.Errorf("bottom of the gradient is %v, want mostly blue", bottom)
+	}
+}
+
+// Bei einem Nine-Slice-Bild bleiben die Ecken ungestreckt.
+func TestPanelNineSlice(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 9, 9))
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 9; x++ {
+			col := colors.Blue
+			switch {
+			case (x < 3 || x >= 6) && (y < 3 || y >= 6):
+				col = colors.Red
+			case x < 3 || x >= 6 || y < 3 || y >= 6:
+				col = colors.Lime
+			}
+			src.Set(x, y, col)
+		}
+	}
+	pnl := adagui.NewPanel(60, 40)
+	pnl.SetBorderWidth(0)
+	pnl.SetBackgroundImage(props.NewNineSlice(src,
+		props.Insets{Top: 3, Right: 3, Bottom: 3, Left: 3}))
+	img := adagui.RenderNode(pnl, 1.0)
+
+	for _, tc := range []struct {
+		x, y int
+		want colors.RGBA
+	}{
+		{1, 1, colors.Red},
+		{58, 38, colors.Red},
+		{30, 1, colors.Lime},
+		{1, 20, colors.Lime},
+		{30, 20, colors.Blue},
+	} {
+		if got := pixelAt(img, tc.x, tc.y); got != tc.want {
+			t.Errorf("pixel (%d,%d) is %v, want %v", tc.x, tc.y, got, tc.want)
+		}
+	}
+}
+
+// Der Schatten liegt ausserhalb des Buttons und muss beim Entfernen
+// ebenfalls neu gezeichnet werden.
+func TestButtonShadow(t *testing.T) {
+	btn := adagui.NewTextButton("Shadow")
+	h := adaguitest.New(t, centered(btn))
+
+	var pt image.Point
+	h.Do(func() { pt = btn.Rect().Max.Int().Add(image.Pt(8, 8)) })
+	bg := pixelAt(h.Image(), pt.X, pt.Y)
+
+	h.Do(func() {
+		btn.SetDropShadow(&props.Shadow{
+			Offset: geom.Point{X: 12, Y: 12},
+			Color:  colors.Red,
+		})
+	})
+	h.WaitIdle()
+	if got := pixelAt(h.Image(), pt.X, pt.Y); got != colors.Red {
+		t.Errorf("shadow pixel is %v, want %v", got, colors.Red)
+	}
+
+	h.Do(func() { btn.Properties().DelShadow(props.DropShadow) })
+	h.WaitIdle()
+	if got := pixelAt(h.Image(), pt.X, pt.Y); got != bg {
+		t.Errorf("pixel is %v after removing the shadow, want %v", got, bg)
+	}
+}
@@ -0,0 +1,184 @@
+package adagui
+
+import (
+	"sync"
+
+	"github.com/stefan-muehlebach/adagui/props"
+)
+
+func init() {
+	props.AddChangeListener(propsListener{})
+}
+
+// Mit SetTheme wird das Theme name aktiviert (bspw. props.DefaultTheme,
+// "Night", "HighContrast" oder der Name einer lokalen Datei, siehe
+// props.SetTheme). Gelesen wird das Theme in der aufrufenden Go-Routine,
+// aktiviert im UI-Thread (siehe invoke): alle Widgets saemtlicher
+// Fenster erhalten die Werte des neuen Themes, Widgets mit abgeleiteten
+// Groessen (siehe ThemeHandler) werden neu vermessen und alle Fenster neu
+// angeordnet und gezeichnet. Widgets, welche keinem Fenster angehoeren,
+// erhalten zwar die neuen Werte, muessen ihre Groesse jedoch selber
+// anpassen. Kann das Theme nicht gelesen werden, bleibt das aktuelle Theme
+// aktiv und der Fehler wird retourniert. Fehler in einzelnen Properties
+// werden ebenfalls retourniert, das Theme wird in diesem Fall aber
+// trotzdem aktiviert.
+func (s *Screen) SetTheme(name string) error {
+	// Fehler in einzelnen Properties verhindern die Installation des
+	// Themes nicht; nur wenn es gar nicht gelesen werden konnte, bleibt
+	// das bisherige Theme aktiv.
+	propsMap, err := props.NewThemeMap(name)
+	if propsMap == nil {
+		return err
+	}
+	s.mutex.Lock()
+	s.theme = name
+	s.mutex.Unlock()
+	s.invoke(func() {
+		props.InstallTheme(name, propsMap)
+		s.themeChanged()
+	})
+	return err
+}
+
+// Liefert den Namen des aktuellen Themes.
+func (s *Screen) Theme() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.theme
+}
+
+// Meldet allen Fenstern den Wechsel des Themes. Wird im UI-Thread
+// aufgerufen.
+func (s *Screen) themeChanged() {
+	s.mutex.Lock()
+	windows := make([]*Window, 0, len(s.windows))
+	for w := range s.windows {
+		windows = append(windows, w)
+	}
+	s.mutex.Unlock()
+	for _, w := range windows {
+		if w.root != nil {
+			themeChanged(w.root)
+		}
+		w.damageAll()
+	}
+}
+
+// Ruft fuer n und alle Kinder OnThemeChanged auf und markiert alle
+// Container fuer ein neues Layout.
+func themeChanged(n Node) {
+	if h, ok := n.(ThemeHandler); ok {
+		h.OnThemeChanged()
+	}
+	if c := asContainer(n); c != nil {
+		for e := c.ChildList.Front(); e != nil; e = e.Next() {
+			themeChanged(e.Value.(*Embed).Wrapper)
+		}
+		c.InvalidateLayout()
+	}
+}
+
+// Leitet Aenderungen an den Properties an den laufenden Screen weiter.
+type propsListener struct{}
+
+func (propsListener) PropertiesChanged(p *props.Properties, layout bool) {
+	if s := CurrentScreen(); s != nil {
+		s.propsChanged(p, layout)
+	}
+}
+
+// Aenderungen an Properties, welche noch nicht an die Nodes weitergegeben
+// wurden. Mehrere Aenderungen werden gesammelt und gemeinsam im UI-Thread
+// verarbeitet.
+type propsChanges struct {
+	mutex   sync.Mutex
+	pending map[*props.Properties]bool
+}
+
+func (s *Screen) propsChanged(p *props.Properties, layout bool) {
+	c := &s.propsChanges
+	c.mutex.Lock()
+	first := len(c.pending) == 0
+	if c.pending == nil {
+		c.pending = make(map[*props.Properties]bool)
+	}
+	c.pending[p] = c.pending[p] || layout
+	c.mutex.Unlock()
+	if first {
+		s.Post(s.applyPropsChanges)
+	}
+}
+
+// Markiert alle Nodes, deren Properties von einer Aenderung betroffen sind,
+// fuer das Neuzeichnen. Wurden Schriften oder Groessen geaendert, werden
+// die Nodes zudem (via ThemeHandler) neu vermessen. Properties auf
+// Objektebene betreffen nur ihren eigenen Node (siehe ownProps) und werden
+// ignoriert, solange dieser keinem SceneGraph angehoert; einzig fuer
+// Aenderungen auf Typenebene werden alle Fenster durchsucht.
+func (s *Screen) applyPropsChanges() {
+	c := &s.propsChanges
+	c.mutex.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.mutex.Unlock()
+
+	for p, layout := range pending {
+		switch owner := p.Owner().(type) {
+		case nil:
+			continue
+		case Node:
+			nodePropsChanged(owner, layout)
+		}
+		// Properties auf Objektebene, deren Node keinem SceneGraph
+		// angehoert, betreffen keines der Fenster.
+		delete(pending, p)
+	}
+	if len(pending) == 0 {
+		return
+	}
+	s.mutex.Lock()
+	windows := make([]*Window, 0, len(s.windows))
+	for w := range s.windows {
+		windows = append(windows, w)
+	}
+	s.mutex.Unlock()
+	for _, w := range windows {
+		if w.root != nil {
+			propsChanged(w.root, pending)
+		}
+	}
+}
+
+func propsChanged(n Node, pending map[*props.Properties]bool) {
+	m := n.Wrappee()
+	affected, layout := false, false
+	for p, l := range pending {
+		if m.Properties().Inherits(p) {
+			affected = true
+			layout = layout || l
+		}
+	}
+	if affected {
+		nodePropsChanged(n, layout)
+	}
+	if c := asContainer(n); c != nil {
+		for e := c.ChildList.Front(); e != nil; e = e.Next() {
+			propsChanged(e.Value.(*Embed).Wrapper, pending)
+		}
+	}
+}
+
+func nodePropsChanged(n Node, layout bool) {
+	if h, ok := n.(ThemeHandler); ok && layout {
+		h.OnThemeChanged()
+	}
+	n.Wrappee().Mark(MarkNeedsPaint)
+}
+
+// Ordnet die Properties von n dem Node zu (siehe props.Properties.Owner).
+// Wird aufgerufen, sobald n in einen SceneGraph eingefuegt wird.
+func ownProps(n Node) {
+	if p := n.Wrappee().Properties(); p != nil {
+		p.SetOwner(n)
+	}
+}
@@ -0,0 +1,150 @@
+package adagui_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+	"github.com/stefan-muehlebach/adagui/props"
+	"github.com/stefan-muehlebach/gg/colors"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+// Nach einem Wechsel des Themes muessen Schriftgroessen und Layout
+// angepasst und das Fenster neu gezeichnet werden.
+func TestSetTheme(t *testing.T) {
+	lbl := adagui.NewLabel("Theme")
+	btn := adagui.NewTextButton("Button")
+	grp := adagui.NewGroup()
+	grp.Layout = adagui.NewHBoxLayout(10)
+	grp.Add(lbl, btn)
+	h := adaguitest.New(t, grp)
+	t.Cleanup(func() { h.Screen.SetTheme(props.DefaultTheme) })
+
+	var lblSize, btnPos geom.Point
+	h.Do(func() { lblSize, btnPos = lbl.MinSize(), btn.Pos() })
+	before := h.Image()
+	if err := h.Screen.SetTheme("HighContrast"); err != nil {
+		t.Fatal(err)
+	}
+	h.WaitIdle()
+	if name := h.Screen.Theme(); name != "HighContrast" {
+		t.Errorf("theme is '%s', want 'HighContrast'", name)
+	}
+	h.Do(func() {
+		if s := lbl.MinSize(); s.Y <= lblSize.Y {
+			t.Errorf("label size %v did not grow with the font", s)
+		}
+		if p := btn.Pos(); p.X <= btnPos.X {
+			t.Errorf("button at %v not moved after relayout", p)
+		}
+	})
+	if !differs(before, h.Image(), before.Bounds()) {
+		t.Errorf("window not repainted after theme change")
+	}
+
+	if err := h.Screen.SetTheme(props.DefaultTheme); err != nil {
+		t.Fatal(err)
+	}
+	h.WaitIdle()
+	h.Do(func() {
+		if p := btn.Pos(); !p.Eq(btnPos) {
+			t.Errorf("button at %v, want %v after switching back", p, btnPos)
+		}
+	})
+}
+
+// Ein unbekanntes Theme darf am aktuellen Theme nichts aendern.
+func TestSetUnknownTheme(t *testing.T) {
+	h := adaguitest.New(t, centered(adagui.NewButton(60, 40)))
+	if err := h.Screen.SetTheme("NoSuchTheme"); err == nil {
+		t.Errorf("SetTheme accepted an unknown theme")
+	}
+	if name := h.Screen.Theme(); name != props.DefaultTheme {
+		t.Errorf("theme is '%s', want '%s'", name, props.DefaultTheme)
+	}
+}
+
+// Ein Theme mit einer fehlerhaften Farbe wird trotzdem aktiviert und die
+// Widgets werden angepasst.
+func TestSetThemeWithErrors(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "Broken.json")
+	data := []byte(`[{"Name": "Default",
+		"Colors": {"Color": {"Name": "NoSuchColor"}},
+		"Sizes": {"FontSize": 30}}]`)
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	lbl := adagui.NewLabel("Theme")
+	h := adaguitest.New(t, centered(lbl))
+	t.Cleanup(func() { h.Screen.SetTheme(props.DefaultTheme) })
+
+	var before geom.Point
+	h.Do(func() { before = lbl.MinSize() })
+	if err := h.Screen.SetTheme(name); !errors.Is(err, props.ErrColorNotFound) {
+		t.Errorf("got error %v, want ErrColorNotFound", err)
+	}
+	h.WaitIdle()
+	if got := h.Screen.Theme(); got != name {
+		t.Errorf("theme is '%s', want '%s'", got, name)
+	}
+	var after geom.Point
+	h.Do(func() { after = lbl.MinSize() })
+	if after.Y <= before.Y {
+		t.Errorf("label size %v not updated to the new theme", after)
+	}
+}
+
+// Aenderungen an den Properties eines Typs muessen bei bereits gezeichneten
+// Widgets ankommen.
+func TestPropsChangeRepaint(t *testing.T) {
+	btn := adagui.NewTextButton("Props")
+	h := adaguitest.New(t, centered(btn))
+	typeProps := props.PropsMap["TextButton"]
+
+	var minSize geom.Point
+	h.Do(func() { minSize = btn.MinSize() })
+	before := h.Image()
+	h.Do(func() { typeProps.SetColor(props.Color, colors.FireBrick) })
+	t.Cleanup(func() { h.Do(func() { typeProps.DelColor(props.Color) }) })
+	h.WaitIdle()
+	if !differs(before, h.Image(), before.Bounds()) {
+		t.Errorf("button not repainted after the type color changed")
+	}
+
+	h.Do(func() { typeProps.SetSize(props.FontSize, 24) })
+	t.Cleanup(func() { h.Do(func() { typeProps.DelSize(props.FontSize) }) })
+	h.WaitIdle()
+	h.Do(func() {
+		if s := btn.MinSize(); s.X <= minSize.X {
+			t.Errorf("button min size %v did not grow with the font", s)
+		}
+	})
+}
+
+// Aenderungen an den Properties eines einzelnen Widgets duerfen nur dieses
+// betreffen.
+func TestObjectPropsChange(t *testing.T) {
+	btn1 := adagui.NewTextButton("One")
+	btn2 := adagui.NewTextButton("Two")
+	grp := adagui.NewGroup()
+	grp.Layout = adagui.NewHBoxLayout(10)
+	grp.Add(btn1, btn2)
+	h := adaguitest.New(t, grp)
+
+	var minSize1, minSize2 geom.Point
+	h.Do(func() { minSize1, minSize2 = btn1.MinSize(), btn2.MinSize() })
+	h.Do(func() { btn1.SetFontSize(24) })
+	h.WaitIdle()
+	var size1, size2 geom.Point
+	h.Do(func() { size1, size2 = btn1.MinSize(), btn2.MinSize() })
+	if size1.X <= minSize1.X {
+		t.Errorf("button min size %v did not grow with the font", size1)
+	}
+	if !size2.Eq(minSize2) {
+		t.Errorf("other button changed its min size to %v", size2)
+	}
+}
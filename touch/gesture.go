@@ -0,0 +1,112 @@
+package touch
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// In GestureConfig sind alle Grenzwerte zusammengefasst, mit welchen aus den
+// rohen Ereignissen des Touchscreens die Events Tap, DoubleTap, LongPress,
+// Swipe und Fling erkannt werden. Die Bedeutung der einzelnen Felder ist
+// bei den gleichnamigen Konstanten beschrieben, welche auch die
+// Default-Werte enthalten (siehe DefaultGestureConfig).
+type GestureConfig struct {
+	TapDuration        time.Duration
+	DoubleTapDuration  time.Duration
+	LongPressThreshold time.Duration
+	NearThreshold      float64
+	SwipeMinDistance   float64
+	SwipeMaxDuration   time.Duration
+	FlingMinVelocity   float64
+	VelocityWindow     time.Duration
+}
+
+// Liefert eine Konfiguration mit den Default-Werten.
+func DefaultGestureConfig() GestureConfig {
+	return GestureConfig{
+		TapDuration:        TapDuration,
+		DoubleTapDuration:  DoubleTapDuration,
+		LongPressThreshold: LongPressThreshold,
+		NearThreshold:      NearThreshold,
+		SwipeMinDistance:   SwipeMinDistance,
+		SwipeMaxDuration:   SwipeMaxDuration,
+		FlingMinVelocity:   FlingMinVelocity,
+		VelocityWindow:     VelocityWindow,
+	}
+}
+
+// Erzeugt eine Konfiguration aus den JSON-Daten in data. Zeitangaben werden
+// als Text im Format von time.ParseDuration angegeben (bspw. "250ms"),
+// Distanzen und Geschwindigkeiten als Zahlen. Fehlende Werte werden aus
+// DefaultGestureConfig uebernommen. Beispiel:
+//
+//	{ "TapDuration": "300ms", "NearThreshold": 20 }
+func NewGestureConfigFromData(data []byte) (GestureConfig, error) {
+	cfg := DefaultGestureConfig()
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return DefaultGestureConfig(), err
+	}
+	return cfg, nil
+}
+
+// Wie NewGestureConfigFromData, die JSON-Daten werden jedoch aus der Datei
+// fileName gelesen.
+func NewGestureConfigFromFile(fileName string) (GestureConfig, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return DefaultGestureConfig(), err
+	}
+	return NewGestureConfigFromData(data)
+}
+
+// Die Zeitangaben werden als Text gelesen. Alle Felder, welche in data
+// nicht vorkommen, behalten ihren bisherigen Wert.
+func (cfg *GestureConfig) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		TapDuration        *string
+		DoubleTapDuration  *string
+		LongPressThreshold *string
+		NearThreshold      *float64
+		SwipeMinDistance   *float64
+		SwipeMaxDuration   *string
+		FlingMinVelocity   *float64
+		VelocityWindow     *string
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	durations := []struct {
+		src *string
+		dst *time.Duration
+	}{
+		{raw.TapDuration, &cfg.TapDuration},
+		{raw.DoubleTapDuration, &cfg.DoubleTapDuration},
+		{raw.LongPressThreshold, &cfg.LongPressThreshold},
+		{raw.SwipeMaxDuration, &cfg.SwipeMaxDuration},
+		{raw.VelocityWindow, &cfg.VelocityWindow},
+	}
+	for _, d := range durations {
+		if d.src == nil {
+			continue
+		}
+		val, err := time.ParseDuration(*d.src)
+		if err != nil {
+			return err
+		}
+		*d.dst = val
+	}
+	floats := []struct {
+		src, dst *float64
+	}{
+		{raw.NearThreshold, &cfg.NearThreshold},
+		{raw.SwipeMinDistance, &cfg.SwipeMinDistance},
+		{raw.FlingMinVelocity, &cfg.FlingMinVelocity},
+	}
+	for _, f := range floats {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package touch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGestureConfigFromData(t *testing.T) {
+	cfg, err := NewGestureConfigFromData([]byte(
+		`{"TapDuration": "300ms", "NearThreshold": 20}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TapDuration != 300*time.Millisecond {
+		t.Errorf("TapDuration is %v, want 300ms", cfg.TapDuration)
+	}
+	if cfg.NearThreshold != 20 {
+		t.Errorf("NearThreshold is %v, want 20", cfg.NearThreshold)
+	}
+	if cfg.LongPressThreshold != LongPressThreshold {
+		t.Errorf("LongPressThreshold is %v, want the default %v",
+			cfg.LongPressThreshold, LongPressThreshold)
+	}
+	if _, err := NewGestureConfigFromData([]byte(`{"TapDuration": "soon"}`)); err == nil {
+		t.Errorf("invalid duration accepted")
+	}
+}
@@ -0,0 +1,86 @@
+package touch
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Mit einem SessionWriter wird eine Folge von Events (eine Session) im
+// Format JSON Lines geschrieben, d.h. jedes Event steht als JSON-Objekt auf
+// einer eigenen Zeile. Zusammen mit den Zeitstempeln der Events laesst sich
+// eine Session spaeter mit dem originalen Zeitverlauf wieder abspielen.
+type SessionWriter struct {
+	enc *json.Encoder
+}
+
+func NewSessionWriter(w io.Writer) *SessionWriter {
+	return &SessionWriter{enc: json.NewEncoder(w)}
+}
+
+// Schreibt das Event evt als neue Zeile.
+func (sw *SessionWriter) Write(evt Event) error {
+	return sw.enc.Encode(evt)
+}
+
+// Ein SessionReader liest die Events einer Session, welche mit einem
+// SessionWriter geschrieben wurde. Leere Zeilen werden ignoriert.
+type SessionReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func NewSessionReader(r io.Reader) *SessionReader {
+	return &SessionReader{scanner: bufio.NewScanner(r)}
+}
+
+// Liest das naechste Event. Am Ende der Session wird io.EOF retourniert.
+func (sr *SessionReader) Read() (Event, error) {
+	var evt Event
+	for sr.scanner.Scan() {
+		sr.line++
+		data := sr.scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return evt, &SessionError{Line: sr.line, Err: err}
+		}
+		return evt, nil
+	}
+	if err := sr.scanner.Err(); err != nil {
+		return evt, err
+	}
+	return evt, io.EOF
+}
+
+// Liest alle (restlichen) Events der Session.
+func (sr *SessionReader) ReadAll() ([]Event, error) {
+	var events []Event
+	for {
+		evt, err := sr.Read()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, evt)
+	}
+}
+
+// Tritt beim Lesen einer Session ein Fehler auf, wird er zusammen mit der
+// Zeilennummer in einem SessionError retourniert.
+type SessionError struct {
+	Line int
+	Err  error
+}
+
+func (e *SessionError) Error() string {
+	return fmt.Sprintf("touch: session line %d: %v", e.Line, e.Err)
+}
+
+func (e *SessionError) Unwrap() error {
+	return e.Err
+}
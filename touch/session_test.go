@@ -0,0 +1,66 @@
+package touch
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+func TestSessionRoundTrip(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Type: TypePress, SeqNumber: 1,
+			InitTime: t0, InitPos: geom.Point{X: 10, Y: 20},
+			Time: t0, Pos: geom.Point{X: 10, Y: 20}},
+		{Type: TypeSwipe, SeqNumber: 1,
+			InitTime: t0, InitPos: geom.Point{X: 10, Y: 20},
+			Time: t0.Add(150 * time.Millisecond), Pos: geom.Point{X: 90, Y: 20},
+			Direction: DirRight, Velocity: geom.Point{X: 600, Y: 0}},
+	}
+	var buf bytes.Buffer
+	sw := NewSessionWriter(&buf)
+	for _, evt := range events {
+		if err := sw.Write(evt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !strings.Contains(buf.String(), `"Type":"Swipe"`) {
+		t.Errorf("event type not written as text:\n%s", buf.String())
+	}
+
+	got, err := NewSessionReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(events) {
+		t.Fatalf("read %d events, want %d", len(got), len(events))
+	}
+	for i := range events {
+		if got[i].String() != events[i].String() ||
+			got[i].Direction != events[i].Direction ||
+			got[i].Velocity != events[i].Velocity {
+			t.Errorf("event %d: got %v, want %v", i, got[i], events[i])
+		}
+	}
+}
+
+func TestSessionError(t *testing.T) {
+	sr := NewSessionReader(strings.NewReader(
+		"\n{\"Type\":\"Press\"}\n{\"Type\":\"Poke\"}\n"))
+	if _, err := sr.Read(); err != nil {
+		t.Fatalf("first event: %v", err)
+	}
+	_, err := sr.Read()
+	var serr *SessionError
+	if !errors.As(err, &serr) || serr.Line != 3 {
+		t.Errorf("got error %v, want a SessionError on line 3", err)
+	}
+	if _, err := sr.Read(); err != io.EOF {
+		t.Errorf("got %v at the end of the session, want io.EOF", err)
+	}
+}
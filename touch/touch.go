@@ -16,12 +16,13 @@
 //    |
 //   TypeRelease
 //    |
-//   (TypeTap)
+//   (TypeTap | TypeSwipe, TypeFling)
 //
 package touch
 
 import (
     "fmt"
+    "math"
     "time"
     "github.com/stefan-muehlebach/gg/geom"
 )
@@ -58,8 +59,21 @@ const (
     // ein Tap, resp. DoubleTap erzeugt wird (siehe Konstanten weiter unten).
     TypeTap
     TypeDoubleTap
+    // Wird der Finger schnell und ueber eine groessere Distanz in eine
+    // Richtung gezogen, dann wird nach dem Release ein TypeSwipe-Event
+    // erzeugt (siehe SwipeMinDistance und SwipeMaxDuration). Die Richtung
+    // ist im Feld Direction abgelegt.
+    TypeSwipe
+    // Ist der Finger beim Abheben noch schnell genug unterwegs, wird nach
+    // dem Release ein TypeFling-Event erzeugt (siehe FlingMinVelocity).
+    // Die Geschwindigkeit beim Abheben ist im Feld Velocity abgelegt.
+    TypeFling
     numEvents
 
+    // Die folgenden Konstanten sind die Default-Werte fuer die Erkennung
+    // der Events. Sie koennen pro Screen oder GUI-Element mit einer
+    // GestureConfig angepasst werden.
+
     // TapDuration ist die Zeit, welche max. zwischen Press und Release
     // vergehen darf, damit dieses Ereignis als Tap interpretiert wird.
     TapDuration          = 200 * time.Millisecond
@@ -75,6 +89,17 @@ const (
     // zwischen dem Press-Event und der aktuellen Position darf nicht mehr
     // NearThreshold betragen.
     NearThreshold        = 8.0
+    // Fuer einen Swipe muss der Finger in der Hauptrichtung mindestens
+    // SwipeMinDistance zurueckgelegt haben, und zwar innerhalb von
+    // SwipeMaxDuration.
+    SwipeMinDistance     = 40.0
+    SwipeMaxDuration     = 500 * time.Millisecond
+    // Ein Fling wird erzeugt, wenn die Geschwindigkeit beim Abheben des
+    // Fingers mindestens FlingMinVelocity (in Pixel pro Sekunde) betraegt.
+    FlingMinVelocity     = 300.0
+    // Fuer die Berechnung der Geschwindigkeit beim Abheben werden nur die
+    // Positionen innerhalb dieser Zeitspanne vor dem Release verwendet.
+    VelocityWindow       = 100 * time.Millisecond
 )
 
 // Damit beim Debuggen klar ist, um welchen Event-Typ es sich handelt,
@@ -97,11 +122,159 @@ func (t Type) String() (string) {
         return "Tap"
     case TypeDoubleTap:
         return "DoubleTap"
+    case TypeSwipe:
+        return "Swipe"
+    case TypeFling:
+        return "Fling"
     default:
         return "(Unknown Type)"
     }
 }
 
+// Fuer die Aufzeichnung von Events (siehe SessionWriter) wird der Typ als
+// Text abgelegt.
+func (t Type) MarshalText() ([]byte, error) {
+    if t >= numEvents {
+        return nil, fmt.Errorf("touch: unknown event type %d", t)
+    }
+    return []byte(t.String()), nil
+}
+
+func (t *Type) UnmarshalText(text []byte) (error) {
+    for typ := TypePress; typ < numEvents; typ++ {
+        if typ.String() == string(text) {
+            *t = typ
+            return nil
+        }
+    }
+    return fmt.Errorf("touch: unknown event type '%s'", text)
+}
+
+// Mit Direction wird die Richtung eines Swipe- oder Fling-Events
+// angegeben. Massgebend ist jeweils die Achse, entlang welcher die
+// groessere Bewegung stattgefunden hat.
+type Direction uint8
+
+const (
+    DirNone Direction = iota
+    DirLeft
+    DirRight
+    DirUp
+    DirDown
+)
+
+func (d Direction) String() (string) {
+    switch d {
+    case DirNone:
+        return "None"
+    case DirLeft:
+        return "Left"
+    case DirRight:
+        return "Right"
+    case DirUp:
+        return "Up"
+    case DirDown:
+        return "Down"
+    default:
+        return "(Unknown Direction)"
+    }
+}
+
+func (d Direction) MarshalText() ([]byte, error) {
+    if d > DirDown {
+        return nil, fmt.Errorf("touch: unknown direction %d", d)
+    }
+    return []byte(d.String()), nil
+}
+
+func (d *Direction) UnmarshalText(text []byte) (error) {
+    for dir := DirNone; dir <= DirDown; dir++ {
+        if dir.String() == string(text) {
+            *d = dir
+            return nil
+        }
+    }
+    return fmt.Errorf("touch: unknown direction '%s'", text)
+}
+
+// Ermittelt die Richtung der Bewegung um den Vektor d.
+func DirectionOf(d geom.Point) (Direction) {
+    switch {
+    case d.X == 0 && d.Y == 0:
+        return DirNone
+    case math.Abs(d.X) >= math.Abs(d.Y):
+        if d.X < 0 {
+            return DirLeft
+        }
+        return DirRight
+    default:
+        if d.Y < 0 {
+            return DirUp
+        }
+        return DirDown
+    }
+}
+
+// Ein Event durchlaeuft den SceneGraph in drei Phasen: in der Capture-Phase
+// von der Wurzel abwaerts bis zum Parent des Ziel-Nodes, in der Target-Phase
+// wird es dem Ziel-Node selber zugestellt und in der Bubble-Phase wandert
+// es ueber alle Container zurueck bis zur Wurzel. Leaf-Widgets erhalten
+// Events also immer in der Target-Phase.
+type Phase uint8
+
+const (
+    PhaseTarget Phase = iota
+    PhaseCapture
+    PhaseBubble
+)
+
+func (p Phase) String() (string) {
+    switch p {
+    case PhaseTarget:
+        return "Target"
+    case PhaseCapture:
+        return "Capture"
+    case PhaseBubble:
+        return "Bubble"
+    default:
+        return "(Unknown Phase)"
+    }
+}
+
+// Mit Propagation wird die Weitergabe eines Events durch den SceneGraph
+// gesteuert. Alle Kopien eines Events, welche waehrend der Weitergabe
+// erzeugt werden, verweisen auf das gleiche Propagation-Objekt.
+type Propagation struct {
+    stopped, claimed bool
+}
+
+// Beendet die Weitergabe des Events, d.h. nach dem aktuellen Node erhaelt
+// kein weiterer Node dieses Event.
+func (p *Propagation) Stop() {
+    if p != nil {
+        p.stopped = true
+    }
+}
+
+func (p *Propagation) Stopped() (bool) {
+    return p != nil && p.stopped
+}
+
+// Mit Claim beansprucht der aktuelle Node die ganze Geste (d.h. alle
+// Events bis und mit dem naechsten Release) fuer sich. Die Weitergabe wird
+// beendet, der bisherige Ziel-Node erhaelt ein Leave-Event und alle
+// weiteren Events der Geste gehen direkt an den aktuellen Node.
+func (p *Propagation) Claim() {
+    if p != nil {
+        p.claimed = true
+        p.stopped = true
+    }
+}
+
+func (p *Propagation) Claimed() (bool) {
+    return p != nil && p.claimed
+}
+
 // In diesem Datentyp ist alles zusammengefasst, was an Touch-Events an die
 // applikatorischen Elemente gesendet werden kann.
 type Event struct {
@@ -114,6 +287,11 @@ type Event struct {
     // Dieses Feld wird auf true gesetzt, sobald ein LongPressed-Ereignis
     // erkannt wird.
     LongPressed bool
+    // Ist bei einem Release-Event true, wenn die Geste nicht durch das
+    // Abheben des Fingers, sondern bspw. durch einen Fensterwechsel
+    // abgebrochen wurde. Auf ein solches Event folgen keine Tap-, Swipe-
+    // oder Fling-Events mehr.
+    Canceled bool
     // In InitTime und InitPos werden Zeitpunkt und Position des Press-Events
     // (des initialen Events) festgehalten.
     InitTime time.Time
@@ -122,6 +300,26 @@ type Event struct {
     // enthalten.
     Time time.Time
     Pos  geom.Point
+    // Bei Swipe- und Fling-Events enthaelt Direction die Richtung der
+    // Bewegung und Velocity die Geschwindigkeit beim Abheben des Fingers
+    // (in Pixel pro Sekunde, Bildschirmkoordinaten).
+    Direction Direction
+    Velocity  geom.Point
+    // Phase, in welcher das Event dem Node zugestellt wird und Steuerung der
+    // Weitergabe (siehe StopPropagation und ClaimGesture).
+    Phase       Phase       `json:"-"`
+    Propagation *Propagation `json:"-"`
+}
+
+// Beendet die Weitergabe dieses Events an weitere Nodes.
+func (evt Event) StopPropagation() {
+    evt.Propagation.Stop()
+}
+
+// Beansprucht die Geste, zu welcher dieses Event gehoert, fuer den Node,
+// welcher das Event gerade verarbeitet (siehe Propagation.Claim).
+func (evt Event) ClaimGesture() {
+    evt.Propagation.Claim()
 }
 
 // Fuer das Debugging implementiert Event das Stringer-Interface.
@@ -141,7 +339,10 @@ type TouchFunction func(evt Event)
 // Touchscreen-Ereignisse hinterlegt werden. Im Array touchFuncList kann
 // fuer jedes Ereginis max. eine Funktion hinterlegt werden.
 type TouchEmbed struct {
-    touchFuncList [numEvents]TouchFunction
+    touchFuncList   [numEvents]TouchFunction
+    captureFuncList [numEvents]TouchFunction
+    bubbleFuncList  [numEvents]TouchFunction
+    gestureConfig   *GestureConfig
 }
 
 // Diese Methode wird durch AdaGui aufgerufen, um ein Touch-Ereignis an
@@ -159,20 +360,66 @@ func (m *TouchEmbed) OnInputEvent(evt Event) {
 // resp. die Verarbeitung der Events mittels Go-Routinen zu paralellisieren.
 // Die notwendige Synchronisation in den GUI-Elementen stelle ich mir jedoch
 // ziemlich anspruchsvoll vor...
+// In der Capture-Phase werden nur die mit SetCaptureFunc registrierten
+// Handler aufgerufen, in der Bubble-Phase diejenigen von SetBubbleFunc und
+// in der Target-Phase diejenigen von SetTouchFunc.
 func (m *TouchEmbed) CallTouchFunc(evt Event) {
-    if fnc := m.touchFuncList[evt.Type]; fnc != nil {
+    list := &m.touchFuncList
+    switch evt.Phase {
+    case PhaseCapture:
+        list = &m.captureFuncList
+    case PhaseBubble:
+        list = &m.bubbleFuncList
+    }
+    if fnc := list[evt.Type]; fnc != nil {
         fnc(evt)
     }
 }
 
 // Mit SetTouchFunc wird die Funktion fnc als Handler fuer den Event typ
 // registriert. Eine bereits registrierte Funktion wird damit ueberschrieben.
+// Der Handler wird nur fuer Events aufgerufen, welche das GUI-Element selber
+// betreffen; fuer die Events der Kinder siehe SetCaptureFunc und
+// SetBubbleFunc.
 func (m *TouchEmbed) SetTouchFunc(fnc TouchFunction, types ...Type) {
     for _, typ := range types {
         m.touchFuncList[typ] = fnc
     }
 }
 
+// Mit SetGestureConfig koennen fuer dieses GUI-Element (und alle darin
+// enthaltenen Elemente) eigene Grenzwerte fuer die Erkennung von Tap,
+// LongPress, Swipe, etc. festgelegt werden. Mit nil gelten wieder die
+// Werte des uebergeordneten Elements, resp. des Screens.
+func (m *TouchEmbed) SetGestureConfig(cfg *GestureConfig) {
+    m.gestureConfig = cfg
+}
+
+// Liefert die mit SetGestureConfig festgelegten Grenzwerte oder nil.
+func (m *TouchEmbed) GestureConfig() (*GestureConfig) {
+    return m.gestureConfig
+}
+
+// Mit SetCaptureFunc wird die Funktion fnc als Handler fuer den Event typ
+// in der Capture-Phase registriert. Damit kann ein Container die Events
+// seiner Kinder sehen (und bspw. mit ClaimGesture abfangen), bevor diese
+// sie erhalten.
+func (m *TouchEmbed) SetCaptureFunc(fnc TouchFunction, types ...Type) {
+    for _, typ := range types {
+        m.captureFuncList[typ] = fnc
+    }
+}
+
+// Mit SetBubbleFunc wird die Funktion fnc als Handler fuer den Event typ
+// in der Bubble-Phase registriert. Damit erhaelt ein Container die Events
+// seiner Kinder, nachdem diese sie verarbeitet haben, sofern die Weitergabe
+// nicht vorher beendet wurde (siehe StopPropagation).
+func (m *TouchEmbed) SetBubbleFunc(fnc TouchFunction, types ...Type) {
+    for _, typ := range types {
+        m.bubbleFuncList[typ] = fnc
+    }
+}
+
 // Registriert fnc als Handler fuer den Press-Event.
 func (m *TouchEmbed) SetOnPress(fnc TouchFunction) {
     m.SetTouchFunc(fnc, TypePress)
@@ -213,3 +460,13 @@ func (m *TouchEmbed) SetOnDoubleTap(fnc TouchFunction) {
     m.SetTouchFunc(fnc, TypeDoubleTap)
 }
 
+// Registriert fnc als Handler fuer den Swipe-Event.
+func (m *TouchEmbed) SetOnSwipe(fnc TouchFunction) {
+    m.SetTouchFunc(fnc, TypeSwipe)
+}
+
+// Registriert fnc als Handler fuer den Fling-Event.
+func (m *TouchEmbed) SetOnFling(fnc TouchFunction) {
+    m.SetTouchFunc(fnc, TypeFling)
+}
+
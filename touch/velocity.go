@@ -0,0 +1,56 @@
+package touch
+
+import (
+	"time"
+
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+// Mit einem VelocityTracker wird aus den Positionen einer Drag-Bewegung die
+// aktuelle Geschwindigkeit bestimmt. Dazu werden alle Positionen der
+// letzten Zeitspanne Window (Default: VelocityWindow) aufbewahrt.
+type VelocityTracker struct {
+	Window  time.Duration
+	samples []sample
+}
+
+type sample struct {
+	pos geom.Point
+	t   time.Time
+}
+
+// Verwirft alle bisherigen Positionen, bspw. beim Beginn einer neuen
+// Bewegung.
+func (vt *VelocityTracker) Reset() {
+	vt.samples = vt.samples[:0]
+}
+
+// Fuegt die Position pos zum Zeitpunkt t hinzu. Positionen, welche aelter
+// als Window sind, werden entfernt.
+func (vt *VelocityTracker) Add(pos geom.Point, t time.Time) {
+	window := vt.Window
+	if window <= 0 {
+		window = VelocityWindow
+	}
+	vt.samples = append(vt.samples, sample{pos, t})
+	i := 0
+	for i < len(vt.samples)-2 && t.Sub(vt.samples[i].t) > window {
+		i++
+	}
+	vt.samples = append(vt.samples[:0], vt.samples[i:]...)
+}
+
+// Liefert die Geschwindigkeit in Pixel pro Sekunde zwischen der aeltesten
+// und der neuesten Position. Liegen weniger als zwei Positionen vor oder
+// ist keine Zeit vergangen, wird der Nullvektor retourniert.
+func (vt *VelocityTracker) Velocity() geom.Point {
+	if len(vt.samples) < 2 {
+		return geom.Point{}
+	}
+	first, last := vt.samples[0], vt.samples[len(vt.samples)-1]
+	dt := last.t.Sub(first.t).Seconds()
+	if dt <= 0 {
+		return geom.Point{}
+	}
+	return last.pos.Sub(first.pos).Div(dt)
+}
@@ -0,0 +1,206 @@
+package adagui
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"golang.org/x/image/draw"
+)
+
+// Mit TransitionType wird der Effekt bestimmt, mit welchem beim Wechsel
+// des Fensters (siehe Screen.SetWindowWithTransition) vom bisherigen zum
+// neuen Fenster uebergeblendet wird.
+type TransitionType int
+
+const (
+	// Das neue Fenster wird ohne Uebergang dargestellt.
+	TransitionNone TransitionType = iota
+	// Das neue Fenster schiebt sich von rechts ueber den Bildschirm und
+	// das bisherige Fenster nach links hinaus.
+	TransitionSlideLeft
+	// Das neue Fenster schiebt sich von links ueber den Bildschirm und
+	// das bisherige Fenster nach rechts hinaus.
+	TransitionSlideRight
+	// Das neue Fenster schiebt sich von unten ueber den Bildschirm und
+	// das bisherige Fenster nach oben hinaus.
+	TransitionSlideUp
+	// Das neue Fenster wird ueber das bisherige Fenster eingeblendet.
+	TransitionFade
+	// Das neue Fenster waechst von der Mitte des Bildschirms aus, bis es
+	// den ganzen Bildschirm bedeckt.
+	TransitionZoom
+)
+
+func (t TransitionType) String() string {
+	switch t {
+	case TransitionNone:
+		return "TransitionNone"
+	case TransitionSlideLeft:
+		return "TransitionSlideLeft"
+	case TransitionSlideRight:
+		return "TransitionSlideRight"
+	case TransitionSlideUp:
+		return "TransitionSlideUp"
+	case TransitionFade:
+		return "TransitionFade"
+	case TransitionZoom:
+		return "TransitionZoom"
+	default:
+		return "(Unknown TransitionType)"
+	}
+}
+
+// Ein Transition beschreibt einen Uebergang zwischen zwei Fenstern. Wie bei
+// den Animationen kann mit Curve (Default: AnimationLinear) der zeitliche
+// Verlauf beeinflusst werden.
+type Transition struct {
+	Type     TransitionType
+	Duration time.Duration
+	Curve    AnimationCurve
+}
+
+// Erzeugt einen Uebergang vom Typ typ mit der Standarddauer und einem
+// weichen Start und Ende.
+func NewTransition(typ TransitionType) Transition {
+	return Transition{
+		Type:     typ,
+		Duration: DurationStandard,
+		Curve:    AnimationEaseInOut,
+	}
+}
+
+// Waehrend eines Uebergangs werden die beiden Fenster nicht mehr direkt
+// gezeichnet. Stattdessen werden Abzuege der Fenster vor und nach dem
+// Wechsel ueberlagert dargestellt.
+type transitionState struct {
+	Transition
+	from, to *image.RGBA
+	anim     *Animation
+	val      float64
+}
+
+// Zeichnet das Bild des Uebergangs in dst.
+func (t *transitionState) paint(dst *image.RGBA) {
+	r := dst.Bounds()
+	w, h := r.Dx(), r.Dy()
+	switch t.Type {
+	case TransitionSlideLeft:
+		dx := int(t.val * float64(w))
+		draw.Draw(dst, r, t.from, image.Pt(dx, 0), draw.Src)
+		draw.Draw(dst, r.Add(image.Pt(w-dx, 0)), t.to, image.Point{}, draw.Src)
+	case TransitionSlideRight:
+		dx := int(t.val * float64(w))
+		draw.Draw(dst, r, t.from, image.Pt(-dx, 0), draw.Src)
+		draw.Draw(dst, r.Add(image.Pt(dx-w, 0)), t.to, image.Point{}, draw.Src)
+	case TransitionSlideUp:
+		dy := int(t.val * float64(h))
+		draw.Draw(dst, r, t.from, image.Pt(0, dy), draw.Src)
+		draw.Draw(dst, r.Add(image.Pt(0, h-dy)), t.to, image.Point{}, draw.Src)
+	case TransitionFade:
+		mask := image.NewUniform(color.Alpha{uint8(255.0 * t.val)})
+		draw.Draw(dst, r, t.from, image.Point{}, draw.Src)
+		draw.DrawMask(dst, r, t.to, image.Point{}, mask, image.Point{},
+			draw.Over)
+	case TransitionZoom:
+		draw.Draw(dst, r, t.from, image.Point{}, draw.Src)
+		zw, zh := int(t.val*float64(w)), int(t.val*float64(h))
+		if zw > 0 && zh > 0 {
+			zr := image.Rect(0, 0, zw, zh).Add(image.Pt((w-zw)/2, (h-zh)/2))
+			draw.ApproxBiLinear.Scale(dst, zr, t.to, t.to.Bounds(),
+				draw.Src, nil)
+		}
+	default:
+		draw.Draw(dst, r, t.to, image.Point{}, draw.Src)
+	}
+}
+
+// Legt den Standarduebergang fest, welcher von SetWindow verwendet wird.
+// Per Default werden Fenster ohne Uebergang gewechselt.
+func (s *Screen) SetTransition(t Transition) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.transition = t
+}
+
+// Liefert den Standarduebergang, welcher von SetWindow verwendet wird.
+func (s *Screen) Transition() Transition {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.transition
+}
+
+// Mit TransitionRunning kann abgefragt werden, ob aktuell ein Uebergang
+// zwischen zwei Fenstern laeuft. Waehrend dieser Zeit werden keine
+// Touch-Events verarbeitet.
+func (s *Screen) TransitionRunning() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.trans != nil
+}
+
+// Wie SetWindow, der Wechsel zum Fenster w erfolgt jedoch mit dem Uebergang
+// t. Die Methode kehrt sofort zurueck, der Uebergang wird vom paintThread
+// dargestellt. Ein bereits laufender Uebergang wird abgebrochen.
+func (s *Screen) SetWindowWithTransition(w *Window, t Transition) {
+	s.invoke(func() { s.setWindowWithTransition(w, t) })
+}
+
+func (s *Screen) setWindowWithTransition(w *Window, t Transition) {
+	cur := s.Window()
+	if cur == w {
+		return
+	}
+	if t.Type == TransitionNone || cur == nil {
+		s.setWindow(w)
+		s.repaint()
+		return
+	}
+	s.endTransition()
+
+	tr := &transitionState{Transition: t}
+	tr.from = s.snapshot()
+	s.setWindow(w)
+	tr.to = s.snapshot()
+	tr.anim = &Animation{
+		Duration:   t.Duration,
+		Curve:      t.Curve,
+		Tick:       func(val float64) { tr.val = val },
+		OnComplete: s.endTransition,
+	}
+	s.mutex.Lock()
+	s.trans = tr
+	s.mutex.Unlock()
+
+	s.StartAnimation(tr.anim)
+}
+
+// Beendet einen laufenden Uebergang. Die sichtbaren Fenster werden beim
+// naechsten Bildaufbau wieder direkt gezeichnet.
+func (s *Screen) endTransition() {
+	s.mutex.Lock()
+	tr := s.trans
+	s.trans = nil
+	if tr != nil {
+		s.updateStages()
+	}
+	s.mutex.Unlock()
+	if tr != nil {
+		s.StopAnimation(tr.anim)
+	}
+}
+
+// Zeichnet alle sichtbaren Fenster und liefert einen Abzug davon. Muss
+// im UI-Thread aufgerufen werden.
+func (s *Screen) snapshot() *image.RGBA {
+	windows := s.visibleWindows()
+	width, height := s.Size()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	op := draw.Src
+	for _, w := range windows {
+		w.repaint()
+		draw.Draw(img, img.Bounds(), w.gc.Image(), image.Point{}, op)
+		op = draw.Over
+	}
+	return img
+}
@@ -0,0 +1,108 @@
+package adagui_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+	"github.com/stefan-muehlebach/adagui/touch"
+)
+
+// Waehrend eines Uebergangs werden keine Events verarbeitet und
+// dargestellt werden Abzuege der beiden Fenster. Nach dem Ende des
+// Uebergangs ist das neue Fenster aktiv und wird direkt dargestellt.
+func TestSlideTransition(t *testing.T) {
+	btn := adagui.NewButton(60, 40)
+	rec := adaguitest.NewRecorder()
+	btn.SetTouchFunc(rec.Record, touch.TypePress, touch.TypeTap)
+	h := adaguitest.New(t, centered(adagui.NewButton(60, 40)))
+
+	next := h.Screen.NewWindow()
+	next.SetRoot(centered(btn))
+	t.Cleanup(next.Close)
+
+	tr := adagui.NewTransition(adagui.TransitionSlideLeft)
+	tr.Duration = 500 * time.Millisecond
+	h.Screen.SetWindowWithTransition(next, tr)
+	var running bool
+	h.Do(func() { running = h.Screen.TransitionRunning() })
+	if !running {
+		t.Fatalf("transition is not running")
+	}
+	if h.Screen.Window() != next {
+		t.Errorf("new window is not the active window")
+	}
+	h.Tap(h.Center(btn))
+	if evts := rec.Events(); len(evts) != 0 {
+		t.Errorf("events delivered during transition: %v", evts)
+	}
+	frames := h.Backend.NumFrames()
+	time.Sleep(tr.Duration)
+	h.WaitIdle()
+	if h.Screen.TransitionRunning() {
+		t.Fatalf("transition still running")
+	}
+	if n := h.Backend.NumFrames() - frames; n < 2 {
+		t.Errorf("only %d frames drawn during transition", n)
+	}
+	if n, _, _ := adaguitest.Compare(h.Backend.Image(), next.Image(),
+		adaguitest.Tolerance{}); n != 0 {
+		t.Errorf("framebuffer and new window differ in %d pixels", n)
+	}
+
+	h.Tap(h.Center(btn))
+	if n := rec.Count(touch.TypeTap); n != 1 {
+		t.Errorf("got %d taps after transition, want 1", n)
+	}
+}
+
+// Beginnt ein Uebergang waehrend einer Geste, dann wird diese abgebrochen:
+// das bisherige Ziel erhaelt ein Release-Event und der Pointer wird
+// freigegeben.
+func TestTransitionCancelsGesture(t *testing.T) {
+	btn := adagui.NewButton(60, 40)
+	rec := adaguitest.NewRecorder()
+	btn.SetTouchFunc(rec.Record, touch.TypeRelease)
+	h := adaguitest.New(t, centered(btn))
+	btn.SetOnPress(func(evt touch.Event) { h.Window.CapturePointer(btn) })
+
+	next := h.Screen.NewWindow()
+	next.SetRoot(centered(adagui.NewButton(60, 40)))
+	t.Cleanup(next.Close)
+
+	pt := h.Center(btn)
+	h.Press(pt)
+	h.WaitIdle()
+	var pushed, captured bool
+	h.Do(func() {
+		pushed, captured = btn.Pushed(), h.Window.PointerCapture() == btn
+	})
+	if !pushed || !captured {
+		t.Fatalf("button not pushed or pointer not captured")
+	}
+	tr := adagui.NewTransition(adagui.TransitionSlideLeft)
+	tr.Duration = 300 * time.Millisecond
+	h.Screen.SetWindowWithTransition(next, tr)
+	h.Drag(pt.AddXY(2, 0))
+	h.Release(pt.AddXY(2, 0))
+	h.WaitIdle()
+
+	if got := fmt.Sprint(rec.Types()); got != "[Release]" {
+		t.Fatalf("button got %s, want [Release]", got)
+	}
+	if evt := rec.Events()[0]; !evt.Canceled {
+		t.Errorf("release is not marked as canceled")
+	}
+	h.Do(func() {
+		if btn.Pushed() {
+			t.Errorf("button is still pushed after the transition started")
+		}
+		if n := h.Window.PointerCapture(); n != nil {
+			t.Errorf("pointer still captured by %T", n)
+		}
+	})
+	time.Sleep(tr.Duration)
+	h.WaitIdle()
+}
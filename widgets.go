@@ -22,9 +22,12 @@ import (
     "log"
     "math"
     "github.com/stefan-muehlebach/adagui/binding"
+    "github.com/stefan-muehlebach/adagui/key"
+    "github.com/stefan-muehlebach/adagui/props"
     "github.com/stefan-muehlebach/adagui/touch"
     "github.com/stefan-muehlebach/gg"
 //    "github.com/stefan-muehlebach/gg/color"
+    "github.com/stefan-muehlebach/gg/colors"
     "github.com/stefan-muehlebach/gg/fonts"
     "github.com/stefan-muehlebach/gg/geom"
     "golang.org/x/image/font"
@@ -83,10 +86,14 @@ func NewSeparator(orient Orientation) (*Separator) {
     s.Init()
     s.PropertyEmbed.InitByName("Default")
     s.orient = orient
-    s.SetMinSize(geom.Point{s.LineWidth(), s.LineWidth()})
+    s.OnThemeChanged()
     return s
 }
 
+func (s *Separator) OnThemeChanged() {
+    s.SetMinSize(geom.Point{X: s.LineWidth(), Y: s.LineWidth()})
+}
+
 func (s *Separator) Paint(gc *gg.Context) {
     gc.SetStrokeColor(s.BarColor())
     gc.SetStrokeWidth(s.LineWidth())
@@ -124,10 +131,15 @@ func NewLabel(txt string) (*Label) {
     return l
 }
 
+// Wie NewLabel, der Text wird jedoch aus dem Bind-Objekt data gelesen.
+// Aendert sich dessen Wert, wird das Label neu vermessen und gezeichnet.
 func NewLabelWithData(data binding.String) (*Label) {
     l := newLabel()
     l.text = data
-    l.updateSize()
+    l.text.AddCallback(onUI(func(data binding.DataItem) {
+        l.updateSize()
+        l.Mark(MarkNeedsPaint)
+    }))
     return l
 }
 
@@ -184,6 +196,10 @@ func (l *Label) SetFontSize(fontSize float64) {
     l.updateSize()
 }
 
+func (l *Label) OnThemeChanged() {
+    l.updateSize()
+}
+
 func (l *Label) updateSize() {
     l.fontFace, _ = fonts.NewFace(l.Font(), l.FontSize())
     w := float64(font.MeasureString(l.fontFace, l.Text())) / 64.0
@@ -200,7 +216,11 @@ func (l *Label) Paint(gc *gg.Context) {
     gc.SetFillColor(l.Color())
     gc.FillStroke()
     gc.SetFontFace(l.fontFace)
-    gc.SetTextColor(l.TextColor())
+    if l.Enabled() {
+        gc.SetTextColor(l.TextColor())
+    } else {
+        gc.SetTextColor(l.DisabledTextColor())
+    }
     gc.DrawStringAnchored(l.text.Get(), l.ax*l.Size().X, (1-l.ay)*l.Size().Y,
     	l.ax, l.ay)
 
@@ -256,25 +276,41 @@ func NewButton(w, h float64) (*Button) {
     return b
 }
 
+// Neben den Farben werden auch Schatten, Farbverlauf und Hintergrundbild
+// aus den Properties verwendet (sofern definiert). Inaktive Buttons werden
+// ohne Farbverlauf und Bild gezeichnet.
 func (b *Button) Paint(gc *gg.Context) {
-    gc.DrawRoundedRectangle(0.0, 0.0, b.Size().X, b.Size().Y,
-            b.CornerRadius())
-    if b.Pushed() {
+    var grad *props.Gradient
+    var img *props.NineSlice
+
+    shadow := b.DropShadow()
+    if !b.Enabled() {
+        gc.SetFillColor(b.DisabledColor())
+        gc.SetStrokeColor(b.DisabledBorderColor())
+        gc.SetStrokeWidth(b.BorderWidth())
+    } else if b.Pushed() {
         gc.SetFillColor(b.PushedColor())
         gc.SetStrokeColor(b.PushedBorderColor())
         gc.SetStrokeWidth(b.PushedBorderWidth())
+        shadow = b.PushedDropShadow()
+        grad, img = b.PushedFillGradient(), b.PushedBackgroundImage()
     } else {
         if b.checked {
             gc.SetFillColor(b.SelectedColor())
             gc.SetStrokeColor(b.SelectedBorderColor())
             gc.SetStrokeWidth(b.SelectedBorderWidth())
+            grad, img = b.SelectedFillGradient(), b.SelectedBackgroundImage()
         } else {
             gc.SetFillColor(b.Color())
             gc.SetStrokeColor(b.BorderColor())
             gc.SetStrokeWidth(b.BorderWidth())
+            grad, img = b.FillGradient(), b.BackgroundImage()
         }
     }
-    gc.FillStroke()
+    b.paintShadow(gc, shadow, b.LocalBounds(), b.CornerRadius())
+    gc.DrawRoundedRectangle(0.0, 0.0, b.Size().X, b.Size().Y,
+            b.CornerRadius())
+    fillStrokeStyled(gc, b.LocalBounds(), grad, img)
 }
 
 func (b *Button) OnInputEvent(evt touch.Event) {
@@ -337,6 +373,10 @@ func (b *TextButton) SetFontSize(fontSize float64) {
     b.updateSize()
 }
 
+func (b *TextButton) OnThemeChanged() {
+    b.updateSize()
+}
+
 func (b *TextButton) updateSize() {
     b.fontFace, _ = fonts.NewFace(b.BoldFont(), b.FontSize())
     w := fix2flt(font.MeasureString(b.fontFace, b.label))
@@ -371,7 +411,9 @@ func (b *TextButton) updateRefPoint() {
 func (b *TextButton) Paint(gc *gg.Context) {
     b.Button.Paint(gc)
     gc.SetFontFace(b.fontFace)
-    if b.Pushed() {
+    if !b.Enabled() {
+        gc.SetTextColor(b.DisabledTextColor())
+    } else if b.Pushed() {
         gc.SetTextColor(b.PushedTextColor())
     } else {
         if b.checked {
@@ -398,7 +440,6 @@ func NewListButton(options []string) (*ListButton) {
     b.LeafEmbed.Init()
     b.PushEmbed.Init(b, nil)
     b.PropertyEmbed.InitByName("ListButton")
-    b.fontFace, _ = fonts.NewFace(b.BoldFont(), b.FontSize())
     b.Options   = options
     b.selIdx    = 0
     b.Selected  = b.Options[b.selIdx]
@@ -406,7 +447,12 @@ func NewListButton(options []string) (*ListButton) {
     return b
 }
 
+func (b *ListButton) OnThemeChanged() {
+    b.updateSize()
+}
+
 func (b *ListButton) updateSize() {
+    b.fontFace, _ = fonts.NewFace(b.BoldFont(), b.FontSize())
     maxWidth := 0.0
     for _, option := range b.Options {
         width := font.MeasureString(b.fontFace, option)
@@ -429,7 +475,9 @@ func (b *ListButton) updateSelected() {
 func (b *ListButton) Paint(gc *gg.Context) {
     b.Button.Paint(gc)
     gc.SetFontFace(b.fontFace)
-    if b.Pushed() {
+    if !b.Enabled() {
+        gc.SetTextColor(b.DisabledTextColor())
+    } else if b.Pushed() {
         gc.SetTextColor(b.PushedTextColor())
     } else {
         if b.checked {
@@ -441,7 +489,10 @@ func (b *ListButton) Paint(gc *gg.Context) {
     pt := geom.Point{0.6*b.Size().Y+b.InnerPadding(), 0.5*b.Size().Y}
     gc.DrawStringAnchored(b.Selected, pt.X, pt.Y, 0.0, 0.5)
 
-    if b.Pushed() {
+    if !b.Enabled() {
+        gc.SetFillColor(b.DisabledTextColor())
+        gc.SetStrokeColor(b.DisabledTextColor())
+    } else if b.Pushed() {
         gc.SetFillColor(b.PushedLineColor())
         gc.SetStrokeColor(b.PushedLineColor())
     } else {
@@ -535,18 +586,68 @@ type IconButton struct {
     data binding.Int
 }
 
+// Kann die Bilddatei nicht geladen werden, dann zeigt der Button das Bild
+// FallbackIcon an (ein graues, durchgestrichenes Quadrat) und der Fehler
+// wird protokolliert. Mit SetIcon kann das Bild ausgetauscht und der Fehler
+// ausgewertet werden.
 func NewIconButton(imgFile string) (*IconButton) {
     b := &IconButton{}
     b.Wrapper = b
     b.LeafEmbed.Init()
     b.PushEmbed.Init(b, nil)
     b.PropertyEmbed.InitByName("IconButton")
-    b.img, _ = gg.LoadPNG(imgFile)
+    if err := b.loadIcon(imgFile); err != nil {
+        log.Printf("IconButton: %v", err)
+    }
+    b.data = binding.NewInt()
+    return b
+}
+
+var (
+    // Dieses Bild wird von IconButtons anstelle einer Bilddatei angezeigt,
+    // welche nicht geladen werden konnte.
+    FallbackIcon image.Image = newFallbackIcon(32)
+)
+
+func newFallbackIcon(size int) (image.Image) {
+    gc := gg.NewContext(size, size)
+    s := float64(size)
+    gc.SetStrokeColor(colors.Gray)
+    gc.SetStrokeWidth(2.0)
+    gc.DrawRectangle(1, 1, s-2, s-2)
+    gc.DrawLine(1, 1, s-1, s-1)
+    gc.DrawLine(1, s-1, s-1, 1)
+    gc.Stroke()
+    return gc.Image()
+}
+
+// Ersetzt das Bild des Buttons durch die PNG-Datei imgFile. Kann die Datei
+// nicht geladen werden, wird FallbackIcon angezeigt und der Fehler
+// retourniert.
+func (b *IconButton) SetIcon(imgFile string) (error) {
+    err := b.loadIcon(imgFile)
+    b.Mark(MarkNeedsPaint)
+    return err
+}
+
+func (b *IconButton) loadIcon(imgFile string) (error) {
+    img, err := gg.LoadPNG(imgFile)
+    if err != nil {
+        img = FallbackIcon
+    }
+    b.img = img
+    b.updateSize()
+    return err
+}
+
+func (b *IconButton) OnThemeChanged() {
+    b.updateSize()
+}
+
+func (b *IconButton) updateSize() {
     i := b.InnerPadding()
     rect := geom.NewRectangleIMG(b.img.Bounds()).Inset(-i, -i)
     b.SetMinSize(rect.Size())
-    b.data = binding.NewInt()
-    return b
 }
 
 func NewIconButtonWithCallback(imgFile string, btnData int, callback func(int)) (*IconButton) {
@@ -561,7 +662,7 @@ func NewIconButtonWithData(imgFile string, btnData int, data binding.Int) (*Icon
     b := NewIconButton(imgFile)
     b.btnData = btnData
     b.data = data
-    b.data.AddListener(b)
+    b.data.AddCallback(onUI(b.DataChanged))
     return b
 }
 
@@ -611,46 +712,39 @@ func NewTabButton(label string, idx int) (*TabButton) {
     b.PushEmbed.Init(b, nil)
     b.PropertyEmbed.InitByName("TabButton")
     b.label     = label
+    b.updateSize()
+    b.data      = binding.NewInt()
+    b.idx       = idx
+    return b
+}
+
+func (b *TabButton) OnThemeChanged() {
+    b.updateSize()
+}
+
+func (b *TabButton) updateSize() {
 	b.fontFace, _  = fonts.NewFace(b.BoldFont(), b.FontSize())
     w := fix2flt(font.MeasureString(b.fontFace, b.label)) +
             (2.0*b.InnerPadding())
     h := b.Height()
     b.SetMinSize(geom.Point{w, h})
-    b.data      = binding.NewInt()
-    b.idx       = idx
-    return b
 }
 
 func NewTabButtonWithData(label string, idx int, data binding.Int) (*TabButton) {
     b := NewTabButton(label, idx)
     b.data = data
-    b.data.AddListener(b)
+    b.data.AddCallback(onUI(b.DataChanged))
     return b
 }
 
 func (b *TabButton) Paint(gc *gg.Context) {
-    gc.DrawRoundedRectangle(0.0, 0.0,
-            b.Size().X, b.Size().Y, b.CornerRadius())
-    if b.Pushed() {
-        gc.SetFillColor(b.PushedColor())
-        gc.SetStrokeColor(b.PushedBorderColor())
-        gc.SetStrokeWidth(b.PushedBorderWidth())
-    } else {
-        if b.checked {
-            gc.SetFillColor(b.SelectedColor())
-            gc.SetStrokeColor(b.SelectedBorderColor())
-            gc.SetStrokeWidth(b.SelectedBorderWidth())
-        } else {
-            gc.SetFillColor(b.Color())
-            gc.SetStrokeColor(b.BorderColor())
-            gc.SetStrokeWidth(b.BorderWidth())
-        }
-    }
-    gc.FillStroke()
+    b.Button.Paint(gc)
 
     mp := b.Bounds().Center()
     gc.SetFontFace(b.fontFace)
-    if b.Pushed() {
+    if !b.Enabled() {
+        gc.SetTextColor(b.DisabledTextColor())
+    } else if b.Pushed() {
         gc.SetTextColor(b.PushedTextColor())
     } else {
         if b.checked {
@@ -712,12 +806,20 @@ func NewCheckbox(label string) (*Checkbox) {
     c.PushEmbed.Init(c, nil)
     c.PropertyEmbed.InitByName("Checkbox")
     c.label     = label
+    c.updateSize()
+    c.value = binding.NewBool()
+    return c
+}
+
+func (c *Checkbox) OnThemeChanged() {
+    c.updateSize()
+}
+
+func (c *Checkbox) updateSize() {
     c.fontFace, _  = fonts.NewFace(c.Font(), c.FontSize())
-    w := float64(font.MeasureString(c.fontFace, label))/64.0
+    w := float64(font.MeasureString(c.fontFace, c.label))/64.0
     c.SetMinSize(geom.Point{c.Width()+c.InnerPadding()+w,
         c.Height()})
-    c.value = binding.NewBool()
-    return c
 }
 
 func NewCheckboxWithCallback(label string, callback func(bool)) (*Checkbox) {
@@ -737,7 +839,10 @@ func NewCheckboxWithData(label string, data binding.Bool) (*Checkbox) {
 func (c *Checkbox) Paint(gc *gg.Context) {
     gc.DrawRoundedRectangle(0.0, 0.0, c.Width(), c.Height(),
             c.CornerRadius())
-    if c.Pushed() {
+    if !c.Enabled() {
+        gc.SetFillColor(c.DisabledColor())
+        gc.SetStrokeColor(c.DisabledBorderColor())
+    } else if c.Pushed() {
         gc.SetFillColor(c.PushedColor())
         gc.SetStrokeColor(c.PushedBorderColor())
     } else {
@@ -748,7 +853,9 @@ func (c *Checkbox) Paint(gc *gg.Context) {
     gc.FillStroke()
     if c.Checked() {
         gc.SetStrokeWidth(c.LineWidth())
-        if c.Pushed() {
+        if !c.Enabled() {
+            gc.SetStrokeColor(c.DisabledTextColor())
+        } else if c.Pushed() {
             gc.SetStrokeColor(c.PushedLineColor())
         } else {
             gc.SetStrokeColor(c.LineColor())
@@ -760,7 +867,11 @@ func (c *Checkbox) Paint(gc *gg.Context) {
     }
     x := c.Width() + c.InnerPadding()
     y := 0.5*c.Height()
-    gc.SetTextColor(c.TextColor())
+    if c.Enabled() {
+        gc.SetTextColor(c.TextColor())
+    } else {
+        gc.SetTextColor(c.DisabledTextColor())
+    }
     gc.SetFontFace(c.fontFace)
     gc.DrawStringAnchored(c.label, x, y, 0.0, 0.5)
 }
@@ -779,6 +890,7 @@ func (c *Checkbox) Checked() bool {
 
 func (c *Checkbox) SetChecked(val bool) {
     c.value.Set(val)
+    c.Mark(MarkNeedsPaint)
 }
 
 // Der RadioButton ist insofern ein Spezialfall, als er erstens zwei Zustaende
@@ -799,21 +911,32 @@ func NewRadioButtonWithData(label string, value int, data binding.Int) (*RadioBu
     b.PushEmbed.Init(b, nil)
     b.PropertyEmbed.InitByName("RadioButton")
     b.label    = label
-    b.fontFace, _ = fonts.NewFace(b.Font(), b.FontSize())
-    w := float64(font.MeasureString(b.fontFace, label))/64.0
-    b.SetMinSize(geom.Point{b.Width()+b.InnerPadding()+w,
-        b.Height()})
+    b.updateSize()
     b.value = value
     b.data = data
-    b.data.AddListener(b)
+    b.data.AddCallback(onUI(b.DataChanged))
     return b
 }
 
+func (b *RadioButton) OnThemeChanged() {
+    b.updateSize()
+}
+
+func (b *RadioButton) updateSize() {
+    b.fontFace, _ = fonts.NewFace(b.Font(), b.FontSize())
+    w := float64(font.MeasureString(b.fontFace, b.label))/64.0
+    b.SetMinSize(geom.Point{X: b.Width()+b.InnerPadding()+w,
+        Y: b.Height()})
+}
+
 func (b *RadioButton) Paint(gc *gg.Context) {
     //log.Printf("RadioButton.Paint()")
     mp := geom.Point{0.5*b.Width(), 0.5*b.Height()}
     gc.DrawCircle(mp.X, mp.Y, 0.5*b.Width())
-    if b.Pushed() {
+    if !b.Enabled() {
+        gc.SetFillColor(b.DisabledColor())
+        gc.SetStrokeColor(b.DisabledBorderColor())
+    } else if b.Pushed() {
         gc.SetFillColor(b.PushedColor())
         gc.SetStrokeColor(b.PushedBorderColor())
     } else {
@@ -823,7 +946,9 @@ func (b *RadioButton) Paint(gc *gg.Context) {
     gc.SetStrokeWidth(b.BorderWidth())
     gc.FillStroke()
     if b.checked {
-        if b.Pushed() {
+        if !b.Enabled() {
+            gc.SetFillColor(b.DisabledTextColor())
+        } else if b.Pushed() {
 	        gc.SetFillColor(b.PushedLineColor())
         } else {
       	    gc.SetFillColor(b.LineColor())
@@ -833,7 +958,11 @@ func (b *RadioButton) Paint(gc *gg.Context) {
     }
     x := b.Width() + b.InnerPadding()
     y := 0.5*b.Height()
-    gc.SetTextColor(b.TextColor())
+    if b.Enabled() {
+        gc.SetTextColor(b.TextColor())
+    } else {
+        gc.SetTextColor(b.DisabledTextColor())
+    }
     gc.SetFontFace(b.fontFace)
     gc.DrawStringAnchored(b.label, x, y, 0.0, 0.5)
 }
@@ -865,6 +994,7 @@ type Scrollbar struct {
     LeafEmbed
     PushEmbed
     orient Orientation
+    length float64
     initValue, visiRange float64
     value binding.Float
     barStart, barEnd geom.Point
@@ -881,20 +1011,30 @@ func NewScrollbar(len float64, orient Orientation) (*Scrollbar) {
     s.PropertyEmbed.InitByName("Scrollbar")
     s.PushEmbed.Init(s, nil)
     s.orient = orient
+    s.length = len
+    s.updateSize()
+    s.initValue = 0.0
+    s.visiRange = 0.1
+    s.value     = binding.NewFloat()
+    s.SetValue(s.initValue)
+    return s
+}
+
+func (s *Scrollbar) OnThemeChanged() {
+    s.updateSize()
+    s.updateCtrl()
+}
+
+func (s *Scrollbar) updateSize() {
     d1 := max(0.5*s.BarSize(), 0.5*s.CtrlSize())
     d2 := min(0.5*s.BarSize(), 0.5*s.CtrlSize())
     if s.orient == Horizontal {
-        s.SetMinSize(geom.Point{len, s.Height()})
+        s.SetMinSize(geom.Point{X: s.length, Y: s.Height()})
         s.barStart = geom.Point{d2, d1}
     } else {
-        s.SetMinSize(geom.Point{s.Width(), len})
+        s.SetMinSize(geom.Point{X: s.Width(), Y: s.length})
         s.barStart = geom.Point{d1, d2}
     }
-    s.initValue = 0.0
-    s.visiRange = 0.1
-    s.value     = binding.NewFloat()
-    s.SetValue(s.initValue)
-    return s
 }
 
 func NewScrollbarWithData(len float64, orient Orientation, dat binding.Float) (*Scrollbar) {
@@ -936,6 +1076,7 @@ func (s *Scrollbar) SetValue(v float64) {
     if v < 0.0 { v = 0.0 }
     s.value.Set(v)
     s.updateCtrl()
+    s.Mark(MarkNeedsPaint)
 }
 
 func (s *Scrollbar) updateCtrl() {
@@ -959,7 +1100,9 @@ func (s *Scrollbar) updateCtrl() {
 
 func (s *Scrollbar) Paint(gc *gg.Context) {
 //    var pt1, pt2 geom.Point
-    if s.Pushed() {
+    if !s.Enabled() {
+        gc.SetStrokeColor(s.DisabledColor())
+    } else if s.Pushed() {
         gc.SetStrokeColor(s.PushedBarColor())
     } else {
         gc.SetStrokeColor(s.BarColor())
@@ -968,7 +1111,9 @@ func (s *Scrollbar) Paint(gc *gg.Context) {
     gc.DrawLine(s.barStart.X, s.barStart.Y, s.barEnd.X, s.barEnd.Y)
     gc.Stroke()
 
-    if s.Pushed() {
+    if !s.Enabled() {
+        gc.SetStrokeColor(s.DisabledBorderColor())
+    } else if s.Pushed() {
         gc.SetStrokeColor(s.PushedColor())
     } else {
         gc.SetStrokeColor(s.Color())
@@ -998,6 +1143,7 @@ func (s *Scrollbar) OnInputEvent(evt touch.Event) {
                 s.isDragging = false
             }
         }
+        s.captureThumb()
     case touch.TypeDrag:
         if !s.isDragging {
             break
@@ -1042,6 +1188,17 @@ func (s *Scrollbar) OnInputEvent(evt touch.Event) {
     }
 }
 
+// Wird der Balken gezogen, dann faengt die Scrollbar den Pointer, damit sie
+// alle Events bis zum Ende der Geste erhaelt.
+func (s *Scrollbar) captureThumb() {
+    if !s.isDragging {
+        return
+    }
+    if w := s.window(); w != nil {
+        w.CapturePointer(s)
+    }
+}
+
 // Mit Slider kann man einen Schieberegler beliebiger Laenge horizontal oder
 // vertikal im GUI positionieren. Als Werte sind aktuell nur Fliesskommazahlen
 // vorgesehen.
@@ -1049,6 +1206,7 @@ type Slider struct {
     LeafEmbed
     PushEmbed
     orient Orientation
+    length float64
     initValue, minValue, maxValue, stepSize float64
     value binding.Float
     barStart, barEnd geom.Point
@@ -1064,15 +1222,8 @@ func NewSlider(len float64, orient Orientation) (*Slider) {
     s.PushEmbed.Init(s, nil)
 
     s.orient = orient
-    d1 := max(0.5*s.BarSize(), 0.5*s.CtrlSize())
-    d2 := min(0.5*s.BarSize(), 0.5*s.CtrlSize())
-    if s.orient == Horizontal {
-        s.SetMinSize(geom.Point{len, s.Height()})
-        s.barStart = geom.Point{d2, d1}
-    } else {
-        s.SetMinSize(geom.Point{s.Width(), len})
-        s.barStart = geom.Point{d1, d2}
-    }
+    s.length = len
+    s.updateSize()
     s.initValue = 0.0
     s.minValue  = 0.0
     s.maxValue  = 1.0
@@ -1082,6 +1233,23 @@ func NewSlider(len float64, orient Orientation) (*Slider) {
     return s
 }
 
+func (s *Slider) OnThemeChanged() {
+    s.updateSize()
+    s.updateCtrl()
+}
+
+func (s *Slider) updateSize() {
+    d1 := max(0.5*s.BarSize(), 0.5*s.CtrlSize())
+    d2 := min(0.5*s.BarSize(), 0.5*s.CtrlSize())
+    if s.orient == Horizontal {
+        s.SetMinSize(geom.Point{X: s.length, Y: s.Height()})
+        s.barStart = geom.Point{X: d2, Y: d1}
+    } else {
+        s.SetMinSize(geom.Point{X: s.Width(), Y: s.length})
+        s.barStart = geom.Point{X: d1, Y: d2}
+    }
+}
+
 func NewSliderWithData(len float64, orient Orientation, dat binding.Float) (*Slider) {
     s := NewSlider(len, orient)
     s.value = dat
@@ -1120,7 +1288,9 @@ func (s *Slider) updateCtrl() {
 
 func (s *Slider) Paint(gc *gg.Context) {
     //log.Printf("Slider.Paint()")
-    if s.Pushed() {
+    if !s.Enabled() {
+        gc.SetStrokeColor(s.DisabledColor())
+    } else if s.Pushed() {
         gc.SetStrokeColor(s.PushedBarColor())
     } else {
         gc.SetStrokeColor(s.BarColor())
@@ -1129,7 +1299,9 @@ func (s *Slider) Paint(gc *gg.Context) {
     gc.DrawLine(s.barStart.X, s.barStart.Y, s.barEnd.X, s.barEnd.Y)
     gc.Stroke()
 
-    if s.Pushed() {
+    if !s.Enabled() {
+        gc.SetStrokeColor(s.DisabledBorderColor())
+    } else if s.Pushed() {
         gc.SetStrokeColor(s.PushedColor())
     } else {
         gc.SetStrokeColor(s.Color())
@@ -1165,6 +1337,7 @@ func (s *Slider) SetValue(v float64) {
     if v < s.minValue { v = s.minValue }
     s.value.Set(v)
     s.updateCtrl()
+    s.Mark(MarkNeedsPaint)
 }
 
 func (s *Slider) Value() (float64) {
@@ -1194,6 +1367,12 @@ func (s *Slider) Factor() (float64) {
 func (s *Slider) OnInputEvent(evt touch.Event) {
     s.PushEmbed.OnInputEvent(evt)
     switch evt.Type {
+    case touch.TypePress:
+        // Der Regler behaelt alle Events bis zum Ende der Geste, auch wenn
+        // ein Container die Geste beanspruchen moechte.
+        if w := s.window(); w != nil {
+            w.CapturePointer(s)
+        }
     case touch.TypeDrag:
         v := 0.0
         r := s.Rect().Inset(0.5*s.CtrlSize(), 0.5*s.CtrlSize())
@@ -1210,3 +1389,26 @@ func (s *Slider) OnInputEvent(evt touch.Event) {
     }
 }
 
+// Ist der Slider fokussiert, dann kann sein Wert mit den Pfeiltasten in
+// Richtung des Reglers um jeweils einen Schritt veraendert werden.
+func (s *Slider) OnKeyEvent(evt key.Event) (bool) {
+    if !evt.Down() {
+        return false
+    }
+    step := 0.0
+    switch evt.Code {
+    case key.CodeLeft, key.CodeDown:
+        if (s.orient == Horizontal) == (evt.Code == key.CodeLeft) {
+            step = -s.stepSize
+        }
+    case key.CodeRight, key.CodeUp:
+        if (s.orient == Horizontal) == (evt.Code == key.CodeRight) {
+            step = s.stepSize
+        }
+    }
+    if step == 0.0 {
+        return false
+    }
+    s.SetValue(s.Value() + step)
+    return true
+}
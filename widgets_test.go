@@ -0,0 +1,178 @@
+package adagui_test
+
+import (
+	"testing"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+	"github.com/stefan-muehlebach/adagui/key"
+	"github.com/stefan-muehlebach/adagui/touch"
+)
+
+// Baut eine Group auf, in deren Mitte das Widget n platziert wird.
+func centered(n adagui.Node) *adagui.Group {
+	grp := adagui.NewGroup()
+	grp.Layout = adagui.NewCenterLayout()
+	grp.Add(n)
+	return grp
+}
+
+func TestButtonTap(t *testing.T) {
+	btn := adagui.NewTextButton("Tap me")
+	rec := adaguitest.NewRecorder()
+	btn.SetTouchFunc(rec.Record, touch.TypePress, touch.TypeRelease,
+		touch.TypeTap)
+	h := adaguitest.New(t, centered(btn))
+
+	h.Tap(h.Center(btn))
+	got := rec.Types()
+	want := []touch.Type{touch.TypePress, touch.TypeRelease, touch.TypeTap}
+	if len(got) != len(want) {
+		t.Fatalf("got events %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+	if btn.Pushed() {
+		t.Errorf("button is still pushed after release")
+	}
+	h.AssertGolden("button_released")
+}
+
+func TestButtonPushed(t *testing.T) {
+	btn := adagui.NewTextButton("Push me")
+	h := adaguitest.New(t, centered(btn))
+
+	h.Press(h.Center(btn))
+	h.WaitIdle()
+	if !btn.Pushed() {
+		t.Errorf("button is not pushed after press")
+	}
+	h.AssertGolden("button_pushed")
+	h.Release(h.Center(btn))
+	h.WaitIdle()
+}
+
+func TestCheckboxToggle(t *testing.T) {
+	chk := adagui.NewCheckbox("Senf")
+	h := adaguitest.New(t, centered(chk))
+
+	if chk.Checked() {
+		t.Fatalf("new checkbox is already checked")
+	}
+	h.Tap(h.Center(chk))
+	if !chk.Checked() {
+		t.Errorf("checkbox not checked after first tap")
+	}
+	h.AssertGolden("checkbox_checked")
+	h.Tap(h.Center(chk))
+	if chk.Checked() {
+		t.Errorf("checkbox still checked after second tap")
+	}
+}
+
+func TestSliderDrag(t *testing.T) {
+	sld := adagui.NewSlider(200, adagui.Horizontal)
+	h := adaguitest.New(t, centered(sld))
+
+	r := sld.Rect().Inset(1, 1)
+	p0 := sld.Wrappee().Parent.Local2Screen(r.W())
+	p1 := sld.Wrappee().Parent.Local2Screen(r.E())
+	h.DragLine(p0, p1, 10)
+	if v := sld.Value(); v < 0.99 {
+		t.Errorf("slider value after drag to the right end: %f", v)
+	}
+	h.DragLine(p1, p0, 10)
+	if v := sld.Value(); v > 0.01 {
+		t.Errorf("slider value after drag to the left end: %f", v)
+	}
+}
+
+func TestSliderDoubleTap(t *testing.T) {
+	sld := adagui.NewSlider(200, adagui.Horizontal)
+	sld.SetInitValue(0.5)
+	h := adaguitest.New(t, centered(sld))
+
+	p1 := sld.Wrappee().Parent.Local2Screen(sld.Rect().Inset(1, 1).E())
+	h.DragLine(h.Center(sld), p1, 5)
+	if v := sld.Value(); v < 0.99 {
+		t.Fatalf("slider value after drag: %f", v)
+	}
+	h.DoubleTap(h.Center(sld))
+	if v := sld.Value(); v != 0.5 {
+		t.Errorf("slider value after double tap: got %f, want 0.5", v)
+	}
+}
+
+// Ein inaktiver Button erhaelt keine Ereignisse und wird anders gezeichnet.
+func TestButtonDisabled(t *testing.T) {
+	btn := adagui.NewTextButton("Disabled")
+	rec := adaguitest.NewRecorder()
+	btn.SetTouchFunc(rec.Record, touch.TypePress, touch.TypeTap)
+	h := adaguitest.New(t, centered(btn))
+	before := h.Image()
+
+	h.Do(func() { btn.SetEnabled(false) })
+	h.WaitIdle()
+	if !differs(before, h.Image(), before.Bounds()) {
+		t.Errorf("disabled button is painted like an enabled one")
+	}
+	h.Tap(h.Center(btn))
+	if n := len(rec.Types()); n != 0 {
+		t.Errorf("disabled button received %d events", n)
+	}
+
+	h.Do(func() { btn.SetEnabled(true) })
+	h.WaitIdle()
+	if differs(before, h.Image(), before.Bounds()) {
+		t.Errorf("re-enabled button is painted differently")
+	}
+	h.Tap(h.Center(btn))
+	if n := rec.Count(touch.TypeTap); n != 1 {
+		t.Errorf("re-enabled button received %d taps, want 1", n)
+	}
+}
+
+// Wird ein Container deaktiviert, sind es auch alle seine Kinder.
+func TestDisabledCascade(t *testing.T) {
+	btn := adagui.NewTextButton("Child")
+	rec := adaguitest.NewRecorder()
+	btn.SetTouchFunc(rec.Record, touch.TypePress, touch.TypeTap)
+	box := adagui.NewGroup()
+	box.Layout = adagui.NewVBoxLayout(0)
+	box.Add(btn)
+	h := adaguitest.New(t, centered(box))
+
+	h.Press(h.Center(btn))
+	h.WaitIdle()
+	h.Do(func() { box.SetEnabled(false) })
+	h.WaitIdle()
+	if btn.Enabled() {
+		t.Errorf("child of a disabled container is enabled")
+	}
+	if btn.Pushed() {
+		t.Errorf("button is still pushed after disabling its container")
+	}
+	h.Release(h.Center(btn))
+	rec.Reset()
+
+	h.Tap(h.Center(btn))
+	h.Key(key.CodeTab, 0)
+	if n := len(rec.Types()); n != 0 {
+		t.Errorf("child of a disabled container received %d events", n)
+	}
+	if f := h.Window.Focus(); f != nil {
+		t.Errorf("focus moved to %T inside a disabled container", f)
+	}
+
+	h.Do(func() { box.SetEnabled(true) })
+	if !btn.Enabled() {
+		t.Errorf("child not enabled after enabling its container")
+	}
+	h.Tap(h.Center(btn))
+	if n := rec.Count(touch.TypeTap); n != 1 {
+		t.Errorf("child received %d taps after enabling, want 1", n)
+	}
+}
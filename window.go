@@ -2,13 +2,15 @@ package adagui
 
 import (
 	//    "fmt"
+	"image"
+	"image/draw"
 	"image/png"
-	"log"
 	"os"
 	"sync"
+	"time"
 
+	"github.com/stefan-muehlebach/adagui/key"
 	"github.com/stefan-muehlebach/adagui/touch"
-	"github.com/stefan-muehlebach/adatft"
 	"github.com/stefan-muehlebach/gg"
 	"github.com/stefan-muehlebach/gg/colors"
 	"github.com/stefan-muehlebach/gg/geom"
@@ -16,6 +18,10 @@ import (
 
 //----------------------------------------------------------------------------
 
+// Mit WindowStage wird der Zustand eines Fensters beschrieben. Sichtbar
+// (StageVisible) sind alle Fenster auf dem Stack des Screens, die nicht von
+// einem deckenden Fenster verdeckt werden. Das oberste Fenster auf dem Stack
+// hat den Zustand StageFocused.
 type WindowStage uint8
 
 const (
@@ -25,6 +31,21 @@ const (
 	StageFocused
 )
 
+// Mit diesen Flags wird beim Ablegen eines Fensters auf dem Stack (siehe
+// Screen.PushWindow) bestimmt, wie es sich gegenueber den darunterliegenden
+// Fenstern verhaelt.
+type WindowFlags uint8
+
+const (
+	// Ein modales Fenster erhaelt alle Touch-Events. Die Fenster darunter
+	// erhalten keine Events mehr, solange es auf dem Stack liegt.
+	WindowModal WindowFlags = 1 << iota
+	// Ein durchscheinendes Fenster wird ueber die darunterliegenden Fenster
+	// gezeichnet, welche dadurch sichtbar bleiben. Die Hintergrundfarbe des
+	// Fensters (Window.Color) sollte dazu (teilweise) transparent sein.
+	WindowTranslucent
+)
+
 type Window struct {
 	Rect        geom.Rectangle
 	Color       colors.RGBA
@@ -32,10 +53,23 @@ type Window struct {
 	gc          *gg.Context
 	eventQ      chan touch.Event
 	eventCloseQ chan bool
+	closeOnce   sync.Once
+	keyQ        chan key.Event
 	wg          sync.WaitGroup
 	root        Node
 	stage       WindowStage
+	flags       WindowFlags
 	mutex       *sync.Mutex
+
+	focus        Node
+	capture      Node
+	keyFunc      key.KeyFunction
+	keyPressTime time.Time
+
+	damageMutex  sync.Mutex
+	damagedNodes map[*Embed]bool
+	damageFull   bool
+	paintRects   []image.Rectangle
 }
 
 // Dies ist die interne Funktion, welche der Screen beim Erzeugen einer neuen
@@ -43,30 +77,55 @@ type Window struct {
 func newWindow(s *Screen) *Window {
 	w := &Window{}
 	w.s = s
-	width := adatft.Width
-	height := adatft.Height
+	width, height := s.Size()
 	w.Rect = geom.NewRectangleWH(0.0, 0.0, float64(width), float64(height))
 	w.Color = colors.Black
 	w.gc = gg.NewContext(width, height)
 	w.eventQ = make(chan touch.Event)
 	w.eventCloseQ = make(chan bool)
+	w.keyQ = make(chan key.Event)
 	w.wg.Add(1)
 	w.stage = StageAlive
 	w.mutex = &sync.Mutex{}
+	w.damageAll()
+
+	s.mutex.Lock()
+	s.windows[w] = true
+	s.mutex.Unlock()
 
 	go w.eventThread()
 
 	return w
 }
 
-// Schliesst das Fenster.
+// Schliesst das Fenster. Danach erhaelt es keine Events mehr, weitere
+// Aufrufe von Close haben keine Wirkung. Liegt das Fenster auf dem Stack,
+// wird es davon entfernt (das Fenster darunter wird ggf. zum aktiven
+// Fenster). Beim Ende von Screen.Run werden alle noch offenen Fenster
+// geschlossen.
 func (w *Window) Close() {
-	//fmt.Printf("Window.Close() has been called\n")
-	//fmt.Printf("Window.Close()   send close to the event thread\n")
-	w.eventCloseQ <- true
-	//fmt.Printf("Window.Close()   wait for the threads to complete\n")
-	w.wg.Wait()
-	//fmt.Printf("Window.Close()   done!\n")
+	w.closeOnce.Do(func() {
+		close(w.eventCloseQ)
+		w.wg.Wait()
+		w.s.mutex.Lock()
+		delete(w.s.windows, w)
+		removed := w.s.removeWindow(w)
+		if removed {
+			w.s.updateStages()
+		}
+		w.stage = StageDead
+		w.s.mutex.Unlock()
+		if removed {
+			w.s.Repaint()
+		}
+	})
+}
+
+// Liefert den aktuellen Zustand des Fensters.
+func (w *Window) Stage() WindowStage {
+	w.s.mutex.Lock()
+	defer w.s.mutex.Unlock()
+	return w.stage
 }
 
 // In jedem Fenster muss es ein GUI-Element geben, welches an der obersten
@@ -77,107 +136,132 @@ func (w *Window) Root() Node {
 }
 
 func (w *Window) SetRoot(root Node) {
+	w.s.invoke(func() { w.setRoot(root) })
+}
+
+func (w *Window) setRoot(root Node) {
 	n := root.Wrappee()
 	if w.root != nil {
-		n.Win = nil
+		w.root.Wrappee().Win = nil
 	}
 	w.root = root
 	n.Win = w
+	ownProps(root)
 	root.SetPos(w.Rect.Min)
 	root.SetSize(w.Rect.Size())
+	w.damageAll()
+}
+
+// Fuehrt das ausstehende Layout aller markierten Container des Fensters
+// durch (siehe Embed.InvalidateMeasure). Wird vor jedem Bildaufbau im
+// UI-Thread aufgerufen.
+func (w *Window) layout() {
+	if w.root != nil {
+		w.root.Wrappee().updateLayout()
+	}
 }
 
-func (w *Window) SaveScreenshot(fileName string) {
+// Speichert den Inhalt des Fensters als PNG-Datei fileName.
+func (w *Window) SaveScreenshot(fileName string) error {
 	fh, err := os.Create(fileName)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	defer fh.Close()
+	w.mutex.Lock()
+	err = png.Encode(fh, w.gc.Image())
+	w.mutex.Unlock()
+	if err != nil {
+		fh.Close()
+		return err
+	}
+	return fh.Close()
+}
 
+// Liefert eine Kopie des aktuellen Fensterinhaltes.
+func (w *Window) Image() *image.RGBA {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
-	if err = png.Encode(fh, w.gc.Image()); err != nil {
-		log.Fatal(err)
-	}
+	src := w.gc.Image()
+	img := image.NewRGBA(src.Bounds())
+	draw.Draw(img, img.Bounds(), src, src.Bounds().Min, draw.Src)
+	return img
 }
 
-// Mit dieser Methode wird ein Neuaufbau des Bildschirms angestossen. Ueber die
-// interne Queue paintQ wird dem paintThread der Auftrag fuer den Neuaufbau
-// gegeben. Diese Methode blockiert nie! Ist bereits ein Auftrag fuer den
-// Neuaufbau in der Queue, dann ist soweit alles i.O. und wir sind sicher,
-// dass auch unser Auftrag behandelt wird.
+// Mit dieser Methode werden alle beschaedigten Bereiche des Fensters neu
+// gezeichnet. Dabei werden nur diejenigen Nodes gezeichnet, welche einen
+// beschaedigten Bereich schneiden und der Zeichenbereich wird auf diese
+// Bereiche beschraenkt. Retourniert wird true, falls etwas gezeichnet wurde.
+// Muss wie die Methoden der Nodes im UI-Thread aufgerufen werden.
 func (w *Window) Repaint() bool {
-	if w.root == nil || !w.root.Wrappee().Marks.NeedsPaint() {
-		return false
+	return len(w.repaint()) > 0
+}
+
+// Wie Repaint, liefert jedoch die neu gezeichneten Bereiche, damit der
+// Screen nur diese an das Backend uebermitteln muss. Muss im UI-Thread
+// aufgerufen werden, das Lock schuetzt einzig den Fensterinhalt (siehe
+// Image und SaveScreenshot).
+func (w *Window) repaint() []image.Rectangle {
+	if w.root == nil {
+		return nil
 	}
-	w.gc.SetFillColor(w.Color)
-	w.gc.Clear()
+	w.layout()
 	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	rects := w.collectDamage()
+	if len(rects) == 0 {
+		if !w.root.Wrappee().Marks.NeedsPaint() {
+			return nil
+		}
+		rects = []image.Rectangle{w.gc.Image().Bounds()}
+	}
+	dst := w.gc.Image().(*image.RGBA)
+	bg := image.NewUniform(w.Color)
+	w.gc.Push()
+	for _, r := range rects {
+		draw.Draw(dst, r, bg, image.Point{}, draw.Src)
+		w.gc.DrawRectangle(geom.NewRectangleIMG(r).AsCoord())
+	}
+	w.gc.Clip()
+	w.paintRects = rects
 	w.root.Wrappee().Paint(w.gc)
-	w.mutex.Unlock()
-	return true
+	w.paintRects = nil
+	w.gc.Pop()
+	return rects
 }
 
 // Mit dieser Go-Routine werden die Events vom Screen-Objekt empfangen und
-// weiterverarbeitet.
+// zur Verarbeitung an den UI-Thread uebergeben. Der Zustand der aktuellen
+// Geste (ptr) wird nur im UI-Thread verwendet.
 func (w *Window) eventThread() {
-	var target Node
-	var onTarget bool
+	var ptr pointerState
 
 LOOP:
 	for {
 		//fmt.Printf("Window.eventThread() next iteration\n")
 		select {
 		case <-w.eventCloseQ:
-			//fmt.Printf("Window.eventThread() got called on eventCloseQ\n")
 			break LOOP
+		case evt := <-w.keyQ:
+			Debugf(Events, "key received: %v", evt)
+			w.s.Post(func() {
+				defer w.s.pendingInput.Add(-1)
+				if w.root == nil {
+					return
+				}
+				w.handleKey(evt)
+			})
 		case evt := <-w.eventQ:
 			//fmt.Printf("Window.eventThread() new event received\n")
-			// Ist kein root-Element vorhanden, dann wird das Event nicht weiter
-			// verarbeitet und die Go-Routine wartet auf das naechste Event.
-			if w.root == nil {
-				continue
-			}
 			Debugf(Events, "event received: %v", evt)
-			if evt.Type == touch.TypePress {
-				target = w.root.SelectTarget(evt.Pos)
-				Debugf(Events, "new target    : %T", target)
-				onTarget = true
-			}
-			if target == nil {
-				continue
-			}
-			evt.InitPos = target.Screen2Local(evt.InitPos)
-			evt.Pos = target.Screen2Local(evt.Pos)
-			Debugf(Events, "relative pos  : %v", evt.Pos)
-
-			if evt.Type == touch.TypeDrag {
-				if !target.Contains(evt.Pos) {
-					if onTarget {
-						onTarget = false
-						newEvent := evt
-						newEvent.Type = touch.TypeLeave
-						w.mutex.Lock()
-						target.OnInputEvent(newEvent)
-						w.mutex.Unlock()
-					}
-				} else {
-					if !onTarget {
-						onTarget = true
-						newEvent := evt
-						newEvent.Type = touch.TypeEnter
-						w.mutex.Lock()
-						target.OnInputEvent(newEvent)
-						w.mutex.Unlock()
-					}
+			// Ist kein root-Element vorhanden, dann wird das Event nicht weiter
+			// verarbeitet.
+			w.s.Post(func() {
+				defer w.s.pendingInput.Add(-1)
+				if w.root == nil {
+					return
 				}
-			}
-
-			//fmt.Printf("Window.eventThread() about to enter crit. section\n")
-			w.mutex.Lock()
-			target.OnInputEvent(evt)
-			w.mutex.Unlock()
-			//fmt.Printf("Window.eventThread()   leave crit. section\n")
+				w.handleTouch(&ptr, evt)
+			})
 		}
 	}
 	w.wg.Done()
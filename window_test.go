@@ -0,0 +1,228 @@
+package adagui_test
+
+import (
+	"testing"
+
+	"github.com/stefan-muehlebach/adagui"
+	"github.com/stefan-muehlebach/adagui/adaguitest"
+	"github.com/stefan-muehlebach/adagui/touch"
+	"github.com/stefan-muehlebach/gg/colors"
+	"github.com/stefan-muehlebach/gg/geom"
+)
+
+// Prueft, ob beim Ziehen ueber den Rand eines Widgets die Events Leave
+// und Enter erzeugt werden und das Widget alle Drag-Events erhaelt.
+func TestEnterLeave(t *testing.T) {
+	btn := adagui.NewButton(60, 40)
+	rec := adaguitest.NewRecorder()
+	btn.SetTouchFunc(rec.Record, touch.TypeEnter, touch.TypeLeave,
+		touch.TypeDrag)
+	h := adaguitest.New(t, centered(btn))
+
+	inside := h.Center(btn)
+	outside := inside.AddXY(100, 0)
+	h.DragPath(inside, outside, inside)
+
+	got := rec.Types()
+	want := []touch.Type{touch.TypeLeave, touch.TypeDrag, touch.TypeEnter,
+		touch.TypeDrag}
+	if len(got) != len(want) {
+		t.Fatalf("got events %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDoubleTap(t *testing.T) {
+	btn := adagui.NewButton(60, 40)
+	rec := adaguitest.NewRecorder()
+	btn.SetTouchFunc(rec.Record, touch.TypeTap, touch.TypeDoubleTap)
+	h := adaguitest.New(t, centered(btn))
+
+	h.DoubleTap(h.Center(btn))
+	if n := rec.Count(touch.TypeTap); n != 1 {
+		t.Errorf("got %d tap events, want 1", n)
+	}
+	if n := rec.Count(touch.TypeDoubleTap); n != 1 {
+		t.Errorf("got %d double tap events, want 1", n)
+	}
+}
+
+func TestLongPress(t *testing.T) {
+	btn := adagui.NewButton(60, 40)
+	rec := adaguitest.NewRecorder()
+	btn.SetTouchFunc(rec.Record, touch.TypeLongPress, touch.TypeTap)
+	h := adaguitest.New(t, centered(btn))
+
+	h.LongPress(h.Center(btn))
+	if n := rec.Count(touch.TypeLongPress); n != 1 {
+		t.Errorf("got %d long press events, want 1", n)
+	}
+	evts := rec.Events()
+	if len(evts) > 0 && !evts[len(evts)-1].LongPressed {
+		t.Errorf("LongPressed flag not set on the last event")
+	}
+}
+
+// Events ausserhalb aller Widgets duerfen bei keinem Widget ankommen.
+func TestMissedTarget(t *testing.T) {
+	btn := adagui.NewButton(60, 40)
+	rec := adaguitest.NewRecorder()
+	btn.SetTouchFunc(rec.Record, touch.TypePress, touch.TypeTap)
+	h := adaguitest.New(t, centered(btn))
+
+	h.Tap(h.Center(btn).AddXY(100, 0))
+	if evts := rec.Events(); len(evts) != 0 {
+		t.Errorf("button received events: %v", evts)
+	}
+}
+
+// Nach einem Tap auf einen Button darf nur der Bereich dieses Buttons neu
+// gezeichnet und an das Backend uebertragen werden.
+func TestPartialRepaint(t *testing.T) {
+	btn1 := adagui.NewButton(60, 40)
+	btn2 := adagui.NewButton(60, 40)
+	grp := adagui.NewGroup()
+	grp.Layout = adagui.NewHBoxLayout(20)
+	grp.Add(btn1, btn2)
+	h := adaguitest.New(t, centered(grp))
+
+	h.Tap(h.Center(btn1))
+	rects := h.Backend.LastRects()
+	if len(rects) == 0 {
+		t.Fatalf("no rectangles transferred")
+	}
+	c1, c2 := h.Center(btn1), h.Center(btn2)
+	for _, r := range rects {
+		if r.Dx() > 80 || r.Dy() > 60 {
+			t.Errorf("transferred rectangle %v is too large", r)
+		}
+		if !c1.In(geom.NewRectangleIMG(r)) {
+			t.Errorf("transferred rectangle %v misses the tapped button", r)
+		}
+		if c2.In(geom.NewRectangleIMG(r)) {
+			t.Errorf("transferred rectangle %v covers the other button", r)
+		}
+	}
+	if n, _, _ := adaguitest.Compare(h.Backend.Image(), h.Image(),
+		adaguitest.Tolerance{}); n != 0 {
+		t.Errorf("framebuffer and window differ in %d pixels", n)
+	}
+}
+
+// Legt ueber das Fenster des Harness ein weiteres, durchscheinendes Fenster
+// mit dem Wurzel-Element root. Das Fenster wird am Ende des Tests wieder
+// vom Stack entfernt.
+func pushWindow(h *adaguitest.Harness, root adagui.Node,
+	flags adagui.WindowFlags) *adagui.Window {
+	w := h.Screen.NewWindow()
+	w.Color = colors.Black.Alpha(0.5)
+	w.SetRoot(root)
+	h.Screen.PushWindow(w, flags)
+	h.T.Cleanup(func() {
+		if h.Screen.Window() == w {
+			h.Screen.PopWindow()
+		}
+		w.Close()
+	})
+	h.WaitIdle()
+	return w
+}
+
+// Ein modales Fenster erhaelt alle Events, auch wenn an der entsprechenden
+// Stelle kein Widget liegt. Nach dem Entfernen erhaelt wieder das
+// darunterliegende Fenster die Events.
+func TestModalWindow(t *testing.T) {
+	btn := adagui.NewButton(60, 40)
+	rec := adaguitest.NewRecorder()
+	btn.SetTouchFunc(rec.Record, touch.TypeTap)
+	h := adaguitest.New(t, centered(btn))
+
+	dlgBtn := adagui.NewButton(40, 20)
+	dlgRec := adaguitest.NewRecorder()
+	dlgBtn.SetTouchFunc(dlgRec.Record, touch.TypeTap)
+	grp := adagui.NewGroup()
+	grp.Add(dlgBtn)
+	dlg := pushWindow(h, grp, adagui.WindowModal|adagui.WindowTranslucent)
+	if h.Window.Stage() != adagui.StageVisible {
+		t.Errorf("stage of lower window: got %v, want %v", h.Window.Stage(),
+			adagui.StageVisible)
+	}
+	if dlg.Stage() != adagui.StageFocused {
+		t.Errorf("stage of dialog: got %v, want %v", dlg.Stage(),
+			adagui.StageFocused)
+	}
+
+	h.Tap(h.Center(btn))
+	if n := rec.Count(touch.TypeTap); n != 0 {
+		t.Errorf("lower window received %d taps while dialog is open", n)
+	}
+	h.Tap(h.Center(dlgBtn))
+	if n := dlgRec.Count(touch.TypeTap); n != 1 {
+		t.Errorf("dialog received %d taps, want 1", n)
+	}
+
+	if w := h.Screen.PopWindow(); w != dlg {
+		t.Fatalf("PopWindow returned the wrong window")
+	}
+	h.Tap(h.Center(btn))
+	if n := rec.Count(touch.TypeTap); n != 1 {
+		t.Errorf("lower window received %d taps after pop, want 1", n)
+	}
+	if h.Window.Stage() != adagui.StageFocused {
+		t.Errorf("stage after pop: got %v, want %v", h.Window.Stage(),
+			adagui.StageFocused)
+	}
+}
+
+// Bei einem nicht modalen Fenster gelangen Events, welche kein Widget
+// treffen, an das darunterliegende Fenster. Dieses bleibt sichtbar.
+func TestTranslucentWindow(t *testing.T) {
+	btn := adagui.NewButton(60, 40)
+	rec := adaguitest.NewRecorder()
+	btn.SetTouchFunc(rec.Record, touch.TypeTap)
+	h := adaguitest.New(t, centered(btn))
+	before := h.Backend.Image().RGBAAt(h.Center(btn).Int().X,
+		h.Center(btn).Int().Y)
+
+	pushWindow(h, adagui.NewGroup(), adagui.WindowTranslucent)
+	after := h.Backend.Image().RGBAAt(h.Center(btn).Int().X,
+		h.Center(btn).Int().Y)
+	if after == before || after.R == 0 && after.G == 0 && after.B == 0 {
+		t.Errorf("lower window not dimmed: before %v, after %v", before,
+			after)
+	}
+
+	h.Tap(h.Center(btn))
+	if n := rec.Count(touch.TypeTap); n != 1 {
+		t.Errorf("lower window received %d taps, want 1", n)
+	}
+}
+
+// Ein geschlossenes Fenster wird vom Stack entfernt und ein Fenster, welches
+// bereits auf dem Stack liegt, wird von SetWindow nicht verdoppelt.
+func TestWindowStack(t *testing.T) {
+	h := adaguitest.New(t, centered(adagui.NewButton(60, 40)))
+
+	dlg := pushWindow(h, adagui.NewGroup(), adagui.WindowTranslucent)
+	dlg.Close()
+	if got := h.Screen.Windows(); len(got) != 1 || got[0] != h.Window {
+		t.Errorf("stack after Close has %d windows, want only the main window",
+			len(got))
+	}
+	if h.Window.Stage() != adagui.StageFocused {
+		t.Errorf("stage after Close: got %v, want %v", h.Window.Stage(),
+			adagui.StageFocused)
+	}
+
+	pushWindow(h, adagui.NewGroup(), adagui.WindowTranslucent)
+	h.Screen.SetWindow(h.Window)
+	h.WaitIdle()
+	if got := h.Screen.Windows(); len(got) != 1 || got[0] != h.Window {
+		t.Errorf("stack after SetWindow has %d windows, want only the main "+
+			"window", len(got))
+	}
+}